@@ -0,0 +1,395 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"ilcs/database"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/config"
+	"ilcs/internal/constants"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// The commands below read the same config as serve, loaded before they run.
+
+func commandContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+func newMigrateCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:       "migrate up|down|status|redo",
+		Short:     "Apply, roll back or show the database migrations",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"up", "down", "status", "redo"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := commandContext()
+			defer cancel()
+
+			db := database.OpenMigrationPG(cfg.Database)
+			defer db.Close()
+
+			return database.Migrate(ctx, db, args[0], cmd.OutOrStdout())
+		},
+	}
+}
+
+func newSeedCommand(cfg *config.Config) *cobra.Command {
+
+	var (
+		userId   string
+		timezone string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Create a user with a few sample tasks and print a token for it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+
+			ctx, cancel := commandContext()
+			defer cancel()
+
+			db := database.ConnectPG(cfg.Database)
+			defer db.Close()
+
+			redisDb := database.ConnectRedis(cfg.Redis)
+			defer redisDb.Close()
+
+			repo := repositories.New(db)
+
+			id, err := createUser(ctx, repo, userId, timezone)
+			if err != nil {
+				return
+			}
+
+			tokens := utils.NewTokens(cfg.Auth.JWTSecret)
+
+			todoService := todo.NewTodoService(repo, redisDb, repositories.NewTransactor(db), tokens)
+			ctx = context.WithValue(ctx, constants.USER_ID, id)
+
+			today := time.Now().UTC()
+			for _, req := range []todo.CreateTodoRequest{
+				{Title: "Read the API docs", DueDate: today.Format(time.DateOnly)},
+				{Title: "Plan the week", DueDate: today.AddDate(0, 0, 1).Format(time.DateOnly), RecurrenceRule: "FREQ=WEEKLY", Board: "work"},
+				{Title: "Water the plants", Description: "The ones on the balcony too", DueDate: today.AddDate(0, 0, 3).Format(time.DateOnly), Board: "home"},
+			} {
+				if _, _, err = todoService.CreateTodo(ctx, req); err != nil {
+					return
+				}
+			}
+
+			token, err := tokens.Sign(id, 24*time.Hour)
+			if err != nil {
+				return
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "user %s\ntoken %s\n", id, token)
+			return
+		},
+	}
+
+	cmd.Flags().StringVar(&userId, "user", "", "id of the user, a new one when empty")
+	cmd.Flags().StringVar(&timezone, "timezone", "UTC", "time zone of the user")
+
+	return cmd
+}
+
+func newUserCommand(cfg *config.Config) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users",
+	}
+
+	var (
+		userId   string
+		timezone string
+	)
+
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user, or update the time zone of an existing one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := commandContext()
+			defer cancel()
+
+			db := database.ConnectPG(cfg.Database)
+			defer db.Close()
+
+			id, err := createUser(ctx, repositories.New(db), userId, timezone)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), id)
+			return nil
+		},
+	}
+
+	create.Flags().StringVar(&userId, "id", "", "id of the user, a new one when empty")
+	create.Flags().StringVar(&timezone, "timezone", "UTC", "time zone of the user")
+
+	cmd.AddCommand(create)
+
+	return cmd
+}
+
+// createUser saves the user with its time zone and returns its id.
+func createUser(ctx context.Context, repo repositories.Querier, userId, timezone string) (id string, err error) {
+
+	if _, err = time.LoadLocation(timezone); err != nil {
+		return
+	}
+
+	var uuidUser uuid.UUID
+	if userId == "" {
+		uuidUser, err = uuid.NewV7()
+	} else {
+		uuidUser, err = uuid.Parse(userId)
+	}
+
+	if err != nil {
+		return
+	}
+
+	user, err := repo.UpsertUserTimezone(ctx, repositories.UpsertUserTimezoneParams{
+		ID:       pgtype.UUID{Valid: true, Bytes: uuidUser},
+		Timezone: timezone,
+	})
+	if err != nil {
+		return
+	}
+
+	return user.ID.String(), nil
+}
+
+func newTokenCommand(cfg *config.Config) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens",
+	}
+
+	var (
+		userId string
+		ttl    time.Duration
+	)
+
+	mint := &cobra.Command{
+		Use:   "mint",
+		Short: "Sign a token of a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if _, err := uuid.Parse(userId); err != nil {
+				return fmt.Errorf("--user must be a user id: %w", err)
+			}
+
+			token, err := utils.NewTokens(cfg.Auth.JWTSecret).Sign(userId, ttl)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), token)
+			return nil
+		},
+	}
+
+	mint.Flags().StringVar(&userId, "user", "", "id of the user")
+	mint.Flags().DurationVar(&ttl, "ttl", 24*time.Hour, "how long the token is valid")
+	mint.MarkFlagRequired("user")
+
+	cmd.AddCommand(mint)
+
+	return cmd
+}
+
+func newCacheCommand(cfg *config.Config) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the Redis cache",
+	}
+
+	var undo bool
+
+	flush := &cobra.Command{
+		Use:   "flush",
+		Short: "Drop the cached tasks, the streams and presence are kept",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := commandContext()
+			defer cancel()
+
+			redisDb := database.ConnectRedis(cfg.Redis)
+			defer redisDb.Close()
+
+			patterns := flushPatterns(undo)
+
+			// a cluster is scanned node by node, every node only has its own keys
+			nodes := []redis.UniversalClient{redisDb}
+			if cluster, ok := redisDb.(*redis.ClusterClient); ok {
+				nodes = nil
+				var mu sync.Mutex
+				err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+					mu.Lock()
+					defer mu.Unlock()
+					nodes = append(nodes, node)
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			var flushed int64
+			for _, node := range nodes {
+				for _, pattern := range patterns {
+					n, err := flushKeys(ctx, node, pattern)
+					flushed += n
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%d keys flushed\n", flushed)
+			return nil
+		},
+	}
+
+	flush.Flags().BoolVar(&undo, "undo", false, "drop the undo tokens too, pending undos stop working")
+
+	cmd.AddCommand(flush)
+
+	return cmd
+}
+
+// flushPatterns returns the patterns of the keys cache flush drops, the undo
+// tokens only when asked to since pending undos stop working without them.
+func flushPatterns(undo bool) []string {
+
+	patterns := []string{constants.CACHE_KEY + "*"}
+	if undo {
+		patterns = append(patterns, constants.UNDO_KEY+"*")
+	}
+
+	return patterns
+}
+
+// flushKeys unlinks the keys of client matching pattern, one key per command
+// since the keys of a batch may belong to different cluster slots.
+func flushKeys(ctx context.Context, client redis.UniversalClient, pattern string) (flushed int64, err error) {
+
+	iter := client.Scan(ctx, 0, pattern, 500).Iterator()
+
+	var keys []string
+	flush := func() error {
+		cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		})
+
+		for _, cmd := range cmds {
+			flushed += cmd.(*redis.IntCmd).Val()
+		}
+
+		keys = keys[:0]
+
+		return err
+	}
+
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 500 {
+			if err = flush(); err != nil {
+				return
+			}
+		}
+	}
+
+	if err = iter.Err(); err != nil {
+		return
+	}
+
+	if len(keys) > 0 {
+		err = flush()
+	}
+
+	return
+}
+
+func newPurgeCommand(cfg *config.Config) *cobra.Command {
+
+	var (
+		olderThan time.Duration
+		force     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete the tasks that have been in the trash for too long, now",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx, cancel := commandContext()
+			defer cancel()
+
+			if !cmd.Flags().Changed("older-than") {
+				olderThan = time.Duration(cfg.Worker.TrashRetentionDays) * 24 * time.Hour
+			}
+
+			// a zero age matches every trashed task
+			if olderThan <= 0 && !force {
+				return fmt.Errorf("--older-than must be positive, got %s, add --force to empty the whole trash", olderThan)
+			}
+
+			db := database.ConnectPG(cfg.Database)
+			defer db.Close()
+
+			job := todo.NewPurgeJob(repositories.NewTransactor(db), olderThan, 0)
+
+			total := 0
+			for {
+				purged, err := job.Tick(ctx, time.Now())
+				if err != nil {
+					return err
+				}
+
+				if purged == 0 {
+					break
+				}
+
+				total += purged
+				log.Info().Int("purged", purged).Msg("Trashed tasks purged")
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%d tasks purged\n", total)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "how long a task stays in the trash, TRASH_RETENTION_DAYS by default")
+	cmd.Flags().BoolVar(&force, "force", false, "allow an --older-than of zero, which empties the whole trash")
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"ilcs/internal/config"
+	"ilcs/internal/repositories"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepo keeps the users it is asked to save.
+type MockRepo struct {
+	repositories.Querier
+	users []repositories.UpsertUserTimezoneParams
+}
+
+func (m *MockRepo) UpsertUserTimezone(ctx context.Context, arg repositories.UpsertUserTimezoneParams) (repositories.User, error) {
+	m.users = append(m.users, arg)
+	return repositories.User{ID: arg.ID, Timezone: arg.Timezone}, nil
+}
+
+func TestCreateUser(t *testing.T) {
+	repo := &MockRepo{}
+	userId := uuid.New().String()
+
+	id, err := createUser(context.Background(), repo, userId, "Europe/Paris")
+
+	require.NoError(t, err)
+	assert.Equal(t, userId, id)
+	require.Len(t, repo.users, 1)
+	assert.Equal(t, "Europe/Paris", repo.users[0].Timezone)
+
+	id, err = createUser(context.Background(), repo, "", "UTC")
+
+	require.NoError(t, err)
+	assert.NoError(t, uuid.Validate(id))
+}
+
+func TestCreateUser_BadTimezone(t *testing.T) {
+	repo := &MockRepo{}
+
+	_, err := createUser(context.Background(), repo, "", "Mars/Olympus")
+
+	assert.Error(t, err)
+	assert.Empty(t, repo.users)
+}
+
+func TestCreateUser_BadId(t *testing.T) {
+	repo := &MockRepo{}
+
+	_, err := createUser(context.Background(), repo, "not-a-uuid", "UTC")
+
+	assert.Error(t, err)
+	assert.Empty(t, repo.users)
+}
+
+func TestFlushKeys_KeepsUndoTokensUnlessAsked(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	for _, key := range []string{"todo:1", "todo:2", "undo:abc", "events:tasks"} {
+		require.NoError(t, mr.Set(key, "x"))
+	}
+
+	flush := func(undo bool) (flushed int64) {
+		for _, pattern := range flushPatterns(undo) {
+			n, err := flushKeys(ctx, client, pattern)
+			require.NoError(t, err)
+			flushed += n
+		}
+		return
+	}
+
+	assert.Equal(t, int64(2), flush(false))
+	assert.Equal(t, []string{"events:tasks", "undo:abc"}, mr.Keys())
+
+	assert.Equal(t, int64(1), flush(true))
+	assert.Equal(t, []string{"events:tasks"}, mr.Keys())
+}
+
+func TestPurge_RefusesToEmptyTheTrashUnlessForced(t *testing.T) {
+	for _, age := range []string{"0", "-1h"} {
+		cmd := newPurgeCommand(&config.Config{})
+		cmd.SetArgs([]string{"--older-than", age})
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "--force", age)
+	}
+}
@@ -0,0 +1,93 @@
+// Command ilcs is the command line client of the task API.
+package main
+
+import (
+	"context"
+	"fmt"
+	"ilcs/internal/client"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// app holds what every command shares: the flags of the root command and the
+// stored config.
+type app struct {
+	server     string
+	output     string
+	configPath string
+	config     client.Config
+}
+
+// client returns a client of the server, commands other than login need a
+// stored token.
+func (a *app) client(needToken bool) (*client.Client, error) {
+
+	if needToken && a.config.Token == "" {
+		return nil, client.ErrNotLoggedIn
+	}
+
+	return client.New(a.server, a.config.Token), nil
+}
+
+func newRootCommand() *cobra.Command {
+
+	a := &app{}
+
+	root := &cobra.Command{
+		Use:           "ilcs",
+		Short:         "Manage your tasks from the terminal",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+
+			if a.output != "table" && a.output != "json" {
+				return fmt.Errorf("output must be table or json, not %q", a.output)
+			}
+
+			if a.configPath, err = client.ConfigPath(); err != nil {
+				return
+			}
+
+			if a.config, err = client.LoadConfig(a.configPath); err != nil {
+				return
+			}
+
+			if a.server == "" {
+				a.server = a.config.Server
+			}
+
+			return
+		},
+	}
+
+	root.PersistentFlags().StringVar(&a.server, "server", "", "API to talk to, defaults to the one of the last login")
+	root.PersistentFlags().StringVarP(&a.output, "output", "o", "table", "output format: table or json")
+	root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	root.AddCommand(
+		newLoginCommand(a),
+		newAddCommand(a),
+		newListCommand(a),
+		newDoneCommand(a),
+		newEditCommand(a),
+		newRemoveCommand(a),
+		newExportCommand(a),
+	)
+
+	return root
+}
+
+func main() {
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		stop()
+		os.Exit(1)
+	}
+}
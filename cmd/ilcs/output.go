@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"ilcs/internal/client"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+func printJSON(w io.Writer, v interface{}) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+func printTable(w io.Writer, tasks ...client.Task) {
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tSTATUS\tDUE\tBOARD\tTAGS")
+
+	for _, task := range tasks {
+
+		due := task.DueDate
+		if task.DueAt != "" {
+			due = task.DueAt
+		}
+		if task.Overdue {
+			due += " (overdue)"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			task.ID, task.Title, task.Status, due, task.Board, strings.Join(task.Tags, ","))
+	}
+
+	tw.Flush()
+}
+
+// printChange prints a changed task with the token that undoes the change.
+func (a *app) printChange(w io.Writer, verb string, task client.Task, undo string) error {
+
+	if a.output == "json" {
+		return printJSON(w, map[string]interface{}{"task": task, "undo_token": undo})
+	}
+
+	fmt.Fprintf(w, "%s %s, undo token %s\n", verb, task.ID, undo)
+	printTable(w, task)
+
+	return nil
+}
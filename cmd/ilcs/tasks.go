@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"ilcs/internal/client"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func newLoginCommand(a *app) *cobra.Command {
+
+	var token string
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Get a token and store it in the config dir",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if token == "" {
+				c, _ := a.client(false)
+
+				var err error
+				if token, err = c.Login(cmd.Context()); err != nil {
+					return err
+				}
+			}
+
+			a.config.Server = a.server
+			a.config.Token = token
+
+			if err := client.SaveConfig(a.configPath, a.config); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged in to %s, the token is stored in %s\n", a.server, a.configPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&token, "token", "", "token of an existing user, minted with the server's token mint command; a new user when empty")
+
+	return cmd
+}
+
+func newAddCommand(a *app) *cobra.Command {
+
+	var (
+		input client.TaskInput
+		rrule string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <title>",
+		Short: "Add a task",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			c, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			input.Title = args[0]
+			if rrule != "" {
+				input.RecurrenceRule = &rrule
+			}
+
+			task, undo, err := c.CreateTask(cmd.Context(), input)
+			if err != nil {
+				return err
+			}
+
+			return a.printChange(cmd.OutOrStdout(), "Added", task, undo)
+		},
+	}
+
+	cmd.Flags().StringVar(&input.DueDate, "due", "", "due day, YYYY-MM-DD")
+	cmd.Flags().StringVar(&input.DueAt, "due-at", "", "due time, RFC 3339")
+	cmd.Flags().StringVar(&input.Description, "desc", "", "description")
+	cmd.Flags().StringVar(&input.Board, "board", "", "board of the task")
+	cmd.Flags().StringVar(&rrule, "rrule", "", "recurrence rule, e.g. FREQ=WEEKLY")
+
+	return cmd
+}
+
+func newListCommand(a *app) *cobra.Command {
+
+	var opts client.ListOptions
+
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List tasks",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			c, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			list, err := c.ListTasks(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			if a.output == "json" {
+				return printJSON(cmd.OutOrStdout(), list)
+			}
+
+			printTable(cmd.OutOrStdout(), list.Tasks...)
+			fmt.Fprintf(cmd.OutOrStdout(), "\nPage %d of %d, %d tasks\n",
+				list.Pagination.CurrentPage, list.Pagination.TotalPage, list.Pagination.TotalTasks)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Status, "status", "", "pending or completed")
+	cmd.Flags().StringVar(&opts.Search, "search", "", "words of the title or description")
+	cmd.Flags().StringVar(&opts.Board, "board", "", "board of the tasks")
+	cmd.Flags().IntVar(&opts.Page, "page", 1, "page to show")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 20, "tasks per page")
+	cmd.RegisterFlagCompletionFunc("status", completeStatus)
+
+	return cmd
+}
+
+func newDoneCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:               "done <id>",
+		Short:             "Complete a task",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			c, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			task, undo, err := c.CompleteTask(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			return a.printChange(cmd.OutOrStdout(), "Completed", task, undo)
+		},
+	}
+}
+
+func newEditCommand(a *app) *cobra.Command {
+
+	var (
+		title, desc, status, due, dueAt, rrule, board string
+	)
+
+	cmd := &cobra.Command{
+		Use:               "edit <id>",
+		Short:             "Change a task, the fields without a flag are kept",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			c, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			task, err := c.GetTask(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			// an update replaces the whole task, so start from the stored one
+			input := client.TaskInput{
+				Title:          task.Title,
+				Description:    task.Description,
+				Status:         task.Status,
+				RecurrenceRule: &task.RecurrenceRule,
+			}
+
+			if task.DueAt != "" {
+				input.DueAt = task.DueAt
+			} else {
+				input.DueDate = task.DueDate
+			}
+
+			flags := cmd.Flags()
+			if flags.Changed("title") {
+				input.Title = title
+			}
+			if flags.Changed("desc") {
+				input.Description = desc
+			}
+			if flags.Changed("status") {
+				input.Status = status
+			}
+			if flags.Changed("due") {
+				input.DueDate, input.DueAt = due, ""
+			}
+			if flags.Changed("due-at") {
+				input.DueDate, input.DueAt = "", dueAt
+			}
+			if flags.Changed("rrule") {
+				input.RecurrenceRule = &rrule
+			}
+
+			var undo string
+			if flags.Changed("title") || flags.Changed("desc") || flags.Changed("status") ||
+				flags.Changed("due") || flags.Changed("due-at") || flags.Changed("rrule") {
+				if task, undo, err = c.UpdateTask(cmd.Context(), args[0], input); err != nil {
+					return err
+				}
+			}
+
+			if flags.Changed("board") {
+				if task, undo, err = c.MoveTask(cmd.Context(), args[0], board); err != nil {
+					return err
+				}
+			}
+
+			if undo == "" {
+				return fmt.Errorf("nothing to change, see ilcs edit --help")
+			}
+
+			return a.printChange(cmd.OutOrStdout(), "Updated", task, undo)
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "title")
+	cmd.Flags().StringVar(&desc, "desc", "", "description")
+	cmd.Flags().StringVar(&status, "status", "", "pending or completed")
+	cmd.Flags().StringVar(&due, "due", "", "due day, YYYY-MM-DD")
+	cmd.Flags().StringVar(&dueAt, "due-at", "", "due time, RFC 3339")
+	cmd.Flags().StringVar(&rrule, "rrule", "", "recurrence rule, empty to stop repeating")
+	cmd.Flags().StringVar(&board, "board", "", "board to move the task to")
+	cmd.RegisterFlagCompletionFunc("status", completeStatus)
+
+	return cmd
+}
+
+func newRemoveCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:               "rm <id>",
+		Short:             "Delete a task, it stays in the trash for a while",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			c, err := a.client(true)
+			if err != nil {
+				return err
+			}
+
+			undo, err := c.DeleteTask(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if a.output == "json" {
+				return printJSON(cmd.OutOrStdout(), map[string]string{"id": args[0], "undo_token": undo})
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted %s, undo token %s\n", args[0], undo)
+			return nil
+		},
+	}
+}
+
+func newExportCommand(a *app) *cobra.Command {
+
+	var (
+		format, file string
+		opts         client.ListOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export tasks to stdout or a file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+
+			c, err := a.client(true)
+			if err != nil {
+				return
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if file != "" {
+				f, err := os.Create(file)
+				if err != nil {
+					return err
+				}
+
+				defer func() {
+					if closeErr := f.Close(); err == nil {
+						err = closeErr
+					}
+				}()
+
+				w = f
+			}
+
+			return c.Export(cmd.Context(), w, format, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "csv", "csv, ndjson, xlsx, todotxt or markdown")
+	cmd.Flags().StringVar(&file, "file", "", "file to write, stdout when empty")
+	cmd.Flags().StringVar(&opts.Status, "status", "", "pending or completed")
+	cmd.Flags().StringVar(&opts.Search, "search", "", "words of the title or description")
+	cmd.Flags().StringVar(&opts.Board, "board", "", "board of the tasks")
+	cmd.RegisterFlagCompletionFunc("status", completeStatus)
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"csv", "ndjson", "xlsx", "todotxt", "markdown"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+var completeStatus = cobra.FixedCompletions([]string{"pending", "completed"}, cobra.ShellCompDirectiveNoFileComp)
+
+// completeTaskIDs completes the ids of the pending tasks, with their titles
+// as description.
+func (a *app) completeTaskIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	c, err := a.client(true)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	list, err := c.ListTasks(cmd.Context(), client.ListOptions{Status: "pending", Limit: 100})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ids := make([]string, 0, len(list.Tasks))
+	for _, task := range list.Tasks {
+		ids = append(ids, task.ID+"\t"+task.Title)
+	}
+
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
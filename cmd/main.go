@@ -1,23 +1,14 @@
 package main
 
 import (
-	"context"
-	"ilcs/database"
-	"ilcs/internal/app/todo"
-	"ilcs/internal/http/middlewares"
-	"ilcs/internal/http/route"
-	"ilcs/internal/repositories"
-	"net/http"
+	"fmt"
+	"ilcs/internal/config"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	_ "time/tzdata"
 
-	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
 )
 
 func init() {
@@ -26,57 +17,40 @@ func init() {
 
 func main() {
 
-	app := gin.Default()
-
-	db := database.ConnectPG()
-
-	defer db.Close()
-
-	redisDb := database.ConnectRedis()
-
-	defer redisDb.Close()
-
-	app.Use(middlewares.Trace())
-	app.Use(middlewares.RequestLoggerMiddleware(), middlewares.ResponseLoggerMiddleware())
-
-	setupContainer(app, db, redisDb)
-
-	server := &http.Server{
-		Addr:    ":" + os.Getenv("PORT"),
-		Handler: app,
+	var (
+		cfg        config.Config
+		configPath string
+	)
+
+	root := &cobra.Command{
+		Use:           "app",
+		Short:         "The task API and the commands to operate it",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+
+			cfg, err = config.Load(configPath)
+			if err != nil {
+				return fmt.Errorf("invalid configuration: %w", err)
+			}
+
+			return
+		},
 	}
 
-	go func() {
-		log.Info().Msgf("Starting server... on port %s", os.Getenv("PORT"))
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal().Err(err).Msg("Server failed")
-		}
-	}()
+	root.PersistentFlags().StringVar(&configPath, "config", "", "YAML config file, CONFIG_FILE by default; the environment overrides it")
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Info().Msg("Shutting down server...")
+	root.AddCommand(
+		newServeCommand(&cfg),
+		newMigrateCommand(&cfg),
+		newSeedCommand(&cfg),
+		newUserCommand(&cfg),
+		newTokenCommand(&cfg),
+		newCacheCommand(&cfg),
+		newPurgeCommand(&cfg),
+	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Server forced to shutdown")
+	if err := root.Execute(); err != nil {
+		log.Fatal().Err(err).Send()
 	}
-
-	log.Info().Msg("Server exiting")
-
-}
-
-func setupContainer(app *gin.Engine, db *pgxpool.Pool, redisDb *redis.Client) {
-
-	repo := repositories.New(db)
-
-	todoService := todo.NewTodoService(repo, redisDb)
-
-	todoHandler := todo.NewTodoHandler(todoService)
-
-	route.RegisterTodoRoute(app, todoHandler)
-
 }
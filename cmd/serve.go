@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"ilcs/database"
+	"ilcs/internal/app/audit"
+	"ilcs/internal/app/board"
+	"ilcs/internal/app/caldav"
+	"ilcs/internal/app/calendar"
+	"ilcs/internal/app/digest"
+	"ilcs/internal/app/reminder"
+	"ilcs/internal/app/stream"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/app/user"
+	"ilcs/internal/app/webhook"
+	"ilcs/internal/config"
+	"ilcs/internal/graph"
+	"ilcs/internal/http/middlewares"
+	"ilcs/internal/http/route"
+	"ilcs/internal/repositories"
+	"ilcs/internal/rpc"
+	"ilcs/internal/utils"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+func newServeCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the REST, GraphQL and gRPC APIs",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			serve(*cfg)
+		},
+	}
+}
+
+func serve(cfg config.Config) {
+
+	app := gin.Default()
+
+	db := database.ConnectPG(cfg.Database)
+
+	defer db.Close()
+
+	redisDb := database.ConnectRedis(cfg.Redis)
+
+	defer redisDb.Close()
+
+	// handlers pass the gin context to the services, with the fallback its
+	// deadline and cancelation are the ones of the request
+	app.ContextWithFallback = true
+
+	app.Use(middlewares.Trace())
+	app.Use(middlewares.QueryTimeout(cfg.Database.RequestTimeout))
+	app.Use(middlewares.RequestLoggerMiddleware(), middlewares.ResponseLoggerMiddleware())
+
+	grpcServer := setupContainer(app, db, redisDb, cfg)
+
+	server := &http.Server{
+		Addr:    ":" + cfg.HTTP.Port,
+		Handler: app,
+	}
+
+	go func() {
+		log.Info().Msgf("Starting server... on port %s", cfg.HTTP.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("Server failed")
+		}
+	}()
+
+	if port := cfg.HTTP.GRPCPort; port != "" {
+		listener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			log.Fatal().Err(err).Msg("gRPC server failed")
+		}
+
+		go func() {
+			log.Info().Msgf("Starting gRPC server... on port %s", port)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal().Err(err).Msg("gRPC server failed")
+			}
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Info().Msg("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Server forced to shutdown")
+	}
+
+	// watch streams only end with their client, do not wait for them forever
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+
+	log.Info().Msg("Server exiting")
+
+}
+
+// setupContainer registers the routes of the REST API and returns the gRPC
+// server, which serves the same services.
+func setupContainer(app *gin.Engine, db *pgxpool.Pool, redisDb redis.UniversalClient, cfg config.Config) *grpc.Server {
+
+	tokens := utils.NewTokens(cfg.Auth.JWTSecret)
+
+	guard := middlewares.NewGuard(tokens, cfg.Auth.AdminUserIDs)
+
+	repo := repositories.New(repositories.WithTimeouts(db))
+
+	todoService := todo.NewTodoService(repo, redisDb, repositories.NewTransactor(db), tokens)
+
+	todoHandler := todo.NewTodoHandler(todoService)
+
+	route.RegisterTodoRoute(app, todoHandler, guard)
+
+	userService := user.NewUserService(repo, tokens)
+
+	userHandler := user.NewUserHandler(userService)
+
+	route.RegisterUserRoute(app, userHandler, guard)
+
+	reminderService := reminder.NewReminderService(repo)
+
+	reminderHandler := reminder.NewReminderHandler(reminderService)
+
+	route.RegisterReminderRoute(app, reminderHandler, guard)
+
+	digestService := digest.NewDigestService(repo)
+
+	digestHandler := digest.NewDigestHandler(digestService)
+
+	route.RegisterDigestRoute(app, digestHandler, guard)
+
+	webhookService := webhook.NewWebhookService(repo, webhook.NewSender())
+
+	webhookHandler := webhook.NewWebhookHandler(webhookService)
+
+	route.RegisterWebhookRoute(app, webhookHandler, guard)
+
+	streamService := stream.NewStreamService(redisDb)
+
+	streamHandler := stream.NewStreamHandler(streamService)
+
+	route.RegisterStreamRoute(app, streamHandler, guard)
+
+	boardService := board.NewBoardService(redisDb)
+
+	boardHandler := board.NewBoardHandler(boardService, todoService)
+
+	route.RegisterBoardRoute(app, boardHandler, guard)
+
+	auditService := audit.NewAuditService(repo)
+
+	auditHandler := audit.NewAuditHandler(auditService)
+
+	route.RegisterAuditRoute(app, auditHandler, guard)
+
+	calendarService := calendar.NewCalendarService(repo)
+
+	calendarHandler := calendar.NewCalendarHandler(calendarService)
+
+	route.RegisterCalendarRoute(app, calendarHandler, guard)
+
+	caldavService := caldav.NewCaldavService(repo, todoService, repositories.NewTransactor(db))
+
+	caldavHandler := caldav.NewCaldavHandler(caldavService)
+
+	route.RegisterCaldavRoute(app, caldavHandler, guard)
+
+	graphSchema, err := graph.NewSchema(todoService)
+	if err != nil {
+		log.Fatal().Err(err).Msg("GraphQL schema is invalid")
+	}
+
+	graphHandler := graph.NewGraphHandler(graphSchema, repo)
+
+	route.RegisterGraphRoute(app, graphHandler, guard)
+
+	return rpc.NewServer(todoService, streamService, tokens)
+
+}
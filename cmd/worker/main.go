@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"ilcs/database"
+	"ilcs/internal/app/digest"
+	"ilcs/internal/app/reminder"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/app/webhook"
+	"ilcs/internal/config"
+	"ilcs/internal/events"
+	"ilcs/internal/notify"
+	"ilcs/internal/repositories"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func init() {
+	log.Logger = zerolog.New(os.Stdout).With().Caller().Timestamp().Logger()
+}
+
+func main() {
+
+	cfg, err := config.Load("")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid configuration")
+	}
+
+	db := database.ConnectPG(cfg.Database)
+
+	defer db.Close()
+
+	redisDb := database.ConnectRedis(cfg.Redis)
+
+	defer redisDb.Close()
+
+	repo := repositories.New(db)
+
+	emailQueue := notify.NewEmailQueue(redisDb)
+
+	mailer := notify.NewSMTPMailer(cfg.SMTP.Addr, cfg.SMTP.From, cfg.SMTP.Username, cfg.SMTP.Password)
+
+	channels := map[repositories.ReminderChannel]notify.Channel{
+		repositories.ReminderChannelWebhook: notify.NewWebhookChannel(),
+		repositories.ReminderChannelEmail:   notify.NewEmailChannel(repo, emailQueue, cfg.AppURL),
+		repositories.ReminderChannelInApp:   notify.NewInAppChannel(repo),
+	}
+
+	scheduler := reminder.NewScheduler(db, channels, 30*time.Second)
+
+	digestJob := digest.NewJob(db, channels, cfg.Worker.DigestDays, cfg.Worker.DigestHour, 5*time.Minute)
+
+	webhookJob := webhook.NewJob(db, webhook.NewSender(), 10*time.Second)
+
+	relay := events.NewRelay(repositories.NewTransactor(db), []events.Publisher{
+		events.NewRedisStream(redisDb),
+		events.NewUserFeed(redisDb),
+		events.NewBoardFeed(redisDb),
+		webhook.NewDispatcher(repo),
+	}, time.Second)
+
+	purgeJob := todo.NewPurgeJob(repositories.NewTransactor(db), time.Duration(cfg.Worker.TrashRetentionDays)*24*time.Hour, time.Hour)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Info().Msg("Starting worker...")
+
+	var wg sync.WaitGroup
+
+	wg.Add(5)
+	go func() {
+		defer wg.Done()
+		emailQueue.Consume(ctx, mailer)
+	}()
+
+	go func() {
+		defer wg.Done()
+		digestJob.Run(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		webhookJob.Run(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		relay.Run(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		purgeJob.Run(ctx)
+	}()
+
+	scheduler.Run(ctx)
+
+	wg.Wait()
+
+	log.Info().Msg("Worker exiting")
+
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// ErrSchemaBehind is returned by CheckSchema when the database misses
+// migrations embedded in the binary.
+var ErrSchemaBehind = errors.New("database schema is behind this build")
+
+// newProvider returns a goose provider of the embedded migrations. Migrations
+// run under a Postgres advisory lock, so replicas and jobs migrating at the
+// same time wait for each other instead of racing.
+func newProvider(dbpool *pgxpool.Pool) (*goose.Provider, *sql.DB, error) {
+
+	migrations, err := fs.Sub(embedMigrations, "migrations")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db := stdlib.OpenDBFromPool(dbpool)
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations, goose.WithSessionLocker(locker))
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return provider, db, nil
+}
+
+// Migrate runs a migration command, up, down, redo (down then up again) or
+// status, and writes what it did to w.
+func Migrate(ctx context.Context, dbpool *pgxpool.Pool, command string, w io.Writer) error {
+
+	provider, db, err := newProvider(dbpool)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	var results []*goose.MigrationResult
+
+	switch command {
+	case "up":
+		results, err = provider.Up(ctx)
+		if len(results) == 0 && err == nil {
+			fmt.Fprintln(w, "no migrations to run")
+		}
+
+	case "down":
+		var result *goose.MigrationResult
+		if result, err = provider.Down(ctx); result != nil {
+			results = append(results, result)
+		}
+
+	case "redo":
+		var result *goose.MigrationResult
+		if result, err = provider.Down(ctx); result != nil {
+			results = append(results, result)
+		}
+
+		if err == nil {
+			if result, err = provider.UpByOne(ctx); result != nil {
+				results = append(results, result)
+			}
+		}
+
+	case "status":
+		return writeStatus(ctx, provider, w)
+
+	default:
+		return fmt.Errorf("unknown migration command %q", command)
+	}
+
+	for _, result := range results {
+		fmt.Fprintln(w, result)
+	}
+
+	return err
+}
+
+func writeStatus(ctx context.Context, provider *goose.Provider, w io.Writer) error {
+
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tSTATE\tAPPLIED AT\tMIGRATION")
+
+	for _, status := range statuses {
+
+		appliedAt := "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+	}
+
+	return tw.Flush()
+}
+
+// VersionSource tells how far the database is migrated, *goose.Provider is
+// one.
+type VersionSource interface {
+	HasPending(ctx context.Context) (bool, error)
+	GetVersions(ctx context.Context) (current, target int64, err error)
+}
+
+// CheckSchema returns ErrSchemaBehind when the database is not migrated up to
+// the last embedded migration.
+func CheckSchema(ctx context.Context, dbpool *pgxpool.Pool) error {
+
+	provider, db, err := newProvider(dbpool)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	return CheckVersions(ctx, provider)
+}
+
+// CheckVersions returns ErrSchemaBehind, with both versions in the message,
+// when source has migrations left to run.
+func CheckVersions(ctx context.Context, source VersionSource) error {
+
+	pending, err := source.HasPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !pending {
+		return nil
+	}
+
+	current, target, err := source.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: the database is at version %d and this build needs %d, run `app migrate up` first", ErrSchemaBehind, current, target)
+}
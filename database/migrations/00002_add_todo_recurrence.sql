@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE todo
+  ADD COLUMN recurrence_rule TEXT,
+  ADD COLUMN recurrence_start DATE,
+  ADD COLUMN series_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS todo_series_id_due_date_idx ON todo (series_id, due_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS todo_series_id_due_date_idx;
+
+ALTER TABLE todo
+  DROP COLUMN IF EXISTS series_id,
+  DROP COLUMN IF EXISTS recurrence_start,
+  DROP COLUMN IF EXISTS recurrence_rule;
+-- +goose StatementEnd
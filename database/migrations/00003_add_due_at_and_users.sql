@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS users (
+  id UUID PRIMARY KEY,
+  timezone VARCHAR NOT NULL DEFAULT 'UTC',
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- due_date stays the local calendar day of the task so date-only tasks keep
+-- working; due_at is only set when the task is due at a specific instant.
+ALTER TABLE todo ADD COLUMN due_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todo DROP COLUMN IF EXISTS due_at;
+
+DROP TABLE IF EXISTS users;
+-- +goose StatementEnd
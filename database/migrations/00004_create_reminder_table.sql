@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TYPE reminder_channel AS ENUM ('webhook', 'email', 'in_app');
+
+CREATE TABLE IF NOT EXISTS reminder (
+  id UUID PRIMARY KEY,
+  todo_id UUID NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+  user_id UUID,
+  channel reminder_channel NOT NULL DEFAULT 'in_app',
+  target VARCHAR,
+  remind_at TIMESTAMPTZ,
+  offset_minutes INTEGER,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  fired_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  CHECK (remind_at IS NOT NULL OR offset_minutes IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS reminder_todo_id_idx ON reminder (todo_id);
+CREATE INDEX IF NOT EXISTS reminder_pending_idx ON reminder (created_at) WHERE fired_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification (
+  id UUID PRIMARY KEY,
+  user_id UUID,
+  todo_id UUID REFERENCES todo (id) ON DELETE CASCADE,
+  message TEXT NOT NULL,
+  read_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS notification_user_id_idx ON notification (user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification;
+DROP TABLE IF EXISTS reminder;
+DROP TYPE IF EXISTS reminder_channel;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE users
+  ADD COLUMN email VARCHAR,
+  ADD COLUMN email_reminders BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN email_digest BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN email_assignments BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN unsubscribe_token UUID UNIQUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+  DROP COLUMN IF EXISTS unsubscribe_token,
+  DROP COLUMN IF EXISTS email_assignments,
+  DROP COLUMN IF EXISTS email_digest,
+  DROP COLUMN IF EXISTS email_reminders,
+  DROP COLUMN IF EXISTS email;
+-- +goose StatementEnd
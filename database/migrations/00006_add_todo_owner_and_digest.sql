@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE todo ADD COLUMN user_id UUID;
+
+CREATE INDEX IF NOT EXISTS todo_user_id_due_date_idx ON todo (user_id, due_date);
+
+ALTER TABLE users ADD COLUMN digest_sent_on DATE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS digest_sent_on;
+
+DROP INDEX IF EXISTS todo_user_id_due_date_idx;
+
+ALTER TABLE todo DROP COLUMN IF EXISTS user_id;
+-- +goose StatementEnd
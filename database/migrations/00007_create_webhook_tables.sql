@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE todo ADD COLUMN completed_at TIMESTAMPTZ;
+
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'succeeded', 'failed');
+
+CREATE TABLE IF NOT EXISTS webhook_endpoint (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  url VARCHAR NOT NULL,
+  secret VARCHAR NOT NULL,
+  events TEXT[] NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_endpoint_user_id_idx ON webhook_endpoint (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+  id UUID PRIMARY KEY,
+  endpoint_id UUID NOT NULL REFERENCES webhook_endpoint (id) ON DELETE CASCADE,
+  event VARCHAR NOT NULL,
+  payload JSONB NOT NULL,
+  status webhook_delivery_status NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_response_code INTEGER,
+  last_error TEXT,
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_endpoint_id_idx ON webhook_delivery (endpoint_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS webhook_delivery_pending_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook_endpoint;
+DROP TYPE IF EXISTS webhook_delivery_status;
+
+ALTER TABLE todo DROP COLUMN IF EXISTS completed_at;
+-- +goose StatementEnd
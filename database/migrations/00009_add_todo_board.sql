@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todo ADD COLUMN board VARCHAR NOT NULL DEFAULT 'inbox';
+
+CREATE INDEX IF NOT EXISTS todo_board_idx ON todo (board, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS todo_board_idx;
+
+ALTER TABLE todo DROP COLUMN IF EXISTS board;
+-- +goose StatementEnd
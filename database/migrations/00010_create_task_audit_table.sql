@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS task_audit (
+  id BIGSERIAL PRIMARY KEY,
+  task_id UUID NOT NULL,
+  actor UUID,
+  action VARCHAR NOT NULL,
+  trace_id VARCHAR,
+  changes JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_audit_task_id_idx ON task_audit (task_id, id);
+CREATE INDEX IF NOT EXISTS task_audit_actor_idx ON task_audit (actor, created_at);
+CREATE INDEX IF NOT EXISTS task_audit_created_at_idx ON task_audit (created_at);
+
+CREATE OR REPLACE FUNCTION task_audit_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'task_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER task_audit_append_only
+BEFORE UPDATE OR DELETE ON task_audit
+FOR EACH ROW EXECUTE FUNCTION task_audit_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS task_audit;
+DROP FUNCTION IF EXISTS task_audit_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS todo_tag (
+  todo_id UUID NOT NULL REFERENCES todo (id) ON DELETE CASCADE,
+  tag VARCHAR(64) NOT NULL,
+  PRIMARY KEY (todo_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS todo_tag_tag_idx ON todo_tag (tag);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS todo_tag;
+-- +goose StatementEnd
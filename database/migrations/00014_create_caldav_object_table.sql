@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- CalDAV clients choose the name and UID of the tasks they create, tasks
+-- created anywhere else are served as <id>.ics with their id as UID.
+CREATE TABLE IF NOT EXISTS caldav_object (
+  todo_id UUID PRIMARY KEY REFERENCES todo (id) ON DELETE CASCADE,
+  user_id UUID NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  uid VARCHAR(255) NOT NULL,
+  UNIQUE (user_id, name)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS caldav_object;
+-- +goose StatementEnd
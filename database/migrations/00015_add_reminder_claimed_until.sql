@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A claimed reminder is left alone by the other workers until claimed_until,
+-- so it can be delivered outside the transaction that claimed it.
+ALTER TABLE reminder ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminder DROP COLUMN IF EXISTS claimed_until;
+-- +goose StatementEnd
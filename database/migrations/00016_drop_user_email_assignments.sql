@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Tasks have no assignee, nothing ever sent assignment emails.
+ALTER TABLE users DROP COLUMN IF EXISTS email_assignments;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_assignments BOOLEAN NOT NULL DEFAULT TRUE;
+-- +goose StatementEnd
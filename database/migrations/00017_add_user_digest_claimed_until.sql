@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A user claimed for a digest is left alone by the other workers until
+-- digest_claimed_until, so the digest can be sent outside the transaction that
+-- claimed it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_claimed_until TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS digest_claimed_until;
+-- +goose StatementEnd
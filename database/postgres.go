@@ -4,33 +4,99 @@ import (
 	"context"
 	"embed"
 	"fmt"
-	"os"
+	"ilcs/internal/config"
+	"strconv"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/jackc/pgx/v5/stdlib"
-	"github.com/pressly/goose/v3"
+	"github.com/rs/zerolog/log"
 )
 
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
-func ConnectPG() *pgxpool.Pool {
-	dbpool, err := pgxpool.New(context.Background(), os.Getenv("GOOSE_DBSTRING"))
+// PoolConfig is the pgx config of a pool of cfg, settings left at zero keep
+// the defaults of pgx.
+func PoolConfig(cfg config.Database) (*pgxpool.Config, error) {
+
+	poolConfig, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = int32(cfg.MaxConns)
+	}
+
+	if cfg.MinConns > 0 {
+		poolConfig.MinConns = int32(cfg.MinConns)
+	}
+
+	if cfg.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+
+	if cfg.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		poolConfig.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
+
+// OpenPG connects to the database without touching its schema.
+func OpenPG(cfg config.Database) *pgxpool.Pool {
+	poolConfig, err := PoolConfig(cfg)
 	if err != nil {
 		panic(fmt.Errorf("unable to connect to database: %v", err))
 	}
 
-	db := stdlib.OpenDBFromPool(dbpool)
+	dbpool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		panic(fmt.Errorf("unable to connect to database: %v", err))
+	}
 
-	// Run migrations
-	goose.SetBaseFS(embedMigrations)
+	return dbpool
+}
+
+// OpenMigrationPG connects to the database to migrate it, a migration may run
+// longer than the statement timeout of the queries.
+func OpenMigrationPG(cfg config.Database) *pgxpool.Pool {
+	cfg.StatementTimeout = 0
+	cfg.MinConns = 0
+
+	return OpenPG(cfg)
+}
 
-	if err := goose.SetDialect("pgx"); err != nil {
-		panic(fmt.Errorf("failed to set dialect: %v", err))
+// ConnectPG connects to a database whose schema is up to date. Migrations are
+// run by `app migrate up`, or here first when AUTO_MIGRATE is true.
+func ConnectPG(cfg config.Database) *pgxpool.Pool {
+	ctx := context.Background()
+
+	if cfg.AutoMigrate {
+		migrationPool := OpenMigrationPG(cfg)
+		err := Migrate(ctx, migrationPool, "up", log.Logger)
+		migrationPool.Close()
+
+		if err != nil {
+			panic(fmt.Errorf("failed to run migrations: %v", err))
+		}
 	}
 
-	if err := goose.Up(db, "migrations"); err != nil {
-		panic(fmt.Errorf("failed to run migrations: %v", err))
+	dbpool := OpenPG(cfg)
+
+	if err := CheckSchema(ctx, dbpool); err != nil {
+		panic(err)
 	}
 
 	return dbpool
@@ -0,0 +1,32 @@
+-- name: CopyTaskAudit :copyfrom
+INSERT INTO task_audit (task_id, actor, action, trace_id, changes) VALUES ($1, $2, $3, $4, $5);
+
+-- name: InsertTaskAudit :one
+INSERT INTO task_audit (task_id, actor, action, trace_id, changes) VALUES ($1, $2, $3, $4, $5) RETURNING id;
+
+-- name: GetLatestTaskAudit :one
+-- The last change of a task, undo tokens are only valid while it is theirs.
+SELECT * FROM task_audit
+WHERE task_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListTaskHistory :many
+SELECT * FROM task_audit
+WHERE task_id = $1
+ORDER BY id;
+
+-- name: ListTaskHistories :many
+SELECT * FROM task_audit
+WHERE task_id = ANY(sqlc.arg(task_ids)::uuid[])
+ORDER BY id;
+
+-- name: SearchTaskAudit :many
+SELECT * FROM task_audit
+WHERE
+    (sqlc.narg(actor)::uuid IS NULL OR actor = sqlc.narg(actor)) AND
+    (sqlc.narg(from_ts)::timestamptz IS NULL OR created_at >= sqlc.narg(from_ts)) AND
+    (sqlc.narg(to_ts)::timestamptz IS NULL OR created_at < sqlc.narg(to_ts))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit_val)::integer
+OFFSET (sqlc.arg(page)::integer - 1) * sqlc.arg(limit_val)::integer;
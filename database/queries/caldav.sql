@@ -0,0 +1,20 @@
+-- name: InsertCaldavObject :exec
+INSERT INTO caldav_object (todo_id, user_id, name, uid) VALUES ($1, $2, $3, $4);
+
+-- name: GetCaldavObjectByName :one
+SELECT * FROM caldav_object WHERE user_id = $1 AND name = $2;
+
+-- name: GetCaldavTodo :one
+-- CalDAV reads tasks here rather than through the task cache, and only those
+-- of the user.
+SELECT * FROM todo WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
+
+-- name: ListCaldavTodos :many
+-- The tasks of a user with the name and UID their CalDAV client gave them, a
+-- page at a time after the given id.
+SELECT t.*, o.name, o.uid
+FROM todo t
+LEFT JOIN caldav_object o ON o.todo_id = t.id
+WHERE t.user_id = sqlc.arg(user_id) AND t.deleted_at IS NULL AND t.id > sqlc.arg(after_id)
+ORDER BY t.id
+LIMIT sqlc.arg(limit_val)::integer;
@@ -0,0 +1,38 @@
+-- name: InsertCalendarFeed :one
+INSERT INTO calendar_feed (id, user_id, name, token_hash, component, status, search, board) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+
+-- name: ListCalendarFeedsByUser :many
+SELECT * FROM calendar_feed WHERE user_id = $1 ORDER BY created_at;
+
+-- name: GetCalendarFeedByToken :one
+SELECT * FROM calendar_feed WHERE token_hash = $1;
+
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feed WHERE id = $1 AND user_id = $2;
+
+-- name: ListCalendarTodos :many
+-- The tasks of a feed: those of its owner due since a given day that match
+-- the saved filters, which use the same rules as ListTodo.
+SELECT
+    t.id,
+    t.title,
+    t.description,
+    t.status,
+    t.due_date,
+    t.due_at,
+    t.completed_at,
+    t.created_at,
+    t.updated_at,
+    ARRAY(SELECT tag FROM todo_tag WHERE todo_id = t.id ORDER BY tag)::text[] AS tags
+FROM todo t
+WHERE
+    t.user_id = sqlc.arg(user_id) AND
+    t.due_date >= sqlc.arg(due_since) AND
+    (sqlc.narg(status)::text IS NULL OR t.status = sqlc.narg(status)::todo_status) AND
+    (sqlc.narg(search)::text IS NULL OR
+        (t.title ILIKE '%' || sqlc.narg(search) || '%' OR
+         t.description ILIKE '%' || sqlc.narg(search) || '%')) AND
+    (sqlc.narg(board)::text IS NULL OR t.board = sqlc.narg(board)) AND
+    t.deleted_at IS NULL
+ORDER BY t.due_date, t.due_at NULLS FIRST
+LIMIT sqlc.arg(limit_val)::integer;
@@ -0,0 +1,38 @@
+-- name: ListDigestTodos :many
+SELECT
+    id,
+    title,
+    due_date,
+    due_at
+FROM todo
+WHERE
+    user_id = sqlc.arg(user_id) AND
+    status = 'pending' AND
+    deleted_at IS NULL AND
+    due_date < sqlc.arg(due_before)
+ORDER BY due_date, due_at NULLS LAST
+LIMIT sqlc.arg(limit_val)::integer;
+
+-- name: ClaimDigestUsers :many
+-- Users whose local time has reached the digest hour and who have not
+-- received today's digest yet. The claimed users are skipped by the other
+-- workers for the lease, a worker that dies while sending leaves them to be
+-- claimed again once it is over.
+WITH due AS (
+    SELECT id FROM users
+    WHERE
+        (digest_sent_on IS NULL OR digest_sent_on < (NOW() AT TIME ZONE timezone)::date) AND
+        EXTRACT(HOUR FROM NOW() AT TIME ZONE timezone) >= sqlc.arg(hour)::integer AND
+        (digest_claimed_until IS NULL OR digest_claimed_until <= NOW())
+    ORDER BY id
+    LIMIT sqlc.arg(limit_val)::integer
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE users u
+SET digest_claimed_until = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::integer)
+FROM due
+WHERE u.id = due.id
+RETURNING u.*;
+
+-- name: MarkDigestSent :exec
+UPDATE users SET digest_sent_on = $2, digest_claimed_until = NULL WHERE id = $1;
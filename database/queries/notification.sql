@@ -0,0 +1,8 @@
+-- name: InsertNotification :exec
+INSERT INTO notification (id, user_id, todo_id, message) VALUES ($1, $2, $3, $4);
+
+-- name: ListNotificationsByUser :many
+SELECT * FROM notification
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_val)::integer;
@@ -0,0 +1,22 @@
+-- name: CopyOutboxEvents :copyfrom
+INSERT INTO outbox (event_id, type, task_id, user_id, payload) VALUES ($1, $2, $3, $4, $5);
+
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (event_id, type, task_id, user_id, payload) VALUES ($1, $2, $3, $4, $5);
+
+-- name: LockOutboxRelay :one
+-- Only one relay may dispatch at a time so events leave in insertion order.
+-- The lock is released when the transaction ends.
+SELECT pg_try_advisory_xact_lock(hashtext('outbox_relay'));
+
+-- name: ListPendingOutboxEvents :many
+SELECT * FROM outbox
+WHERE dispatched_at IS NULL
+ORDER BY id
+LIMIT sqlc.arg(limit_val)::integer;
+
+-- name: MarkOutboxDispatched :exec
+UPDATE outbox SET dispatched_at = NOW() WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: PurgeDispatchedOutbox :exec
+DELETE FROM outbox WHERE dispatched_at < $1;
@@ -0,0 +1,57 @@
+-- name: InsertReminder :one
+INSERT INTO reminder (id, todo_id, user_id, channel, target, remind_at, offset_minutes) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: ListRemindersByTodo :many
+SELECT * FROM reminder WHERE todo_id = $1 AND user_id = $2 ORDER BY created_at;
+
+-- name: ListRemindersByTodos :many
+SELECT * FROM reminder WHERE todo_id = ANY(sqlc.arg(todo_ids)::uuid[]) AND user_id = sqlc.arg(user_id) ORDER BY created_at;
+
+-- name: DeleteReminder :execrows
+DELETE FROM reminder WHERE id = $1 AND user_id = $2;
+
+-- name: ClaimDueReminders :many
+-- Offset reminders are resolved against the current due time of the task, so
+-- rescheduling a task moves its reminders with it. Date-only tasks are due at
+-- midnight in the owner's time zone. The claimed reminders are skipped by the
+-- other workers for the lease, a worker that dies while sending leaves them to
+-- be claimed again once it is over.
+WITH due AS (
+    SELECT r.id
+    FROM reminder r
+    JOIN todo t ON t.id = r.todo_id
+    LEFT JOIN users u ON u.id = r.user_id
+    WHERE
+        r.fired_at IS NULL AND
+        r.attempts < sqlc.arg(max_attempts)::integer AND
+        (r.claimed_until IS NULL OR r.claimed_until <= NOW()) AND
+        t.status = 'pending' AND
+        t.deleted_at IS NULL AND
+        COALESCE(
+            r.remind_at,
+            COALESCE(t.due_at, t.due_date::timestamp AT TIME ZONE COALESCE(u.timezone, 'UTC')) - make_interval(mins => r.offset_minutes)
+        ) <= NOW()
+    ORDER BY r.created_at
+    LIMIT sqlc.arg(limit_val)::integer
+    FOR UPDATE OF r SKIP LOCKED
+)
+UPDATE reminder r
+SET claimed_until = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::integer)
+FROM due, todo t
+WHERE r.id = due.id AND t.id = r.todo_id
+RETURNING
+    r.id,
+    r.todo_id,
+    r.user_id,
+    r.channel,
+    r.target,
+    r.attempts,
+    t.title,
+    t.due_date,
+    t.due_at;
+
+-- name: MarkReminderFired :exec
+UPDATE reminder SET fired_at = NOW(), last_error = NULL, claimed_until = NULL WHERE id = $1;
+
+-- name: MarkReminderFailed :exec
+UPDATE reminder SET attempts = attempts + 1, last_error = $2, claimed_until = NULL WHERE id = $1;
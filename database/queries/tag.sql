@@ -0,0 +1,17 @@
+-- name: AddTodoTags :many
+-- Returns the tags the task did not have yet.
+INSERT INTO todo_tag (todo_id, tag)
+SELECT sqlc.arg(todo_id), unnest(sqlc.arg(tags)::text[])
+ON CONFLICT DO NOTHING
+RETURNING tag;
+
+-- name: RemoveTodoTags :many
+DELETE FROM todo_tag
+WHERE todo_id = sqlc.arg(todo_id) AND tag = ANY(sqlc.arg(tags)::text[])
+RETURNING tag;
+
+-- name: ListTodoTags :many
+SELECT tag FROM todo_tag WHERE todo_id = $1 ORDER BY tag;
+
+-- name: ListTagsByTodos :many
+SELECT todo_id, tag FROM todo_tag WHERE todo_id = ANY(sqlc.arg(todo_ids)::uuid[]) ORDER BY todo_id, tag;
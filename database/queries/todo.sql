@@ -1,5 +1,14 @@
 -- name: InsertTodo :one
-INSERT INTO todo (id, title, description, due_date) VALUES ($1, $2, $3, $4) RETURNING *;
+INSERT INTO todo (id, title, description, due_date, recurrence_rule, recurrence_start, series_id, due_at, user_id, board) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
+
+-- name: CopyTodos :copyfrom
+-- Bulk insert of imported tasks, they carry their own timestamps so the
+-- snapshots recorded for them match the rows.
+INSERT INTO todo (id, title, description, status, due_date, recurrence_rule, recurrence_start, series_id, due_at, user_id, board, created_at, updated_at, completed_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+
+-- name: InsertTodoOccurrence :execrows
+INSERT INTO todo (id, title, description, due_date, recurrence_rule, recurrence_start, series_id, due_at, user_id, board) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (series_id, due_date) DO NOTHING;
 
 -- name: ListTodo :many
 WITH filtered_todo AS (
@@ -9,14 +18,21 @@ WITH filtered_todo AS (
         (sqlc.arg(status)::text IS NULL OR status = sqlc.arg(status)::todo_status) AND
         (sqlc.arg(search)::text IS NULL OR 
             (title ILIKE '%' || sqlc.arg(search) || '%' OR 
-             description ILIKE '%' || sqlc.arg(search) || '%'))
+             description ILIKE '%' || sqlc.arg(search) || '%')) AND
+        (sqlc.arg(board)::text IS NULL OR board = sqlc.arg(board)) AND
+        deleted_at IS NULL
 )
 SELECT 
     id,
     title,
     description,
     status,
-    due_date
+    due_date,
+    recurrence_rule,
+    due_at,
+    board,
+    updated_at,
+    completed_at
 FROM filtered_todo
 ORDER BY created_at DESC
 LIMIT sqlc.arg(limit_val)::integer
@@ -29,22 +45,182 @@ WHERE
     (sqlc.arg(status)::text IS NULL OR status = sqlc.arg(status)::todo_status) AND
     (sqlc.arg(search)::text IS NULL OR 
         (title ILIKE '%' || sqlc.arg(search) || '%' OR 
-         description ILIKE '%' || sqlc.arg(search) || '%'));
+         description ILIKE '%' || sqlc.arg(search) || '%')) AND
+    (sqlc.arg(board)::text IS NULL OR board = sqlc.arg(board)) AND
+    deleted_at IS NULL;
+
+-- name: ListTodoAfter :many
+-- Keyset variant of ListTodo for cursor paging: the tasks that come after the
+-- one with the given created_at and id, so tasks created meanwhile don't shift
+-- the next pages.
+SELECT
+    id,
+    title,
+    description,
+    status,
+    due_date,
+    recurrence_rule,
+    due_at,
+    board,
+    updated_at,
+    created_at,
+    completed_at
+FROM todo
+WHERE
+    (sqlc.arg(status)::text IS NULL OR status = sqlc.arg(status)::todo_status) AND
+    (sqlc.arg(search)::text IS NULL OR
+        (title ILIKE '%' || sqlc.arg(search) || '%' OR
+         description ILIKE '%' || sqlc.arg(search) || '%')) AND
+    (sqlc.arg(board)::text IS NULL OR board = sqlc.arg(board)) AND
+    deleted_at IS NULL AND
+    (sqlc.narg(after_created_at)::timestamptz IS NULL OR
+        (created_at, id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_val)::integer;
 
 
+-- name: ExportTodo :many
+-- Same filters as ListTodo without the paging, grouped by board. Exports read
+-- it through EachExportTodo, which streams the rows instead of collecting them.
+SELECT
+    t.id,
+    t.title,
+    t.description,
+    t.status,
+    t.due_date,
+    t.recurrence_rule,
+    t.due_at,
+    t.board,
+    ARRAY(SELECT tag FROM todo_tag WHERE todo_id = t.id ORDER BY tag)::text[] AS tags
+FROM todo t
+WHERE
+    (sqlc.arg(status)::text IS NULL OR t.status = sqlc.arg(status)::todo_status) AND
+    (sqlc.arg(search)::text IS NULL OR
+        (t.title ILIKE '%' || sqlc.arg(search) || '%' OR
+         t.description ILIKE '%' || sqlc.arg(search) || '%')) AND
+    (sqlc.arg(board)::text IS NULL OR t.board = sqlc.arg(board)) AND
+    t.deleted_at IS NULL
+ORDER BY t.board, t.created_at DESC;
+
 -- name: UpdateTodo :one
 UPDATE todo 
 SET 
-    title = $2,
-    description = $3,
-    status = $4,
-    due_date = $5,
+    title = sqlc.arg(title),
+    description = sqlc.arg(description),
+    status = sqlc.arg(status),
+    due_date = sqlc.arg(due_date),
+    due_at = sqlc.narg(due_at),
+    recurrence_rule = COALESCE(sqlc.narg(recurrence_rule), recurrence_rule),
+    completed_at = CASE
+        WHEN sqlc.arg(status) = 'completed' AND status <> 'completed' THEN NOW()
+        WHEN sqlc.arg(status) = 'completed' THEN completed_at
+        ELSE NULL
+    END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateTodoDueDate :one
+UPDATE todo
+SET
+    due_date = $2,
+    due_at = $3,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: EndTodoSeries :many
+UPDATE todo t
+SET
+    recurrence_rule = NULL,
+    updated_at = NOW()
+FROM (SELECT id, recurrence_rule FROM todo WHERE (series_id = $1 OR id = $1) AND deleted_at IS NULL FOR UPDATE) old
+WHERE t.id = old.id
+RETURNING t.*, old.recurrence_rule AS previous_recurrence_rule;
+
+-- name: MoveTodo :one
+-- previous_board lets subscribers of the old board drop the task.
+UPDATE todo t
+SET
+    board = sqlc.arg(board),
     updated_at = NOW()
-WHERE id = $1 
+FROM (SELECT id, board FROM todo WHERE id = sqlc.arg(id) AND deleted_at IS NULL FOR UPDATE) old
+WHERE t.id = old.id
+RETURNING t.*, old.board AS previous_board;
+
+-- name: CompleteTodo :one
+UPDATE todo
+SET
+    status = 'completed',
+    completed_at = CASE WHEN status <> 'completed' THEN NOW() ELSE completed_at END,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteTodo :one
+-- Moves the task to the trash, PurgeTrashedTodos deletes it for good.
+UPDATE todo
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreTodo :one
+UPDATE todo t
+SET
+    deleted_at = NULL,
+    updated_at = NOW()
+FROM (SELECT id, deleted_at FROM todo WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL FOR UPDATE) old
+WHERE t.id = old.id
+RETURNING t.*, old.deleted_at AS previous_deleted_at;
+
+-- name: ListTrash :many
+SELECT * FROM todo
+WHERE deleted_at IS NOT NULL AND user_id = sqlc.arg(user_id)
+ORDER BY deleted_at DESC
+LIMIT sqlc.arg(limit_val)::integer
+OFFSET (sqlc.arg(page)::integer - 1) * sqlc.arg(limit_val)::integer;
+
+-- name: CountTrash :one
+SELECT COUNT(*) FROM todo WHERE deleted_at IS NOT NULL AND user_id = $1;
+
+-- name: PurgeTrashedTodos :many
+DELETE FROM todo
+WHERE id IN (
+    SELECT id FROM todo
+    WHERE deleted_at < sqlc.arg(deleted_before)
+    ORDER BY deleted_at
+    LIMIT sqlc.arg(limit_val)::integer
+    FOR UPDATE SKIP LOCKED
+)
 RETURNING *;
 
--- name: DeleteTodo :exec
-DELETE FROM todo WHERE id = $1;
+-- name: GetTodoForUpdate :one
+-- Locks the task until the end of the transaction, so the state it returns is
+-- the state the following update starts from.
+SELECT * FROM todo WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;
+
+-- name: LockTodo :one
+-- Unlike GetTodoForUpdate it also locks tasks in the trash.
+SELECT * FROM todo WHERE id = $1 FOR UPDATE;
+
+-- name: SetTodoFields :one
+-- Overwrites every editable field, completed_at included, for undo and bulk
+-- updates that compute the whole new state themselves.
+UPDATE todo
+SET
+    title = sqlc.arg(title),
+    description = sqlc.arg(description),
+    status = sqlc.arg(status),
+    due_date = sqlc.arg(due_date),
+    due_at = sqlc.narg(due_at),
+    recurrence_rule = sqlc.narg(recurrence_rule),
+    completed_at = sqlc.narg(completed_at),
+    board = sqlc.arg(board),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
 
 -- name: GetTodoById :one
 SELECT 
@@ -52,6 +228,14 @@ SELECT
     title,
     description,
     status,
-    due_date
+    due_date,
+    recurrence_rule,
+    recurrence_start,
+    series_id,
+    due_at,
+    user_id,
+    board,
+    updated_at,
+    completed_at
 FROM todo
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
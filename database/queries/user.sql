@@ -0,0 +1,26 @@
+-- name: GetUserById :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: UpsertUserTimezone :one
+INSERT INTO users (id, timezone) VALUES ($1, $2)
+ON CONFLICT (id) DO UPDATE SET timezone = EXCLUDED.timezone, updated_at = NOW()
+RETURNING *;
+
+-- name: UpsertUserPreferences :one
+INSERT INTO users (id, email, email_reminders, email_digest, unsubscribe_token) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO UPDATE SET
+    email = EXCLUDED.email,
+    email_reminders = EXCLUDED.email_reminders,
+    email_digest = EXCLUDED.email_digest,
+    unsubscribe_token = COALESCE(users.unsubscribe_token, EXCLUDED.unsubscribe_token),
+    updated_at = NOW()
+RETURNING *;
+
+-- name: UnsubscribeUser :one
+UPDATE users
+SET
+    email_reminders = CASE WHEN sqlc.arg(kind)::text IN ('reminder', 'all') THEN FALSE ELSE email_reminders END,
+    email_digest = CASE WHEN sqlc.arg(kind)::text IN ('digest', 'all') THEN FALSE ELSE email_digest END,
+    updated_at = NOW()
+WHERE unsubscribe_token = sqlc.arg(unsubscribe_token)
+RETURNING id;
@@ -0,0 +1,60 @@
+-- name: InsertWebhookEndpoint :one
+INSERT INTO webhook_endpoint (id, user_id, url, secret, events) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: ListWebhookEndpointsByUser :many
+SELECT * FROM webhook_endpoint WHERE user_id = $1 ORDER BY created_at;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoint WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoint WHERE id = $1 AND user_id = $2;
+
+-- name: ListWebhookEndpointsForEvent :many
+SELECT * FROM webhook_endpoint
+WHERE user_id = sqlc.arg(user_id) AND active AND sqlc.arg(event)::text = ANY(events);
+
+-- name: InsertWebhookDelivery :one
+INSERT INTO webhook_delivery (id, endpoint_id, event, payload) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: ClaimWebhookDeliveries :many
+-- Pushes the next attempt of the claimed deliveries past the lease, so the
+-- other workers skip them while they are sent and pick them up again if the
+-- worker sending them dies.
+WITH due AS (
+    SELECT id
+    FROM webhook_delivery
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(limit_val)::integer
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_delivery d
+SET next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::integer)
+FROM due, webhook_endpoint e
+WHERE d.id = due.id AND e.id = d.endpoint_id
+RETURNING
+    d.id,
+    d.event,
+    d.payload,
+    d.attempts,
+    e.url,
+    e.secret;
+
+-- name: RecordWebhookAttempt :one
+UPDATE webhook_delivery
+SET
+    status = sqlc.arg(status),
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_response_code = sqlc.narg(last_response_code),
+    last_error = sqlc.narg(last_error),
+    delivered_at = CASE WHEN sqlc.arg(status) = 'succeeded' THEN NOW() ELSE NULL END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_delivery
+WHERE endpoint_id = sqlc.arg(endpoint_id)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_val)::integer;
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ilcs/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// versions is a database migrated up to current of a build that ships target.
+type versions struct {
+	current, target int64
+	err             error
+}
+
+func (v versions) HasPending(ctx context.Context) (bool, error) {
+	return v.current < v.target, v.err
+}
+
+func (v versions) GetVersions(ctx context.Context) (int64, int64, error) {
+	return v.current, v.target, v.err
+}
+
+func TestCheckVersions_OneMigrationBehind(t *testing.T) {
+	err := database.CheckVersions(context.Background(), versions{current: 16, target: 17})
+
+	assert.ErrorIs(t, err, database.ErrSchemaBehind)
+	assert.EqualError(t, err, "database schema is behind this build: the database is at version 16 and this build needs 17, run `app migrate up` first")
+}
+
+func TestCheckVersions_UpToDate(t *testing.T) {
+	assert.NoError(t, database.CheckVersions(context.Background(), versions{current: 17, target: 17}))
+}
+
+func TestCheckVersions_Unreachable(t *testing.T) {
+	refused := errors.New("connection refused")
+
+	err := database.CheckVersions(context.Background(), versions{err: refused})
+
+	assert.ErrorIs(t, err, refused)
+	assert.NotErrorIs(t, err, database.ErrSchemaBehind)
+}
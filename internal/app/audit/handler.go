@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"errors"
+	"ilcs/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IAuditHandler interface {
+	TaskHistory(c *gin.Context)
+	Search(c *gin.Context)
+}
+
+type AuditHandler struct {
+	service IAuditService
+}
+
+func NewAuditHandler(service IAuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+func (h *AuditHandler) TaskHistory(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.service.TaskHistory(c, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"history": entries})
+}
+
+func (h *AuditHandler) Search(c *gin.Context) {
+
+	var req SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, page, limit, err := h.service.Search(c, req)
+	if errors.Is(err, ErrInvalidActor) || errors.Is(err, ErrInvalidTime) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"entries": entries, "page": page, "limit": limit})
+}
@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Entry struct {
+	ID      int64  `json:"id"`
+	TaskID  string `json:"task_id"`
+	Actor   string `json:"actor,omitempty"`
+	Action  string `json:"action"`
+	TraceID string `json:"trace_id,omitempty"`
+	// Changes maps every changed field to its value before and after.
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// SearchRequest filters the audit log. From and To are RFC 3339 times, To is
+// exclusive.
+type SearchRequest struct {
+	Actor *string `form:"actor"`
+	From  *string `form:"from"`
+	To    *string `form:"to"`
+	Page  *int    `form:"page"`
+	Limit *int    `form:"limit"`
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"ilcs/internal/repositories"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidActor = errors.New("actor must be a user id")
+	ErrInvalidTime  = errors.New("from and to must be RFC 3339 times")
+)
+
+type IAuditService interface {
+	TaskHistory(ctx context.Context, id string) (entries []Entry, err error)
+	Search(ctx context.Context, req SearchRequest) (entries []Entry, page, limit int, err error)
+}
+
+type AuditService struct {
+	repo repositories.Querier
+}
+
+func NewAuditService(repo repositories.Querier) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// TaskHistory returns the audit log of a task, oldest first. The log outlives
+// the task, so the history of a deleted task can still be read.
+func (s *AuditService) TaskHistory(ctx context.Context, id string) (entries []Entry, err error) {
+
+	taskId, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data, err := s.repo.ListTaskHistory(ctx, pgtype.UUID{Bytes: taskId, Valid: true})
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	entries = toEntries(data)
+
+	return
+}
+
+// Search returns the audit log of every task, newest first.
+func (s *AuditService) Search(ctx context.Context, req SearchRequest) (entries []Entry, page, limit int, err error) {
+
+	page, limit = 1, 50
+
+	if req.Page != nil && *req.Page > 0 {
+		page = *req.Page
+	}
+
+	if req.Limit != nil && *req.Limit > 0 && *req.Limit <= 200 {
+		limit = *req.Limit
+	}
+
+	params := repositories.SearchTaskAuditParams{
+		Page:     int32(page),
+		LimitVal: int32(limit),
+	}
+
+	if req.Actor != nil && *req.Actor != "" {
+		actor, errP := uuid.Parse(*req.Actor)
+		if errP != nil {
+			err = ErrInvalidActor
+			log.Error().Err(err).Send()
+			return
+		}
+
+		params.Actor = pgtype.UUID{Bytes: actor, Valid: true}
+	}
+
+	params.FromTs, err = parseTime(req.From)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	params.ToTs, err = parseTime(req.To)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data, err := s.repo.SearchTaskAudit(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	entries = toEntries(data)
+
+	return
+}
+
+func parseTime(value *string) (ts pgtype.Timestamptz, err error) {
+
+	if value == nil || *value == "" {
+		return
+	}
+
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		err = ErrInvalidTime
+		return
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+func toEntries(data []repositories.TaskAudit) (entries []Entry) {
+
+	entries = []Entry{}
+
+	for _, item := range data {
+		entry := Entry{
+			ID:        item.ID,
+			TaskID:    item.TaskID.String(),
+			Action:    item.Action,
+			TraceID:   item.TraceID.String,
+			Changes:   item.Changes,
+			CreatedAt: item.CreatedAt.Time,
+		}
+
+		if item.Actor.Valid {
+			entry.Actor = item.Actor.String()
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/audit"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepo struct {
+	mock.Mock
+	repositories.Querier
+}
+
+func (m *MockRepo) SearchTaskAudit(ctx context.Context, params repositories.SearchTaskAuditParams) ([]repositories.TaskAudit, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.TaskAudit), args.Error(1)
+}
+
+func (m *MockRepo) ListTaskHistory(ctx context.Context, taskId pgtype.UUID) ([]repositories.TaskAudit, error) {
+	args := m.Called(ctx, taskId)
+	return args.Get(0).([]repositories.TaskAudit), args.Error(1)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSearch_Filters(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := audit.NewAuditService(mockRepo)
+
+	actor := uuid.New()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("SearchTaskAudit", mock.Anything, repositories.SearchTaskAuditParams{
+		Actor:    pgtype.UUID{Bytes: actor, Valid: true},
+		FromTs:   pgtype.Timestamptz{Time: from, Valid: true},
+		ToTs:     pgtype.Timestamptz{Time: to, Valid: true},
+		LimitVal: 50,
+		Page:     2,
+	}).Return([]repositories.TaskAudit{{
+		ID:      7,
+		TaskID:  pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Actor:   pgtype.UUID{Bytes: actor, Valid: true},
+		Action:  "update",
+		TraceID: pgtype.Text{String: "trace-1", Valid: true},
+		Changes: []byte(`{}`),
+	}}, nil)
+
+	entries, page, limit, err := service.Search(context.Background(), audit.SearchRequest{
+		Actor: ptr(actor.String()),
+		From:  ptr("2025-01-01T00:00:00Z"),
+		To:    ptr("2025-02-01T00:00:00Z"),
+		Page:  ptr(2),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, page)
+	assert.Equal(t, 50, limit)
+	require.Len(t, entries, 1)
+	assert.Equal(t, actor.String(), entries[0].Actor)
+	assert.Equal(t, "trace-1", entries[0].TraceID)
+}
+
+func TestSearch_InvalidFilters(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := audit.NewAuditService(mockRepo)
+
+	_, _, _, err := service.Search(context.Background(), audit.SearchRequest{Actor: ptr("someone")})
+	assert.ErrorIs(t, err, audit.ErrInvalidActor)
+
+	_, _, _, err = service.Search(context.Background(), audit.SearchRequest{From: ptr("yesterday")})
+	assert.ErrorIs(t, err, audit.ErrInvalidTime)
+
+	mockRepo.AssertNotCalled(t, "SearchTaskAudit", mock.Anything, mock.Anything)
+}
+
+func TestTaskHistory_Empty(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := audit.NewAuditService(mockRepo)
+
+	id := uuid.New()
+
+	mockRepo.On("ListTaskHistory", mock.Anything, pgtype.UUID{Bytes: id, Valid: true}).Return([]repositories.TaskAudit(nil), nil)
+
+	entries, err := service.TaskHistory(context.Background(), id.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, []audit.Entry{}, entries)
+}
@@ -0,0 +1,308 @@
+package board
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type IBoardHandler interface {
+	Connect(c *gin.Context)
+}
+
+type BoardHandler struct {
+	service  IBoardService
+	todos    todo.ITodoService
+	upgrader websocket.Upgrader
+	refresh  time.Duration
+}
+
+func NewBoardHandler(service IBoardService, todos todo.ITodoService) *BoardHandler {
+	return &BoardHandler{
+		service:  service,
+		todos:    todos,
+		upgrader: websocket.Upgrader{Subprotocols: []string{Protocol}},
+		refresh:  service.PresenceTTL() / 3,
+	}
+}
+
+// Connect upgrades the request to a websocket on which the client subscribes
+// to boards, receives their task events and viewers, and moves or completes
+// tasks.
+func (h *BoardHandler) Connect(c *gin.Context) {
+
+	userId := utils.GetUserId(c)
+	if err := utils.ValidateId(userId); err != nil {
+		c.JSON(403, gin.H{"error": ErrNoUser.Error()})
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already answered the request
+		log.Error().Err(err).Send()
+		return
+	}
+
+	conn := &connection{
+		ws:      ws,
+		handler: h,
+		userId:  userId,
+		id:      uuid.NewString(),
+		boards:  map[string]bool{},
+	}
+
+	conn.run(c)
+}
+
+type connection struct {
+	ws      *websocket.Conn
+	handler *BoardHandler
+	userId  string
+	id      string
+
+	mu     sync.Mutex
+	boards map[string]bool
+
+	writeMu sync.Mutex
+}
+
+func (conn *connection) run(ctx context.Context) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sub := conn.handler.service.Listen(ctx)
+
+	defer func() {
+		sub.Close()
+		conn.ws.Close()
+
+		// the request context is already done
+		for _, board := range conn.subscribed() {
+			conn.handler.service.Leave(context.Background(), board, conn.userId, conn.id)
+		}
+	}()
+
+	ttl := conn.handler.service.PresenceTTL()
+
+	conn.ws.SetReadDeadline(time.Now().Add(ttl))
+	conn.ws.SetPongHandler(func(string) error {
+		return conn.ws.SetReadDeadline(time.Now().Add(ttl))
+	})
+
+	go conn.forward(ctx, sub)
+
+	for {
+		var msg ClientMessage
+		if err := conn.ws.ReadJSON(&msg); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				conn.send(ServerMessage{Type: MessageError, Error: "invalid message"})
+				continue
+			}
+
+			return
+		}
+
+		conn.handle(ctx, sub, msg)
+	}
+}
+
+// forward writes the entries of the subscribed boards to the socket, and
+// refreshes the presence of the connection.
+func (conn *connection) forward(ctx context.Context, sub *Subscription) {
+
+	entries := sub.Entries()
+
+	ticker := time.NewTicker(conn.handler.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+
+			if !conn.isSubscribed(entry.Board) {
+				continue
+			}
+
+			msg := ServerMessage{Type: MessageEvent, Board: entry.Board, Event: entry.Type, ID: entry.ID, Data: entry.Data}
+
+			if entry.Type == EventPresence {
+				var p presence
+				if err := json.Unmarshal(entry.Data, &p); err != nil {
+					continue
+				}
+
+				msg = ServerMessage{Type: MessagePresence, Board: entry.Board, Viewers: p.Viewers}
+			}
+
+			if err := conn.send(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			for _, board := range conn.subscribed() {
+				viewers, err := conn.handler.service.Refresh(ctx, board, conn.userId, conn.id)
+				if err != nil {
+					continue
+				}
+
+				if err := conn.send(ServerMessage{Type: MessagePresence, Board: board, Viewers: viewers}); err != nil {
+					return
+				}
+			}
+
+			conn.writeMu.Lock()
+			err := conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+			conn.writeMu.Unlock()
+
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (conn *connection) handle(ctx context.Context, sub *Subscription, msg ClientMessage) {
+
+	msg.Board = strings.TrimSpace(msg.Board)
+
+	switch msg.Type {
+	case MessageSubscribe:
+		if err := sub.Add(ctx, msg.Board); err != nil {
+			conn.fail(msg.Ref, err)
+			return
+		}
+
+		viewers, err := conn.handler.service.Join(ctx, msg.Board, conn.userId, conn.id)
+		if err != nil {
+			sub.Remove(ctx, msg.Board)
+			conn.fail(msg.Ref, err)
+			return
+		}
+
+		conn.send(ServerMessage{Type: MessageSubscribed, Ref: msg.Ref, Board: msg.Board, Viewers: viewers})
+
+		// entries of the board are forwarded once the client knows it subscribed
+		conn.setSubscribed(msg.Board, true)
+	case MessageUnsubscribe:
+		if !conn.isSubscribed(msg.Board) {
+			conn.send(ServerMessage{Type: MessageError, Ref: msg.Ref, Error: "not subscribed to board"})
+			return
+		}
+
+		conn.setSubscribed(msg.Board, false)
+
+		sub.Remove(ctx, msg.Board)
+
+		if err := conn.handler.service.Leave(ctx, msg.Board, conn.userId, conn.id); err != nil {
+			conn.fail(msg.Ref, err)
+			return
+		}
+
+		conn.send(ServerMessage{Type: MessageUnsubscribed, Ref: msg.Ref, Board: msg.Board})
+	case MessageMove:
+		if err := utils.ValidateId(msg.ID); err != nil {
+			conn.send(ServerMessage{Type: MessageError, Ref: msg.Ref, Error: err.Error()})
+			return
+		}
+
+		task, undo, err := conn.handler.todos.MoveTodo(ctx, msg.ID, msg.Board)
+		if err != nil {
+			conn.fail(msg.Ref, err)
+			return
+		}
+
+		conn.send(ServerMessage{Type: MessageAck, Ref: msg.Ref, Task: task, UndoToken: undo})
+	case MessageComplete:
+		if err := utils.ValidateId(msg.ID); err != nil {
+			conn.send(ServerMessage{Type: MessageError, Ref: msg.Ref, Error: err.Error()})
+			return
+		}
+
+		task, undo, err := conn.handler.todos.CompleteTodo(ctx, msg.ID)
+		if err != nil {
+			conn.fail(msg.Ref, err)
+			return
+		}
+
+		conn.send(ServerMessage{Type: MessageAck, Ref: msg.Ref, Task: task, UndoToken: undo})
+	default:
+		conn.send(ServerMessage{Type: MessageError, Ref: msg.Ref, Error: "unknown message type"})
+	}
+}
+
+// fail answers a command with the error the HTTP API would give for it.
+func (conn *connection) fail(ref string, err error) {
+
+	message := err.Error()
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		message = "Task not found"
+	case errors.Is(err, todo.ErrInvalidBoard), errors.Is(err, ErrNoUser):
+	default:
+		message = "internal error"
+	}
+
+	conn.send(ServerMessage{Type: MessageError, Ref: ref, Error: message})
+}
+
+func (conn *connection) send(msg ServerMessage) error {
+
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+
+	conn.ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+
+	return conn.ws.WriteJSON(msg)
+}
+
+func (conn *connection) setSubscribed(board string, subscribed bool) {
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	if subscribed {
+		conn.boards[board] = true
+		return
+	}
+
+	delete(conn.boards, board)
+}
+
+func (conn *connection) isSubscribed(board string) bool {
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	return conn.boards[board]
+}
+
+func (conn *connection) subscribed() (boards []string) {
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	for board := range conn.boards {
+		boards = append(boards, board)
+	}
+
+	return
+}
@@ -0,0 +1,53 @@
+package board
+
+import "encoding/json"
+
+// Protocol is the WebSocket subprotocol of the board socket. Browsers send it
+// next to their ticket subprotocol, the server has to pick one of the offered
+// protocols for the handshake to succeed.
+const Protocol = "ilcs.board"
+
+// Messages sent by clients.
+const (
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessageMove        = "move"
+	MessageComplete    = "complete"
+)
+
+// Messages sent by the server.
+const (
+	MessageSubscribed   = "subscribed"
+	MessageUnsubscribed = "unsubscribed"
+	MessagePresence     = "presence"
+	MessageEvent        = "event"
+	MessageAck          = "ack"
+	MessageError        = "error"
+)
+
+// ClientMessage is a command sent over the board socket. Ref is echoed in the
+// ack or error answering it.
+type ClientMessage struct {
+	Type  string `json:"type"`
+	Ref   string `json:"ref,omitempty"`
+	Board string `json:"board,omitempty"`
+	ID    string `json:"id,omitempty"`
+}
+
+type ServerMessage struct {
+	Type    string          `json:"type"`
+	Ref     string          `json:"ref,omitempty"`
+	Board   string          `json:"board,omitempty"`
+	Event   string          `json:"event,omitempty"`
+	ID      string          `json:"id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Viewers []string        `json:"viewers,omitempty"`
+	Task    any             `json:"task,omitempty"`
+	// UndoToken is sent with the ack of a command, see POST /api/v1/undo/:token.
+	UndoToken string `json:"undo_token,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type presence struct {
+	Viewers []string `json:"viewers"`
+}
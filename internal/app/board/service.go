@@ -0,0 +1,280 @@
+package board
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/events"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// EventPresence is announced on a board channel when a viewer joins or leaves.
+const EventPresence = "presence"
+
+var ErrNoUser = errors.New("token is not bound to a user")
+
+type RedisClient interface {
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
+	ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd
+	ZRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	ZRemRangeByScore(ctx context.Context, key, min, max string) *redis.IntCmd
+	ZRangeByScore(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.StringSliceCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+}
+
+type IBoardService interface {
+	Join(ctx context.Context, board, userId, connId string) (viewers []string, err error)
+	Refresh(ctx context.Context, board, userId, connId string) (viewers []string, err error)
+	Leave(ctx context.Context, board, userId, connId string) (err error)
+	Listen(ctx context.Context) *Subscription
+	PresenceTTL() time.Duration
+}
+
+// BoardService keeps track of who is viewing a board. Every connection owns a
+// member of the board's presence set, scored with the time it expires at, so
+// viewers whose connection died without leaving drop out after the TTL.
+type BoardService struct {
+	client RedisClient
+	ttl    time.Duration
+}
+
+func NewBoardService(client RedisClient) *BoardService {
+	return &BoardService{
+		client: client,
+		ttl:    60 * time.Second,
+	}
+}
+
+func PresenceKey(board string) string {
+	return "presence:board:" + board
+}
+
+// PresenceTTL is how long a viewer stays present without refreshing.
+func (s *BoardService) PresenceTTL() time.Duration {
+	return s.ttl
+}
+
+// Join marks the connection as viewing the board and tells the other viewers.
+func (s *BoardService) Join(ctx context.Context, board, userId, connId string) (viewers []string, err error) {
+
+	viewers, err = s.Refresh(ctx, board, userId, connId)
+	if err != nil {
+		return
+	}
+
+	err = s.announce(ctx, board, viewers)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// Refresh extends the presence of the connection and returns the viewers.
+func (s *BoardService) Refresh(ctx context.Context, board, userId, connId string) (viewers []string, err error) {
+
+	board, err = validate(board, userId)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	key := PresenceKey(board)
+	now := time.Now()
+
+	err = s.client.ZAdd(ctx, key, redis.Z{
+		Score:  float64(now.Add(s.ttl).Unix()),
+		Member: member(userId, connId),
+	}).Err()
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	err = s.client.Expire(ctx, key, s.ttl).Err()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	viewers, err = s.viewers(ctx, key, now)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// Leave removes the connection from the viewers of the board and tells the
+// remaining viewers.
+func (s *BoardService) Leave(ctx context.Context, board, userId, connId string) (err error) {
+
+	board, err = validate(board, userId)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	key := PresenceKey(board)
+
+	err = s.client.ZRem(ctx, key, member(userId, connId)).Err()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	viewers, err := s.viewers(ctx, key, time.Now())
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	err = s.announce(ctx, board, viewers)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// Listen opens a subscription without boards, they are added with
+// Subscription.Add.
+func (s *BoardService) Listen(ctx context.Context) *Subscription {
+	return &Subscription{pubsub: s.client.Subscribe(ctx), done: make(chan struct{})}
+}
+
+// viewers drops the expired members of a presence set and returns the users
+// left, a user viewing the board from several connections is listed once.
+func (s *BoardService) viewers(ctx context.Context, key string, now time.Time) (viewers []string, err error) {
+
+	err = s.client.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(now.Unix(), 10)).Err()
+	if err != nil {
+		return
+	}
+
+	members, err := s.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: "-inf", Max: "+inf"}).Result()
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]bool, len(members))
+	viewers = []string{}
+
+	for _, m := range members {
+		userId, _, _ := strings.Cut(m, "/")
+		if !seen[userId] {
+			seen[userId] = true
+			viewers = append(viewers, userId)
+		}
+	}
+
+	sort.Strings(viewers)
+
+	return
+}
+
+func (s *BoardService) announce(ctx context.Context, board string, viewers []string) error {
+
+	data, err := json.Marshal(presence{Viewers: viewers})
+	if err != nil {
+		return err
+	}
+
+	entry, err := json.Marshal(events.FeedEntry{Type: EventPresence, Data: data})
+	if err != nil {
+		return err
+	}
+
+	return s.client.Publish(ctx, events.BoardChannel(board), entry).Err()
+}
+
+func validate(board, userId string) (string, error) {
+
+	if _, err := uuid.Parse(userId); err != nil {
+		return "", ErrNoUser
+	}
+
+	board = strings.TrimSpace(board)
+	if board == "" || len(board) > 64 {
+		return "", todo.ErrInvalidBoard
+	}
+
+	return board, nil
+}
+
+func member(userId, connId string) string {
+	return userId + "/" + connId
+}
+
+// BoardEntry is an entry announced on a board channel.
+type BoardEntry struct {
+	Board string
+	events.FeedEntry
+}
+
+// Subscription follows the channels of the boards added to it over a single
+// Redis connection.
+type Subscription struct {
+	pubsub *redis.PubSub
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (s *Subscription) Add(ctx context.Context, board string) error {
+	return s.pubsub.Subscribe(ctx, events.BoardChannel(strings.TrimSpace(board)))
+}
+
+func (s *Subscription) Remove(ctx context.Context, board string) error {
+	return s.pubsub.Unsubscribe(ctx, events.BoardChannel(strings.TrimSpace(board)))
+}
+
+// Entries delivers the entries of the subscribed boards until the
+// subscription is closed.
+func (s *Subscription) Entries() <-chan BoardEntry {
+
+	out := make(chan BoardEntry, 16)
+
+	go func() {
+		defer close(out)
+
+		for msg := range s.pubsub.Channel() {
+			board, ok := events.BoardFromChannel(msg.Channel)
+			if !ok {
+				continue
+			}
+
+			var entry events.FeedEntry
+			if err := json.Unmarshal([]byte(msg.Payload), &entry); err != nil {
+				log.Error().Err(err).Send()
+				continue
+			}
+
+			select {
+			case out <- BoardEntry{Board: board, FeedEntry: entry}:
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func (s *Subscription) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return s.pubsub.Close()
+}
@@ -0,0 +1,250 @@
+package board
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/board"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/constants"
+	"ilcs/internal/events"
+	"ilcs/internal/http/middlewares"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeTodoService moves tasks in memory and publishes the resulting events the
+// way the outbox relay would.
+type FakeTodoService struct {
+	todo.ITodoService
+	feed  *events.BoardFeed
+	tasks map[string]repositories.Todo
+}
+
+func (f *FakeTodoService) MoveTodo(ctx context.Context, id, boardName string) (repositories.Todo, string, error) {
+
+	task, ok := f.tasks[id]
+	if !ok {
+		return repositories.Todo{}, "", pgx.ErrNoRows
+	}
+
+	if strings.TrimSpace(boardName) == "" {
+		return repositories.Todo{}, "", todo.ErrInvalidBoard
+	}
+
+	previous := task.Board
+	task.Board = boardName
+	f.tasks[id] = task
+
+	data := map[string]any{"id": id, "board": task.Board, "previous_board": previous}
+
+	return task, "undo-" + id, f.feed.Publish(ctx, events.New(events.TaskUpdated, "", id, data))
+}
+
+func setup(t *testing.T) (server *httptest.Server, service *FakeTodoService) {
+
+	gin.SetMode(gin.TestMode)
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	service = &FakeTodoService{feed: events.NewBoardFeed(client), tasks: map[string]repositories.Todo{}}
+
+	handler := board.NewBoardHandler(board.NewBoardService(client), service)
+
+	app := gin.New()
+	app.GET("/boards/ws", func(c *gin.Context) {
+		c.Set(constants.USER_ID, c.Query("user"))
+	}, handler.Connect)
+
+	server = httptest.NewServer(app)
+	t.Cleanup(server.Close)
+
+	return
+}
+
+func dial(t *testing.T, server *httptest.Server, userId string) *websocket.Conn {
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/boards/ws?user=" + userId
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func read(t *testing.T, conn *websocket.Conn) board.ServerMessage {
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var msg board.ServerMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+
+	return msg
+}
+
+// readType skips messages until one of the given type arrives.
+func readType(t *testing.T, conn *websocket.Conn, msgType string) board.ServerMessage {
+
+	for {
+		msg := read(t, conn)
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestConnect_Presence(t *testing.T) {
+	server, _ := setup(t)
+
+	alice, bob := uuid.NewString(), uuid.NewString()
+
+	aliceConn := dial(t, server, alice)
+	require.NoError(t, aliceConn.WriteJSON(board.ClientMessage{Type: board.MessageSubscribe, Ref: "1", Board: "sprint"}))
+
+	msg := read(t, aliceConn)
+	assert.Equal(t, board.MessageSubscribed, msg.Type)
+	assert.Equal(t, "1", msg.Ref)
+	assert.Equal(t, []string{alice}, msg.Viewers)
+
+	bobConn := dial(t, server, bob)
+	require.NoError(t, bobConn.WriteJSON(board.ClientMessage{Type: board.MessageSubscribe, Board: "sprint"}))
+
+	msg = read(t, bobConn)
+	assert.ElementsMatch(t, []string{alice, bob}, msg.Viewers)
+
+	for {
+		msg = readType(t, aliceConn, board.MessagePresence)
+		if len(msg.Viewers) == 2 {
+			break
+		}
+	}
+
+	assert.Equal(t, "sprint", msg.Board)
+
+	bobConn.Close()
+
+	msg = readType(t, aliceConn, board.MessagePresence)
+	assert.Equal(t, []string{alice}, msg.Viewers)
+}
+
+func TestConnect_MoveNotifiesBothBoards(t *testing.T) {
+	server, service := setup(t)
+
+	id := uuid.NewString()
+	service.tasks[id] = repositories.Todo{ID: pgtype.UUID{Bytes: uuid.MustParse(id), Valid: true}, Board: "doing"}
+
+	watcher := dial(t, server, uuid.NewString())
+	for _, name := range []string{"doing", "done", "other"} {
+		require.NoError(t, watcher.WriteJSON(board.ClientMessage{Type: board.MessageSubscribe, Board: name}))
+		readType(t, watcher, board.MessageSubscribed)
+	}
+
+	mover := dial(t, server, uuid.NewString())
+	require.NoError(t, mover.WriteJSON(board.ClientMessage{Type: board.MessageMove, Ref: "m1", ID: id, Board: "done"}))
+
+	ack := readType(t, mover, board.MessageAck)
+	assert.Equal(t, "m1", ack.Ref)
+	assert.Equal(t, "undo-"+id, ack.UndoToken)
+
+	seen := map[string]bool{}
+	for len(seen) < 2 {
+		msg := readType(t, watcher, board.MessageEvent)
+		assert.Equal(t, events.TaskUpdated, msg.Event)
+		assert.Contains(t, string(msg.Data), `"previous_board":"doing"`)
+		seen[msg.Board] = true
+	}
+
+	assert.Equal(t, map[string]bool{"doing": true, "done": true}, seen)
+}
+
+func TestConnect_CommandErrors(t *testing.T) {
+	server, _ := setup(t)
+
+	conn := dial(t, server, uuid.NewString())
+
+	require.NoError(t, conn.WriteJSON(board.ClientMessage{Type: board.MessageMove, Ref: "a", ID: "nope", Board: "done"}))
+	msg := read(t, conn)
+	assert.Equal(t, board.ServerMessage{Type: board.MessageError, Ref: "a", Error: "invalid id"}, msg)
+
+	require.NoError(t, conn.WriteJSON(board.ClientMessage{Type: board.MessageMove, Ref: "b", ID: uuid.NewString(), Board: "done"}))
+	msg = read(t, conn)
+	assert.Equal(t, "Task not found", msg.Error)
+
+	require.NoError(t, conn.WriteJSON(board.ClientMessage{Type: board.MessageSubscribe, Ref: "c", Board: "  "}))
+	msg = read(t, conn)
+	assert.Equal(t, todo.ErrInvalidBoard.Error(), msg.Error)
+
+	require.NoError(t, conn.WriteJSON(board.ClientMessage{Type: "dance", Ref: "d"}))
+	msg = read(t, conn)
+	assert.Equal(t, "unknown message type", msg.Error)
+}
+
+func TestConnect_RequiresUser(t *testing.T) {
+	server, _ := setup(t)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/boards/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, 403, resp.StatusCode)
+}
+
+func TestConnect_TicketProtocol(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	tokens := utils.NewTokens("test-secret-that-is-long-enough-1234")
+	service := &FakeTodoService{feed: events.NewBoardFeed(client), tasks: map[string]repositories.Todo{}}
+	handler := board.NewBoardHandler(board.NewBoardService(client), service)
+
+	app := gin.New()
+	app.GET("/boards/ws", middlewares.NewGuard(tokens, nil).TicketAuth(), handler.Connect)
+
+	server := httptest.NewServer(app)
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/boards/ws"
+
+	ticket, err := tokens.SignTicket(uuid.NewString(), time.Minute)
+	require.NoError(t, err)
+
+	dialer := websocket.Dialer{Subprotocols: []string{board.Protocol, middlewares.TicketProtocol + ticket}}
+
+	conn, resp, err := dialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	assert.Equal(t, board.Protocol, resp.Header.Get("Sec-WebSocket-Protocol"))
+
+	require.NoError(t, conn.WriteJSON(board.ClientMessage{Type: board.MessageSubscribe, Ref: "a", Board: "sprint"}))
+	assert.Equal(t, board.MessageSubscribed, read(t, conn).Type)
+
+	// a token is not a ticket
+	token, err := tokens.Sign(uuid.NewString(), time.Minute)
+	require.NoError(t, err)
+
+	dialer = websocket.Dialer{Subprotocols: []string{board.Protocol, middlewares.TicketProtocol + token}}
+
+	_, resp, err = dialer.Dial(url, nil)
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, 401, resp.StatusCode)
+}
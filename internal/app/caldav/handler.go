@@ -0,0 +1,343 @@
+package caldav
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"ilcs/internal/app/todo"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RootPath is the principal and calendar home of every user.
+	RootPath = "/caldav/"
+	// CollectionPath is the calendar collection holding the tasks of a user.
+	CollectionPath = RootPath + "tasks/"
+	// Methods are the methods CalDAV resources support.
+	Methods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+)
+
+type ICaldavHandler interface {
+	Options(c *gin.Context)
+	Propfind(c *gin.Context)
+	Report(c *gin.Context)
+	GetObject(c *gin.Context)
+	PutObject(c *gin.Context)
+	DeleteObject(c *gin.Context)
+}
+
+type CaldavHandler struct {
+	service ICaldavService
+}
+
+func NewCaldavHandler(service ICaldavService) *CaldavHandler {
+	return &CaldavHandler{
+		service: service,
+	}
+}
+
+func errorStatus(err error) int {
+
+	switch {
+	case errors.Is(err, ErrNoUser), errors.Is(err, ErrUnsupportedComponent):
+		return 403
+	case errors.Is(err, ErrObjectNotFound):
+		return 404
+	case errors.Is(err, ErrPreconditionFailed):
+		return 412
+	case errors.Is(err, ErrInvalidObject), errors.Is(err, todo.ErrInvalidRecurrenceRule):
+		return 400
+	}
+
+	return 500
+}
+
+// Options advertises CalDAV, clients check for calendar-access before they
+// look any further.
+func (h *CaldavHandler) Options(c *gin.Context) {
+
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", Methods)
+	c.Status(200)
+}
+
+// kind is what a path of the server is.
+type kind int
+
+const (
+	kindRoot kind = iota
+	kindCollection
+	kindObject
+)
+
+// target is a resource properties are asked of.
+type target struct {
+	href   string
+	kind   kind
+	object Object
+	// ctag changes whenever an object of the collection does.
+	ctag string
+}
+
+func (h *CaldavHandler) Propfind(c *gin.Context) {
+
+	var req propfind
+	if err := decodeXML(c.Request.Body, &req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var names []xml.Name
+	if req.Prop != nil && req.AllProp == nil {
+		names = req.Prop.Names
+	}
+
+	depth := c.GetHeader("Depth")
+
+	var targets []target
+
+	switch c.FullPath() {
+	case RootPath:
+		targets = append(targets, target{href: RootPath, kind: kindRoot})
+		if depth == "0" {
+			break
+		}
+
+		collection, _, err := h.collection(c, false)
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		targets = append(targets, collection)
+	case CollectionPath:
+		collection, objects, err := h.collection(c, depth != "0")
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		targets = append(targets, collection)
+		targets = append(targets, objects...)
+	default:
+		object, err := h.service.Get(c, c.Param("name"))
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		targets = append(targets, objectTarget(object))
+	}
+
+	responses := make([]response, 0, len(targets))
+	for _, t := range targets {
+		responses = append(responses, propResponse(t, names))
+	}
+
+	writeMultistatus(c, responses)
+}
+
+// collection lists the tasks, the ctag of the collection is made from their
+// etags so it cannot be had without them.
+func (h *CaldavHandler) collection(c *gin.Context, withObjects bool) (collection target, objects []target, err error) {
+
+	list, err := h.service.List(c)
+	if err != nil {
+		return
+	}
+
+	hash := sha256.New()
+	for _, object := range list {
+		io.WriteString(hash, object.Name+object.ETag)
+		if withObjects {
+			objects = append(objects, objectTarget(object))
+		}
+	}
+
+	collection = target{
+		href: CollectionPath,
+		kind: kindCollection,
+		ctag: `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`,
+	}
+
+	return
+}
+
+func objectTarget(object Object) target {
+	return target{
+		href:   CollectionPath + url.PathEscape(object.Name),
+		kind:   kindObject,
+		object: object,
+	}
+}
+
+// Report answers calendar-query and calendar-multiget, the reports clients
+// sync with. Queries only filter on the component, every task is a VTODO.
+func (h *CaldavHandler) Report(c *gin.Context) {
+
+	var req report
+	if err := decodeXML(c.Request.Body, &req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var names []xml.Name
+	if req.Prop != nil {
+		names = req.Prop.Names
+	}
+
+	var responses []response
+
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		if req.Filter != nil && !matchesTodo(req.Filter.Comp) {
+			break
+		}
+
+		objects, err := h.service.List(c)
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		for _, object := range objects {
+			responses = append(responses, propResponse(objectTarget(object), names))
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range req.Hrefs {
+			name, err := url.PathUnescape(path.Base(strings.TrimSpace(href)))
+			if err != nil {
+				responses = append(responses, response{Href: href, Status: statusLine(404)})
+				continue
+			}
+
+			object, err := h.service.Get(c, name)
+			if err != nil {
+				responses = append(responses, response{Href: href, Status: statusLine(errorStatus(err))})
+				continue
+			}
+
+			responses = append(responses, propResponse(objectTarget(object), names))
+		}
+	default:
+		c.Data(403, "application/xml; charset=utf-8", []byte(xml.Header+
+			`<D:error xmlns:D="DAV:"><D:supported-report/></D:error>`))
+		return
+	}
+
+	writeMultistatus(c, responses)
+}
+
+// matchesTodo tells whether a VTODO passes a comp-filter of a calendar-query.
+func matchesTodo(filter compFilter) bool {
+
+	if filter.Name != "VCALENDAR" {
+		return false
+	}
+
+	for _, comp := range filter.Comps {
+		if comp.Name != "VTODO" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetObject serves GET and HEAD of an object.
+func (h *CaldavHandler) GetObject(c *gin.Context) {
+
+	object, err := h.service.Get(c, c.Param("name"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("If-None-Match") == object.ETag {
+		c.Header("ETag", object.ETag)
+		c.Status(304)
+		return
+	}
+
+	c.Header("ETag", object.ETag)
+	c.Data(200, "text/calendar; charset=utf-8", object.Data)
+}
+
+// PutObject creates or replaces an object. No ETag is returned, the stored
+// object differs from the one sent, so clients fetch it again.
+func (h *CaldavHandler) PutObject(c *gin.Context) {
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxObjectBytes))
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(413, gin.H{"error": fmt.Sprintf("calendar object is larger than %d bytes", MaxObjectBytes)})
+		return
+	}
+
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.service.Put(c, c.Param("name"), bytes.NewReader(body), c.GetHeader("If-Match"), c.GetHeader("If-None-Match"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if created {
+		c.Status(201)
+		return
+	}
+
+	c.Status(204)
+}
+
+func (h *CaldavHandler) DeleteObject(c *gin.Context) {
+
+	err := h.service.Delete(c, c.Param("name"), c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(204)
+}
+
+// decodeXML reads a request body, an empty one leaves v as it is.
+func decodeXML(body io.Reader, v any) error {
+
+	err := xml.NewDecoder(io.LimitReader(body, MaxObjectBytes)).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
+func writeMultistatus(c *gin.Context, responses []response) {
+
+	data, err := xml.Marshal(multistatus{
+		XmlnsD:    nsDAV,
+		XmlnsC:    nsCalDAV,
+		XmlnsCS:   nsCalendarServ,
+		Responses: responses,
+	})
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(207, "application/xml; charset=utf-8", append([]byte(xml.Header), data...))
+}
@@ -0,0 +1,95 @@
+package caldav
+
+import "encoding/xml"
+
+const (
+	nsDAV          = "DAV:"
+	nsCalDAV       = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServ = "http://calendarserver.org/ns/"
+)
+
+// Object is a task as a calendar object resource.
+type Object struct {
+	// Name is the last segment of the path of the resource.
+	Name string
+	UID  string
+	ETag string
+	Data []byte
+}
+
+// propfind is the body of a PROPFIND request, an empty body asks for all
+// properties.
+type propfind struct {
+	XMLName xml.Name   `xml:"DAV: propfind"`
+	AllProp *struct{}  `xml:"DAV: allprop"`
+	Prop    *propNames `xml:"DAV: prop"`
+}
+
+// report is the body of the REPORT requests calendar collections support,
+// calendar-query and calendar-multiget.
+type report struct {
+	XMLName xml.Name
+	Prop    *propNames `xml:"DAV: prop"`
+	Hrefs   []string   `xml:"DAV: href"`
+	Filter  *struct {
+		Comp compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type compFilter struct {
+	Name  string       `xml:"name,attr"`
+	Comps []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// propNames are the names of the properties asked for, their content is
+// ignored.
+type propNames struct {
+	Names []xml.Name
+}
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			p.Names = append(p.Names, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type multistatus struct {
+	XMLName   xml.Name   `xml:"D:multistatus"`
+	XmlnsD    string     `xml:"xmlns:D,attr"`
+	XmlnsC    string     `xml:"xmlns:C,attr"`
+	XmlnsCS   string     `xml:"xmlns:CS,attr"`
+	Responses []response `xml:"D:response"`
+}
+
+// response is a resource of a multistatus, with either its properties or the
+// status of a resource that could not be read.
+type response struct {
+	Href      string     `xml:"D:href"`
+	Propstats []propstat `xml:"D:propstat"`
+	Status    string     `xml:"D:status,omitempty"`
+}
+
+type propstat struct {
+	Props  []prop `xml:"D:prop>prop"`
+	Status string `xml:"D:status"`
+}
+
+// prop is a property with its value as XML.
+type prop struct {
+	XMLName xml.Name
+	Value   string `xml:",innerxml"`
+}
@@ -0,0 +1,157 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// prefixes are the prefixes multistatus declares for the namespaces of the
+// properties the server knows.
+var prefixes = map[string]string{
+	nsDAV:          "D:",
+	nsCalDAV:       "C:",
+	nsCalendarServ: "CS:",
+}
+
+// allProps are the properties allprop returns, calendar-data has to be asked
+// for by name.
+var allProps = []xml.Name{
+	{Space: nsDAV, Local: "resourcetype"},
+	{Space: nsDAV, Local: "displayname"},
+	{Space: nsDAV, Local: "current-user-principal"},
+	{Space: nsDAV, Local: "principal-URL"},
+	{Space: nsDAV, Local: "current-user-privilege-set"},
+	{Space: nsDAV, Local: "supported-report-set"},
+	{Space: nsDAV, Local: "getetag"},
+	{Space: nsDAV, Local: "getcontenttype"},
+	{Space: nsCalDAV, Local: "calendar-home-set"},
+	{Space: nsCalDAV, Local: "supported-calendar-component-set"},
+	{Space: nsCalendarServ, Local: "getctag"},
+}
+
+func statusLine(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+func escape(text string) string {
+
+	var b strings.Builder
+	// writing to a builder does not fail
+	_ = xml.EscapeText(&b, []byte(text))
+
+	return b.String()
+}
+
+func href(path string) string {
+	return "<D:href>" + escape(path) + "</D:href>"
+}
+
+// propValue is the value of a property of t as XML, false when t does not
+// have it.
+func propValue(t target, name xml.Name) (string, bool) {
+
+	switch name {
+	case xml.Name{Space: nsDAV, Local: "resourcetype"}:
+		switch t.kind {
+		case kindRoot:
+			return "<D:collection/>", true
+		case kindCollection:
+			return "<D:collection/><C:calendar/>", true
+		}
+		return "", true
+	case xml.Name{Space: nsDAV, Local: "displayname"}:
+		switch t.kind {
+		case kindRoot:
+			return "ilcs", true
+		case kindCollection:
+			return "Tasks", true
+		}
+	case xml.Name{Space: nsDAV, Local: "current-user-principal"},
+		xml.Name{Space: nsDAV, Local: "principal-URL"},
+		xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}:
+		if t.kind != kindObject {
+			return href(RootPath), true
+		}
+	case xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}:
+		return "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>", true
+	case xml.Name{Space: nsDAV, Local: "supported-report-set"}:
+		if t.kind == kindCollection {
+			return "<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>" +
+				"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>", true
+		}
+	case xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}:
+		if t.kind == kindCollection {
+			return `<C:comp name="VTODO"/>`, true
+		}
+	case xml.Name{Space: nsDAV, Local: "getetag"}:
+		switch t.kind {
+		case kindCollection:
+			return escape(t.ctag), true
+		case kindObject:
+			return escape(t.object.ETag), true
+		}
+	case xml.Name{Space: nsCalendarServ, Local: "getctag"}:
+		if t.kind == kindCollection {
+			return escape(t.ctag), true
+		}
+	case xml.Name{Space: nsDAV, Local: "getcontenttype"}:
+		if t.kind == kindObject {
+			return "text/calendar; charset=utf-8; component=VTODO", true
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-data"}:
+		if t.kind == kindObject {
+			return escape(string(t.object.Data)), true
+		}
+	}
+
+	return "", false
+}
+
+// propResponse answers a PROPFIND or REPORT for t. Properties t does not have
+// are listed with a 404 status, no names means every property it has.
+func propResponse(t target, names []xml.Name) response {
+
+	all := names == nil
+	if all {
+		names = allProps
+	}
+
+	var found, missing []prop
+
+	for _, name := range names {
+		value, ok := propValue(t, name)
+		if !ok {
+			if !all {
+				missing = append(missing, prop{XMLName: propName(name)})
+			}
+			continue
+		}
+
+		found = append(found, prop{XMLName: propName(name), Value: value})
+	}
+
+	res := response{Href: t.href}
+
+	if len(found) > 0 {
+		res.Propstats = append(res.Propstats, propstat{Props: found, Status: statusLine(200)})
+	}
+
+	if len(missing) > 0 {
+		res.Propstats = append(res.Propstats, propstat{Props: missing, Status: statusLine(404)})
+	}
+
+	return res
+}
+
+// propName is the name a property is written with, properties of namespaces
+// the server does not know declare theirs.
+func propName(name xml.Name) xml.Name {
+
+	if prefix, ok := prefixes[name.Space]; ok {
+		return xml.Name{Local: prefix + name.Local}
+	}
+
+	return name
+}
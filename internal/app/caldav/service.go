@@ -0,0 +1,443 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/ical"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MaxObjectBytes caps the size of the objects clients PUT.
+	MaxObjectBytes = 1 << 20
+	// listPageSize is how many tasks are read at a time to list the collection.
+	listPageSize = 500
+	prodID       = "-//ilcs//CalDAV//EN"
+)
+
+var (
+	ErrNoUser               = errors.New("token is not bound to a user")
+	ErrObjectNotFound       = errors.New("calendar object not found")
+	ErrPreconditionFailed   = errors.New("calendar object has changed")
+	ErrInvalidObject        = errors.New("invalid calendar object")
+	ErrUnsupportedComponent = errors.New("only VTODO components are supported")
+)
+
+type ICaldavService interface {
+	List(ctx context.Context) (objects []Object, err error)
+	Get(ctx context.Context, name string) (object Object, err error)
+	Put(ctx context.Context, name string, body io.Reader, ifMatch, ifNoneMatch string) (created bool, err error)
+	Delete(ctx context.Context, name, ifMatch string) (err error)
+}
+
+// CaldavService maps calendar object resources onto tasks. Changes go
+// through the task service, so tasks changed by CalDAV clients are audited,
+// recur and notify like any other.
+type CaldavService struct {
+	repo  repositories.Querier
+	todos todo.ITodoService
+	tx    repositories.Transactor
+}
+
+func NewCaldavService(repo repositories.Querier, todos todo.ITodoService, tx repositories.Transactor) *CaldavService {
+	return &CaldavService{
+		repo:  repo,
+		todos: todos,
+		tx:    tx,
+	}
+}
+
+func currentUser(ctx context.Context) (pgtype.UUID, error) {
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		return pgtype.UUID{}, ErrNoUser
+	}
+
+	return pgtype.UUID{Bytes: userId, Valid: true}, nil
+}
+
+// resource is where a task is found. Tasks created by CalDAV clients keep the
+// name and UID the client chose, the others are named after their id.
+type resource struct {
+	name string
+	uid  string
+}
+
+func defaultResource(id string) resource {
+	return resource{name: id + ".ics", uid: id}
+}
+
+// List returns every task of the user with its data, clients compare the
+// etags to find out what changed.
+func (s *CaldavService) List(ctx context.Context) (objects []Object, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	objects = []Object{}
+	after := pgtype.UUID{Valid: true}
+
+	for {
+		rows, err := s.repo.ListCaldavTodos(ctx, repositories.ListCaldavTodosParams{
+			UserID:   userId,
+			AfterID:  after,
+			LimitVal: listPageSize,
+		})
+
+		if err != nil {
+			log.Error().Err(err).Send()
+			return nil, err
+		}
+
+		for _, row := range rows {
+			res := defaultResource(row.ID.String())
+			if row.Name.Valid {
+				res = resource{name: row.Name.String, uid: row.Uid.String}
+			}
+
+			objects = append(objects, render(res, repositories.Todo{
+				ID:              row.ID,
+				Title:           row.Title,
+				Description:     row.Description,
+				Status:          row.Status,
+				DueDate:         row.DueDate,
+				CreatedAt:       row.CreatedAt,
+				UpdatedAt:       row.UpdatedAt,
+				RecurrenceRule:  row.RecurrenceRule,
+				RecurrenceStart: row.RecurrenceStart,
+				SeriesID:        row.SeriesID,
+				DueAt:           row.DueAt,
+				UserID:          row.UserID,
+				CompletedAt:     row.CompletedAt,
+				Board:           row.Board,
+				DeletedAt:       row.DeletedAt,
+			}))
+		}
+
+		if len(rows) < listPageSize {
+			break
+		}
+
+		after = rows[len(rows)-1].ID
+	}
+
+	return
+}
+
+// find returns the task of a resource name, only tasks of the user are found.
+func (s *CaldavService) find(ctx context.Context, name string) (task repositories.Todo, res resource, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		return
+	}
+
+	mapped, err := s.repo.GetCaldavObjectByName(ctx, repositories.GetCaldavObjectByNameParams{
+		UserID: userId,
+		Name:   name,
+	})
+
+	var id pgtype.UUID
+
+	switch {
+	case err == nil:
+		id, res = mapped.TodoID, resource{name: mapped.Name, uid: mapped.Uid}
+	case errors.Is(err, pgx.ErrNoRows):
+		base, ok := strings.CutSuffix(name, ".ics")
+		parsed, parseErr := uuid.Parse(base)
+		if !ok || parseErr != nil {
+			return task, res, ErrObjectNotFound
+		}
+
+		id, res = pgtype.UUID{Bytes: parsed, Valid: true}, defaultResource(base)
+	default:
+		return
+	}
+
+	task, err = s.repo.GetCaldavTodo(ctx, repositories.GetCaldavTodoParams{
+		ID:     id,
+		UserID: userId,
+	})
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrObjectNotFound
+	}
+
+	return
+}
+
+func (s *CaldavService) Get(ctx context.Context, name string) (object Object, err error) {
+
+	task, res, err := s.find(ctx, name)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return render(res, task), nil
+}
+
+// Put creates or replaces the task of a resource. ifMatch and ifNoneMatch are
+// the conditional headers of the request, clients use them so they do not
+// overwrite changes they have not seen.
+func (s *CaldavService) Put(ctx context.Context, name string, body io.Reader, ifMatch, ifNoneMatch string) (created bool, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	task, uid, err := decodeTask(body)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	before, res, err := s.find(ctx, name)
+	if errors.Is(err, ErrObjectNotFound) {
+		if ifMatch != "" {
+			return false, ErrPreconditionFailed
+		}
+
+		return true, s.create(ctx, userId, name, uid, task)
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	if ifNoneMatch == "*" || (ifMatch != "" && ifMatch != "*" && ifMatch != render(res, before).ETag) {
+		return false, ErrPreconditionFailed
+	}
+
+	id := before.ID.String()
+
+	_, _, err = s.todos.UpdateTodo(ctx, todo.UpdateTodoRequest{
+		Title:          task.Title,
+		Description:    task.Description,
+		Status:         task.Status,
+		DueDate:        task.DueDate,
+		DueAt:          task.DueAt,
+		RecurrenceRule: &task.RecurrenceRule,
+	}, id)
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	// an update keeps the rule of a task without one, a client that dropped
+	// the RRULE ended the series
+	if before.RecurrenceRule.Valid && task.RecurrenceRule == "" {
+		if _, err = s.todos.EndSeries(ctx, id); err != nil {
+			log.Error().Err(err).Send()
+			return
+		}
+	}
+
+	return
+}
+
+// create writes the task of a new resource and the name and UID the client
+// gave it in one transaction, so a PUT that fails leaves nothing behind and the
+// client can retry it.
+func (s *CaldavService) create(ctx context.Context, userId pgtype.UUID, name, uid string, task todo.Todo) (err error) {
+
+	var data repositories.Todo
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		data, err = s.todos.CreateTodoIn(ctx, q, todo.CreateTodoRequest{
+			Title:          task.Title,
+			Description:    task.Description,
+			DueDate:        task.DueDate,
+			DueAt:          task.DueAt,
+			RecurrenceRule: task.RecurrenceRule,
+		}, task.Status == "completed")
+
+		if err != nil {
+			return
+		}
+
+		if res := defaultResource(data.ID.String()); res.name == name && res.uid == uid {
+			return
+		}
+
+		return q.InsertCaldavObject(ctx, repositories.InsertCaldavObjectParams{
+			TodoID: data.ID,
+			UserID: userId,
+			Name:   name,
+			Uid:    uid,
+		})
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+func (s *CaldavService) Delete(ctx context.Context, name, ifMatch string) (err error) {
+
+	task, res, err := s.find(ctx, name)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	if ifMatch != "" && ifMatch != "*" && ifMatch != render(res, task).ETag {
+		return ErrPreconditionFailed
+	}
+
+	if _, err = s.todos.DeleteTodo(ctx, task.ID.String()); err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// render writes a task as a VCALENDAR holding one VTODO.
+func render(res resource, data repositories.Todo) Object {
+
+	task := ical.Component{Name: "VTODO"}
+	task.AddText("UID", res.uid)
+
+	stamp := time.Now()
+	if data.UpdatedAt.Valid {
+		stamp = data.UpdatedAt.Time
+		task.Add("LAST-MODIFIED", ical.FormatDateTime(stamp))
+	}
+
+	task.Add("DTSTAMP", ical.FormatDateTime(stamp))
+	task.AddText("SUMMARY", data.Title)
+
+	if data.Description.String != "" {
+		task.AddText("DESCRIPTION", data.Description.String)
+	}
+
+	if data.DueAt.Valid {
+		task.Add("DUE", ical.FormatDateTime(data.DueAt.Time))
+	} else if data.DueDate.Valid {
+		task.Add("DUE", ical.FormatDate(data.DueDate.Time), "VALUE", "DATE")
+	}
+
+	if data.Status == repositories.TodoStatusCompleted {
+		task.Add("STATUS", "COMPLETED")
+
+		if data.CompletedAt.Valid {
+			task.Add("COMPLETED", ical.FormatDateTime(data.CompletedAt.Time))
+		}
+	} else {
+		task.Add("STATUS", "NEEDS-ACTION")
+	}
+
+	if data.RecurrenceRule.String != "" {
+		task.Add("RRULE", data.RecurrenceRule.String)
+	}
+
+	calendar := ical.Component{Name: "VCALENDAR"}
+	calendar.Add("VERSION", "2.0")
+	calendar.Add("PRODID", prodID)
+	calendar.Components = append(calendar.Components, task)
+
+	var buf bytes.Buffer
+	// writing to a buffer does not fail
+	_ = ical.Encode(&buf, calendar)
+
+	sum := sha256.Sum256(buf.Bytes())
+
+	return Object{
+		Name: res.name,
+		UID:  res.uid,
+		ETag: `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Data: buf.Bytes(),
+	}
+}
+
+// decodeTask reads the task of a calendar object, the fields a task has no
+// place for are dropped.
+func decodeTask(body io.Reader) (task todo.Todo, uid string, err error) {
+
+	calendar, err := ical.Decode(io.LimitReader(body, MaxObjectBytes))
+	if err != nil || calendar.Name != "VCALENDAR" {
+		return task, "", ErrInvalidObject
+	}
+
+	var found []ical.Component
+	for _, child := range calendar.Components {
+		switch child.Name {
+		case "VTODO":
+			found = append(found, child)
+		case "VEVENT", "VJOURNAL":
+			return task, "", ErrUnsupportedComponent
+		}
+	}
+
+	if len(found) != 1 {
+		return task, "", ErrInvalidObject
+	}
+
+	component := found[0]
+
+	uid = component.Text("UID")
+	if uid == "" {
+		return task, "", ErrInvalidObject
+	}
+
+	task.Title = component.Text("SUMMARY")
+	if task.Title == "" {
+		task.Title = "Untitled"
+	}
+
+	task.Description = component.Text("DESCRIPTION")
+
+	due, date, err := component.Time("DUE", time.UTC)
+	if errors.Is(err, ical.ErrNotFound) {
+		due, date, err = component.Time("DTSTART", time.UTC)
+	}
+
+	switch {
+	case errors.Is(err, ical.ErrNotFound):
+		// tasks always have a due day, one without is due today
+		task.DueDate = time.Now().Format("2006-01-02")
+	case err != nil:
+		return task, "", ErrInvalidObject
+	case date:
+		task.DueDate = due.Format("2006-01-02")
+	default:
+		task.DueAt = due.Format(time.RFC3339)
+	}
+	err = nil
+
+	task.Status = "pending"
+	if _, completed := component.Prop("COMPLETED"); completed || strings.EqualFold(component.Text("STATUS"), "COMPLETED") {
+		task.Status = "completed"
+	}
+
+	if rule, ok := component.Prop("RRULE"); ok {
+		task.RecurrenceRule = rule.Value
+	}
+
+	return
+}
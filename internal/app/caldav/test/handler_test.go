@@ -0,0 +1,95 @@
+package caldav
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ilcs/internal/app/caldav"
+	"ilcs/internal/constants"
+	"ilcs/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func server(t *testing.T, title string) (srv *httptest.Server, id string) {
+
+	gin.SetMode(gin.TestMode)
+
+	repo := &MockRepo{tasks: map[string]repositories.Todo{}}
+	handler := caldav.NewCaldavHandler(caldav.NewCaldavService(repo, &FakeTodoService{repo: repo}, FakeTransactor{repo}))
+
+	ctx := withUser()
+	id = task(ctx, repo, title, "")
+
+	auth := func(c *gin.Context) {
+		c.Set(constants.USER_ID, ctx.Value(constants.USER_ID))
+	}
+
+	app := gin.New()
+	app.Handle("PROPFIND", caldav.CollectionPath, auth, handler.Propfind)
+	app.Handle("REPORT", caldav.CollectionPath, auth, handler.Report)
+
+	srv = httptest.NewServer(app)
+	t.Cleanup(srv.Close)
+
+	return
+}
+
+func send(t *testing.T, method, url, depth, body string) (int, string) {
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Depth", depth)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res.StatusCode, string(data)
+}
+
+func TestPropfind_ListsTasksOfTheCollection(t *testing.T) {
+	srv, id := server(t, "Task")
+
+	body := `<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/" xmlns:x="urn:example">
+  <d:prop><d:resourcetype/><d:getetag/><cs:getctag/><x:color/></d:prop>
+</d:propfind>`
+
+	status, data := send(t, "PROPFIND", srv.URL+caldav.CollectionPath, "1", body)
+	assert.Equal(t, 207, status)
+
+	assert.Contains(t, data, "<D:href>/caldav/tasks/</D:href>")
+	assert.Contains(t, data, "<D:resourcetype><D:collection/><C:calendar/></D:resourcetype>")
+	assert.Contains(t, data, "<CS:getctag>")
+	assert.Contains(t, data, "<D:href>/caldav/tasks/"+id+".ics</D:href>")
+	assert.Contains(t, data, `<color xmlns="urn:example"></color>`)
+	assert.Contains(t, data, "HTTP/1.1 404 Not Found")
+}
+
+func TestReport_MultigetReturnsCalendarData(t *testing.T) {
+	srv, id := server(t, "Pay rent")
+
+	body := `<?xml version="1.0"?>
+<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <d:href>/caldav/tasks/` + id + `.ics</d:href>
+  <d:href>/caldav/tasks/missing.ics</d:href>
+</c:calendar-multiget>`
+
+	status, data := send(t, "REPORT", srv.URL+caldav.CollectionPath, "1", body)
+	assert.Equal(t, 207, status)
+	assert.Contains(t, data, "SUMMARY:Pay rent")
+	assert.Contains(t, data, "<D:href>/caldav/tasks/missing.ics</D:href><D:status>HTTP/1.1 404 Not Found</D:status>")
+
+	status, _ = send(t, "REPORT", srv.URL+caldav.CollectionPath, "1", `<d:sync-collection xmlns:d="DAV:"/>`)
+	assert.Equal(t, 403, status)
+}
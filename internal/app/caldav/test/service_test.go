@@ -0,0 +1,354 @@
+package caldav
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/caldav"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/constants"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepo keeps tasks and caldav objects in memory.
+type MockRepo struct {
+	repositories.Querier
+	tasks      map[string]repositories.Todo
+	objects    []repositories.CaldavObject
+	failInsert bool
+}
+
+func (m *MockRepo) GetCaldavTodo(ctx context.Context, arg repositories.GetCaldavTodoParams) (repositories.Todo, error) {
+
+	task, ok := m.tasks[arg.ID.String()]
+	if !ok || task.UserID != arg.UserID {
+		return repositories.Todo{}, pgx.ErrNoRows
+	}
+
+	return task, nil
+}
+
+func (m *MockRepo) ListCaldavTodos(ctx context.Context, arg repositories.ListCaldavTodosParams) ([]repositories.ListCaldavTodosRow, error) {
+
+	var items []repositories.ListCaldavTodosRow
+	for _, task := range m.tasks {
+		if task.UserID != arg.UserID || task.ID.String() <= arg.AfterID.String() {
+			continue
+		}
+
+		row := repositories.ListCaldavTodosRow{
+			ID:             task.ID,
+			Title:          task.Title,
+			Description:    task.Description,
+			Status:         task.Status,
+			DueDate:        task.DueDate,
+			UpdatedAt:      task.UpdatedAt,
+			RecurrenceRule: task.RecurrenceRule,
+			DueAt:          task.DueAt,
+			UserID:         task.UserID,
+			CompletedAt:    task.CompletedAt,
+		}
+
+		for _, item := range m.objects {
+			if item.TodoID == task.ID {
+				row.Name = pgtype.Text{String: item.Name, Valid: true}
+				row.Uid = pgtype.Text{String: item.Uid, Valid: true}
+			}
+		}
+
+		items = append(items, row)
+	}
+
+	return items, nil
+}
+
+func (m *MockRepo) GetCaldavObjectByName(ctx context.Context, arg repositories.GetCaldavObjectByNameParams) (repositories.CaldavObject, error) {
+
+	for _, item := range m.objects {
+		if item.UserID == arg.UserID && item.Name == arg.Name {
+			return item, nil
+		}
+	}
+
+	return repositories.CaldavObject{}, pgx.ErrNoRows
+}
+
+func (m *MockRepo) InsertCaldavObject(ctx context.Context, arg repositories.InsertCaldavObjectParams) error {
+
+	if m.failInsert {
+		return errors.New("insert failed")
+	}
+
+	m.objects = append(m.objects, repositories.CaldavObject{TodoID: arg.TodoID, UserID: arg.UserID, Name: arg.Name, Uid: arg.Uid})
+	return nil
+}
+
+// FakeTransactor runs the unit of work against the mock and rolls it back
+// when it fails.
+type FakeTransactor struct {
+	repo *MockRepo
+}
+
+func (f FakeTransactor) InTx(ctx context.Context, fn func(q repositories.Querier) error) error {
+
+	tasks := maps.Clone(f.repo.tasks)
+	objects := slices.Clone(f.repo.objects)
+
+	err := fn(f.repo)
+	if err != nil {
+		f.repo.tasks, f.repo.objects = tasks, objects
+	}
+
+	return err
+}
+
+// FakeTodoService changes the tasks of the mock.
+type FakeTodoService struct {
+	todo.ITodoService
+	repo  *MockRepo
+	ended []string
+}
+
+func userOf(ctx context.Context) pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.MustParse(ctx.Value(constants.USER_ID).(string)), Valid: true}
+}
+
+func (f *FakeTodoService) CreateTodoIn(ctx context.Context, q repositories.Querier, req todo.CreateTodoRequest, completed bool) (repositories.Todo, error) {
+
+	task := repositories.Todo{
+		ID:             pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Title:          req.Title,
+		Description:    pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Status:         repositories.TodoStatusPending,
+		RecurrenceRule: pgtype.Text{String: req.RecurrenceRule, Valid: req.RecurrenceRule != ""},
+		UpdatedAt:      pgtype.Timestamptz{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		UserID:         userOf(ctx),
+	}
+
+	if due, err := time.Parse("2006-01-02", req.DueDate); err == nil {
+		task.DueDate = pgtype.Date{Time: due, Valid: true}
+	}
+
+	if completed {
+		task.Status = repositories.TodoStatusCompleted
+		task.CompletedAt = pgtype.Timestamptz{Time: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+
+	f.repo.tasks[task.ID.String()] = task
+
+	return task, nil
+}
+
+func (f *FakeTodoService) UpdateTodo(ctx context.Context, req todo.UpdateTodoRequest, id string) (repositories.Todo, string, error) {
+
+	task, ok := f.repo.tasks[id]
+	if !ok || task.UserID != userOf(ctx) {
+		return repositories.Todo{}, "", pgx.ErrNoRows
+	}
+
+	task.Title = req.Title
+	task.Description = pgtype.Text{String: req.Description, Valid: req.Description != ""}
+	task.Status = repositories.TodoStatus(req.Status)
+	if due, err := time.Parse(time.RFC3339, req.DueAt); err == nil {
+		task.DueAt = pgtype.Timestamptz{Time: due, Valid: true}
+	}
+	if req.RecurrenceRule != nil && *req.RecurrenceRule != "" {
+		task.RecurrenceRule = pgtype.Text{String: *req.RecurrenceRule, Valid: true}
+	}
+	task.UpdatedAt = pgtype.Timestamptz{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}
+	f.repo.tasks[id] = task
+
+	return task, "", nil
+}
+
+func (f *FakeTodoService) EndSeries(ctx context.Context, id string) (string, error) {
+	f.ended = append(f.ended, id)
+	return "", nil
+}
+
+func (f *FakeTodoService) DeleteTodo(ctx context.Context, id string) (string, error) {
+
+	if _, ok := f.repo.tasks[id]; !ok {
+		return "", pgx.ErrNoRows
+	}
+
+	delete(f.repo.tasks, id)
+
+	return "", nil
+}
+
+// task stores a pending task of the user of ctx.
+func task(ctx context.Context, repo *MockRepo, title, rule string) string {
+
+	id := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	repo.tasks[id.String()] = repositories.Todo{
+		ID:             id,
+		Title:          title,
+		Status:         repositories.TodoStatusPending,
+		DueDate:        pgtype.Date{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		RecurrenceRule: pgtype.Text{String: rule, Valid: rule != ""},
+		UserID:         userOf(ctx),
+	}
+
+	return id.String()
+}
+
+func withUser() context.Context {
+	return context.WithValue(context.Background(), constants.USER_ID, uuid.New().String())
+}
+
+func setup() (service *caldav.CaldavService, repo *MockRepo, todos *FakeTodoService, ctx context.Context) {
+
+	repo = &MockRepo{tasks: map[string]repositories.Todo{}}
+	todos = &FakeTodoService{repo: repo}
+	service = caldav.NewCaldavService(repo, todos, FakeTransactor{repo})
+	ctx = withUser()
+
+	return
+}
+
+func vtodo(props ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\n" + strings.Join(props, "\r\n") + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+}
+
+func TestPut_CreatesTaskUnderTheClientName(t *testing.T) {
+	service, repo, _, ctx := setup()
+
+	body := vtodo("UID:abc@example.com", "SUMMARY:Buy milk", "DUE;VALUE=DATE:20250301", "STATUS:COMPLETED")
+
+	created, err := service.Put(ctx, "abc.ics", strings.NewReader(body), "", "*")
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	require.Len(t, repo.tasks, 1)
+	for _, task := range repo.tasks {
+		assert.Equal(t, "Buy milk", task.Title)
+		assert.Equal(t, "2025-03-01", task.DueDate.Time.Format("2006-01-02"))
+		assert.Equal(t, repositories.TodoStatusCompleted, task.Status)
+	}
+
+	object, err := service.Get(ctx, "abc.ics")
+	require.NoError(t, err)
+	assert.Equal(t, "abc@example.com", object.UID)
+	assert.Contains(t, string(object.Data), "UID:abc@example.com\r\n")
+	assert.Contains(t, string(object.Data), "STATUS:COMPLETED\r\n")
+	assert.Contains(t, string(object.Data), "COMPLETED:20250103T000000Z\r\n")
+
+	objects, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "abc.ics", objects[0].Name)
+	assert.Equal(t, object.ETag, objects[0].ETag)
+}
+
+func TestPut_RejectsStaleETag(t *testing.T) {
+	service, repo, todos, ctx := setup()
+
+	id := task(ctx, repo, "Old", "FREQ=WEEKLY")
+
+	object, err := service.Get(ctx, id+".ics")
+	require.NoError(t, err)
+
+	body := vtodo("UID:"+id, "SUMMARY:New", "DUE:20250301T090000Z")
+
+	_, err = service.Put(ctx, id+".ics", strings.NewReader(body), `"stale"`, "")
+	assert.ErrorIs(t, err, caldav.ErrPreconditionFailed)
+
+	created, err := service.Put(ctx, id+".ics", strings.NewReader(body), object.ETag, "")
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "New", repo.tasks[id].Title)
+	assert.Equal(t, time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), repo.tasks[id].DueAt.Time)
+	// the client dropped the RRULE
+	assert.Equal(t, []string{id}, todos.ended)
+
+	updated, err := service.Get(ctx, id+".ics")
+	require.NoError(t, err)
+	assert.NotEqual(t, object.ETag, updated.ETag)
+}
+
+func TestPut_RejectsEvents(t *testing.T) {
+	service, _, _, ctx := setup()
+
+	body := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	_, err := service.Put(ctx, "event.ics", strings.NewReader(body), "", "")
+	assert.ErrorIs(t, err, caldav.ErrUnsupportedComponent)
+
+	_, err = service.Put(ctx, "broken.ics", strings.NewReader("BEGIN:VTODO\r\n"), "", "")
+	assert.ErrorIs(t, err, caldav.ErrInvalidObject)
+}
+
+func TestDelete(t *testing.T) {
+	service, repo, _, ctx := setup()
+
+	id := task(ctx, repo, "Task", "")
+
+	assert.ErrorIs(t, service.Delete(ctx, id+".ics", `"stale"`), caldav.ErrPreconditionFailed)
+	require.NoError(t, service.Delete(ctx, id+".ics", ""))
+	assert.Empty(t, repo.tasks)
+
+	assert.ErrorIs(t, service.Delete(ctx, id+".ics", ""), caldav.ErrObjectNotFound)
+	assert.ErrorIs(t, service.Delete(ctx, "unknown.ics", ""), caldav.ErrObjectNotFound)
+}
+
+func TestOtherUsersTasksAreNotFound(t *testing.T) {
+	service, repo, _, ctx := setup()
+
+	other := withUser()
+	id := task(other, repo, "Theirs", "")
+	require.NoError(t, repo.InsertCaldavObject(other, repositories.InsertCaldavObjectParams{
+		TodoID: repo.tasks[id].ID,
+		UserID: userOf(other),
+		Name:   "theirs.ics",
+		Uid:    "theirs@example.com",
+	}))
+
+	objects, err := service.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	_, err = service.Get(ctx, id+".ics")
+	assert.ErrorIs(t, err, caldav.ErrObjectNotFound)
+	_, err = service.Get(ctx, "theirs.ics")
+	assert.ErrorIs(t, err, caldav.ErrObjectNotFound)
+
+	assert.ErrorIs(t, service.Delete(ctx, id+".ics", ""), caldav.ErrObjectNotFound)
+	assert.Contains(t, repo.tasks, id)
+
+	// a PUT to the name creates a task of the caller and leaves theirs alone
+	created, err := service.Put(ctx, id+".ics", strings.NewReader(vtodo("UID:"+id, "SUMMARY:Mine")), "", "")
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "Theirs", repo.tasks[id].Title)
+}
+
+func TestPut_LeavesNothingBehindWhenTheObjectIsNotStored(t *testing.T) {
+	service, repo, _, ctx := setup()
+	repo.failInsert = true
+
+	body := vtodo("UID:abc@example.com", "SUMMARY:Buy milk")
+
+	_, err := service.Put(ctx, "abc.ics", strings.NewReader(body), "", "")
+	assert.Error(t, err)
+	assert.Empty(t, repo.tasks)
+
+	// the retried PUT creates the task once
+	repo.failInsert = false
+
+	created, err := service.Put(ctx, "abc.ics", strings.NewReader(body), "", "")
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Len(t, repo.tasks, 1)
+}
@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"errors"
+	"ilcs/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ICalendarHandler interface {
+	CreateFeed(c *gin.Context)
+	ListFeeds(c *gin.Context)
+	RevokeFeed(c *gin.Context)
+	GetFeed(c *gin.Context)
+}
+
+type CalendarHandler struct {
+	service ICalendarService
+}
+
+func NewCalendarHandler(service ICalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		service: service,
+	}
+}
+
+func errorStatus(err error) int {
+
+	switch {
+	case errors.Is(err, ErrNoUser):
+		return 403
+	case errors.Is(err, ErrFeedNotFound):
+		return 404
+	}
+
+	return 500
+}
+
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+
+	var req CreateFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.service.CreateFeed(c, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"message": "Calendar feed created successfully", "feed": feed})
+}
+
+func (h *CalendarHandler) ListFeeds(c *gin.Context) {
+
+	feeds, err := h.service.ListFeeds(c)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"feeds": feeds})
+}
+
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.service.RevokeFeed(c, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Calendar feed revoked successfully"})
+}
+
+// GetFeed serves a feed to calendar apps. They cannot send an Authorization
+// header, the token in the path is the credential.
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok || token == "" {
+		c.JSON(404, gin.H{"error": ErrFeedNotFound.Error()})
+		return
+	}
+
+	data, err := h.service.RenderFeed(c, token)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(200, "text/calendar; charset=utf-8", data)
+}
@@ -0,0 +1,31 @@
+package calendar
+
+import "time"
+
+const (
+	// ComponentEvent publishes tasks as events, which every calendar app shows.
+	ComponentEvent = "event"
+	// ComponentTodo publishes tasks as to-dos, for apps with a task list.
+	ComponentTodo = "todo"
+)
+
+type CreateFeedRequest struct {
+	Name      string  `json:"name" binding:"required,max=64"`
+	Component string  `json:"component" binding:"omitempty,oneof=event todo"`
+	Status    *string `json:"status" binding:"omitempty,oneof=pending completed"`
+	Search    *string `json:"search" binding:"omitempty,max=255"`
+	Board     *string `json:"board" binding:"omitempty,max=64"`
+}
+
+type Feed struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Component string  `json:"component"`
+	Status    *string `json:"status,omitempty"`
+	Search    *string `json:"search,omitempty"`
+	Board     *string `json:"board,omitempty"`
+	// Path is the address of the feed, it is only returned when the feed is
+	// created.
+	Path      string    `json:"path,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,335 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"ilcs/internal/ical"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// FeedHistory is how long tasks stay in feeds after their due day.
+	FeedHistory = 90 * 24 * time.Hour
+	// MaxFeedTodos caps the number of tasks of a feed.
+	MaxFeedTodos = 2000
+	// FeedPath is where feeds are served, followed by their token and ".ics".
+	FeedPath = "/api/v1/calendar/"
+)
+
+var (
+	ErrNoUser       = errors.New("token is not bound to a user")
+	ErrFeedNotFound = errors.New("calendar feed not found")
+)
+
+type ICalendarService interface {
+	CreateFeed(ctx context.Context, req CreateFeedRequest) (feed Feed, err error)
+	ListFeeds(ctx context.Context) (feeds []Feed, err error)
+	RevokeFeed(ctx context.Context, id string) (err error)
+	RenderFeed(ctx context.Context, token string) (data []byte, err error)
+}
+
+type CalendarService struct {
+	repo repositories.Querier
+}
+
+func NewCalendarService(repo repositories.Querier) *CalendarService {
+	return &CalendarService{
+		repo: repo,
+	}
+}
+
+func currentUser(ctx context.Context) (pgtype.UUID, error) {
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		return pgtype.UUID{}, ErrNoUser
+	}
+
+	return pgtype.UUID{Bytes: userId, Valid: true}, nil
+}
+
+func newToken() (string, error) {
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken is what is stored of a feed token. Feed addresses end up in the
+// settings of calendar apps, a leaked database should not expose them too.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func optional(text pgtype.Text) *string {
+
+	if !text.Valid {
+		return nil
+	}
+
+	return &text.String
+}
+
+func nullable(value *string) pgtype.Text {
+
+	if value == nil {
+		return pgtype.Text{}
+	}
+
+	return pgtype.Text{String: *value, Valid: true}
+}
+
+func toFeed(data repositories.CalendarFeed) Feed {
+	return Feed{
+		ID:        data.ID.String(),
+		Name:      data.Name,
+		Component: data.Component,
+		Status:    optional(data.Status),
+		Search:    optional(data.Search),
+		Board:     optional(data.Board),
+		CreatedAt: data.CreatedAt.Time,
+	}
+}
+
+// CreateFeed saves a feed with its filters. Its token is part of the returned
+// path and cannot be looked up again, a lost address means a new feed.
+func (s *CalendarService) CreateFeed(ctx context.Context, req CreateFeedRequest) (feed Feed, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	if req.Component == "" {
+		req.Component = ComponentEvent
+	}
+
+	data, err := s.repo.InsertCalendarFeed(ctx, repositories.InsertCalendarFeedParams{
+		ID:        pgtype.UUID{Bytes: id, Valid: true},
+		UserID:    userId,
+		Name:      req.Name,
+		TokenHash: hashToken(token),
+		Component: req.Component,
+		Status:    nullable(req.Status),
+		Search:    nullable(req.Search),
+		Board:     nullable(req.Board),
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	feed = toFeed(data)
+	feed.Path = FeedPath + token + ".ics"
+
+	return
+}
+
+func (s *CalendarService) ListFeeds(ctx context.Context) (feeds []Feed, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data, err := s.repo.ListCalendarFeedsByUser(ctx, userId)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	feeds = []Feed{}
+	for _, item := range data {
+		feeds = append(feeds, toFeed(item))
+	}
+
+	return
+}
+
+// RevokeFeed deletes a feed, its address stops working right away.
+func (s *CalendarService) RevokeFeed(ctx context.Context, id string) (err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	uuidFeed, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	deleted, err := s.repo.DeleteCalendarFeed(ctx, repositories.DeleteCalendarFeedParams{
+		ID:     pgtype.UUID{Bytes: uuidFeed, Valid: true},
+		UserID: userId,
+	})
+
+	if err == nil && deleted == 0 {
+		err = ErrFeedNotFound
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// RenderFeed returns the iCalendar object of the feed a token belongs to. The
+// token is all a feed reader has, so the owner and filters come from the feed.
+func (s *CalendarService) RenderFeed(ctx context.Context, token string) (data []byte, err error) {
+
+	feed, err := s.repo.GetCalendarFeedByToken(ctx, hashToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrFeedNotFound
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	now := time.Now()
+
+	todos, err := s.repo.ListCalendarTodos(ctx, repositories.ListCalendarTodosParams{
+		UserID:   feed.UserID,
+		DueSince: pgtype.Date{Time: now.Add(-FeedHistory).UTC().Truncate(24 * time.Hour), Valid: true},
+		Status:   feed.Status,
+		Search:   feed.Search,
+		Board:    feed.Board,
+		LimitVal: MaxFeedTodos,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	cal := ical.Component{Name: "VCALENDAR"}
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", "-//ilcs//Tasks//EN")
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.Add("METHOD", "PUBLISH")
+	cal.AddText("X-WR-CALNAME", feed.Name)
+	cal.Add("REFRESH-INTERVAL", "PT1H", "VALUE", "DURATION")
+	cal.Add("X-PUBLISHED-TTL", "PT1H")
+
+	for _, todo := range todos {
+		cal.Components = append(cal.Components, taskComponent(todo, feed.Component, now))
+	}
+
+	var buf bytes.Buffer
+	if err = ical.Encode(&buf, cal); err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data = buf.Bytes()
+
+	return
+}
+
+// taskComponent is a task as a VEVENT, or as a VTODO for to-do feeds. Events
+// last the due day, or take no time when the task is due at a given time.
+func taskComponent(todo repositories.ListCalendarTodosRow, component string, now time.Time) ical.Component {
+
+	c := ical.Component{Name: "VEVENT"}
+	if component == ComponentTodo {
+		c.Name = "VTODO"
+	}
+
+	stamp := now
+	if todo.UpdatedAt.Valid {
+		stamp = todo.UpdatedAt.Time
+	}
+
+	c.Add("UID", todo.ID.String()+"@ilcs")
+	c.Add("DTSTAMP", ical.FormatDateTime(stamp))
+	c.AddText("SUMMARY", todo.Title)
+
+	if todo.Description.String != "" {
+		c.AddText("DESCRIPTION", todo.Description.String)
+	}
+
+	if len(todo.Tags) > 0 {
+		tags := make([]string, 0, len(todo.Tags))
+		for _, tag := range todo.Tags {
+			tags = append(tags, ical.EscapeText(tag))
+		}
+
+		c.Add("CATEGORIES", strings.Join(tags, ","))
+	}
+
+	if todo.CreatedAt.Valid {
+		c.Add("CREATED", ical.FormatDateTime(todo.CreatedAt.Time))
+	}
+
+	if todo.UpdatedAt.Valid {
+		c.Add("LAST-MODIFIED", ical.FormatDateTime(todo.UpdatedAt.Time))
+	}
+
+	if component == ComponentTodo {
+		if todo.DueAt.Valid {
+			c.Add("DUE", ical.FormatDateTime(todo.DueAt.Time))
+		} else {
+			c.Add("DUE", ical.FormatDate(todo.DueDate.Time), "VALUE", "DATE")
+		}
+
+		if todo.Status == repositories.TodoStatusCompleted {
+			c.Add("STATUS", "COMPLETED")
+			if todo.CompletedAt.Valid {
+				c.Add("COMPLETED", ical.FormatDateTime(todo.CompletedAt.Time))
+			}
+		} else {
+			c.Add("STATUS", "NEEDS-ACTION")
+		}
+
+		return c
+	}
+
+	if todo.DueAt.Valid {
+		c.Add("DTSTART", ical.FormatDateTime(todo.DueAt.Time))
+	} else {
+		c.Add("DTSTART", ical.FormatDate(todo.DueDate.Time), "VALUE", "DATE")
+		c.Add("DTEND", ical.FormatDate(todo.DueDate.Time.AddDate(0, 0, 1)), "VALUE", "DATE")
+	}
+
+	// tasks do not make their owner busy
+	c.Add("TRANSP", "TRANSPARENT")
+
+	return c
+}
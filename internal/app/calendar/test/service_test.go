@@ -0,0 +1,197 @@
+package calendar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/calendar"
+	"ilcs/internal/constants"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepo struct {
+	mock.Mock
+	repositories.Querier
+}
+
+func (m *MockRepo) InsertCalendarFeed(ctx context.Context, params repositories.InsertCalendarFeedParams) (repositories.CalendarFeed, error) {
+	args := m.Called(ctx, params)
+	return repositories.CalendarFeed{
+		ID:        params.ID,
+		UserID:    params.UserID,
+		Name:      params.Name,
+		TokenHash: params.TokenHash,
+		Component: params.Component,
+		Status:    params.Status,
+		Search:    params.Search,
+		Board:     params.Board,
+	}, args.Error(0)
+}
+
+func (m *MockRepo) GetCalendarFeedByToken(ctx context.Context, tokenHash string) (repositories.CalendarFeed, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(repositories.CalendarFeed), args.Error(1)
+}
+
+func (m *MockRepo) ListCalendarTodos(ctx context.Context, params repositories.ListCalendarTodosParams) ([]repositories.ListCalendarTodosRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.ListCalendarTodosRow), args.Error(1)
+}
+
+func (m *MockRepo) DeleteCalendarFeed(ctx context.Context, params repositories.DeleteCalendarFeedParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func userContext(userId uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), constants.USER_ID, userId.String())
+}
+
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCreateFeed_StoresOnlyTheTokenHash(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := calendar.NewCalendarService(mockRepo)
+
+	var stored repositories.InsertCalendarFeedParams
+	mockRepo.On("InsertCalendarFeed", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(repositories.InsertCalendarFeedParams)
+	}).Return(nil)
+
+	board := "work"
+	feed, err := service.CreateFeed(userContext(uuid.New()), calendar.CreateFeedRequest{Name: "Work", Board: &board})
+	require.NoError(t, err)
+
+	token, ok := strings.CutSuffix(strings.TrimPrefix(feed.Path, calendar.FeedPath), ".ics")
+	require.True(t, ok)
+	assert.Len(t, token, 64)
+	assert.Equal(t, hash(token), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, token)
+	assert.Equal(t, calendar.ComponentEvent, feed.Component)
+	assert.Equal(t, pgtype.Text{String: "work", Valid: true}, stored.Board)
+	assert.False(t, stored.Status.Valid)
+}
+
+func TestCreateFeed_NeedsUser(t *testing.T) {
+	service := calendar.NewCalendarService(new(MockRepo))
+
+	_, err := service.CreateFeed(context.Background(), calendar.CreateFeedRequest{Name: "Work"})
+
+	assert.ErrorIs(t, err, calendar.ErrNoUser)
+}
+
+func TestRenderFeed_UnknownToken(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := calendar.NewCalendarService(mockRepo)
+
+	mockRepo.On("GetCalendarFeedByToken", mock.Anything, hash("nope")).Return(repositories.CalendarFeed{}, pgx.ErrNoRows)
+
+	_, err := service.RenderFeed(context.Background(), "nope")
+
+	assert.ErrorIs(t, err, calendar.ErrFeedNotFound)
+}
+
+func TestRenderFeed_EventsWithSavedFilters(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := calendar.NewCalendarService(mockRepo)
+
+	userId := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	feed := repositories.CalendarFeed{
+		UserID:    userId,
+		Name:      "Work, mostly",
+		Component: calendar.ComponentEvent,
+		Status:    pgtype.Text{String: "pending", Valid: true},
+		Board:     pgtype.Text{String: "work", Valid: true},
+	}
+
+	allDay, timed := uuid.New(), uuid.New()
+
+	mockRepo.On("GetCalendarFeedByToken", mock.Anything, hash("secret")).Return(feed, nil)
+	mockRepo.On("ListCalendarTodos", mock.Anything, mock.MatchedBy(func(params repositories.ListCalendarTodosParams) bool {
+		return params.UserID == userId && params.Status == feed.Status && params.Board == feed.Board && !params.Search.Valid &&
+			params.DueSince.Time.Before(time.Now().Add(-calendar.FeedHistory+24*time.Hour))
+	})).Return([]repositories.ListCalendarTodosRow{
+		{
+			ID:          pgtype.UUID{Bytes: allDay, Valid: true},
+			Title:       "Ship release; tag it",
+			Description: pgtype.Text{String: "line one\nline two", Valid: true},
+			Status:      repositories.TodoStatusPending,
+			DueDate:     pgtype.Date{Time: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), Valid: true},
+			Tags:        []string{"release", "q1"},
+		},
+		{
+			ID:      pgtype.UUID{Bytes: timed, Valid: true},
+			Title:   "Standup",
+			Status:  repositories.TodoStatusPending,
+			DueDate: pgtype.Date{Time: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			DueAt:   pgtype.Timestamptz{Time: time.Date(2025, 4, 1, 9, 30, 0, 0, time.FixedZone("CEST", 2*3600)), Valid: true},
+		},
+	}, nil)
+
+	data, err := service.RenderFeed(context.Background(), "secret")
+	require.NoError(t, err)
+
+	ics := string(data)
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "X-WR-CALNAME:Work\\, mostly\r\n")
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, ics, "UID:"+allDay.String()+"@ilcs\r\n")
+	assert.Contains(t, ics, "SUMMARY:Ship release\\; tag it\r\n")
+	assert.Contains(t, ics, "DESCRIPTION:line one\\nline two\r\n")
+	assert.Contains(t, ics, "CATEGORIES:release,q1\r\n")
+	assert.Contains(t, ics, "DTSTART;VALUE=DATE:20250331\r\nDTEND;VALUE=DATE:20250401\r\n")
+	assert.Contains(t, ics, "DTSTART:20250401T073000Z\r\n")
+	assert.NotContains(t, ics, "VTODO")
+}
+
+func TestRenderFeed_Todos(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := calendar.NewCalendarService(mockRepo)
+
+	completedAt := time.Date(2025, 3, 30, 17, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetCalendarFeedByToken", mock.Anything, hash("secret")).Return(repositories.CalendarFeed{Name: "Tasks", Component: calendar.ComponentTodo}, nil)
+	mockRepo.On("ListCalendarTodos", mock.Anything, mock.Anything).Return([]repositories.ListCalendarTodosRow{
+		{
+			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:       "Done",
+			Status:      repositories.TodoStatusCompleted,
+			DueDate:     pgtype.Date{Time: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), Valid: true},
+			CompletedAt: pgtype.Timestamptz{Time: completedAt, Valid: true},
+		},
+	}, nil)
+
+	data, err := service.RenderFeed(context.Background(), "secret")
+	require.NoError(t, err)
+
+	ics := string(data)
+	assert.Contains(t, ics, "BEGIN:VTODO\r\n")
+	assert.Contains(t, ics, "DUE;VALUE=DATE:20250331\r\n")
+	assert.Contains(t, ics, "STATUS:COMPLETED\r\nCOMPLETED:20250330T170000Z\r\n")
+	assert.NotContains(t, ics, "DTSTART")
+}
+
+func TestRevokeFeed_OtherUsersFeed(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := calendar.NewCalendarService(mockRepo)
+
+	mockRepo.On("DeleteCalendarFeed", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	err := service.RevokeFeed(userContext(uuid.New()), uuid.NewString())
+
+	assert.ErrorIs(t, err, calendar.ErrFeedNotFound)
+}
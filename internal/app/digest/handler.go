@@ -0,0 +1,48 @@
+package digest
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IDigestHandler interface {
+	GetDigest(c *gin.Context)
+}
+
+type DigestHandler struct {
+	service IDigestService
+}
+
+func NewDigestHandler(service IDigestService) *DigestHandler {
+	return &DigestHandler{
+		service: service,
+	}
+}
+
+func (h *DigestHandler) GetDigest(c *gin.Context) {
+
+	var req DigestRequestParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	days := 3
+	if req.Days != nil {
+		days = *req.Days
+	}
+
+	digest, err := h.service.GetDigest(c, days)
+	if errors.Is(err, ErrNoUser) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, digest)
+}
@@ -0,0 +1,148 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"ilcs/internal/notify"
+	"ilcs/internal/repositories"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// claimLease is how long a claimed batch is left to its worker, it has to
+// outlast the delivery of the whole batch.
+const claimLease = 5 * time.Minute
+
+// Job sends every user one digest per day once their local clock reaches the
+// configured hour. Users are claimed for a lease with FOR UPDATE SKIP LOCKED,
+// so running several workers does not send duplicates, and their digests are
+// sent once the claim is committed.
+type Job struct {
+	repo     repositories.Querier
+	channels map[repositories.ReminderChannel]notify.Channel
+	days     int
+	hour     int
+	interval time.Duration
+	batch    int32
+}
+
+func NewJob(db repositories.DBTX, channels map[repositories.ReminderChannel]notify.Channel, days, hour int, interval time.Duration) *Job {
+	return &Job{
+		repo:     repositories.New(db),
+		channels: channels,
+		days:     days,
+		hour:     hour,
+		interval: interval,
+		batch:    50,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := j.Tick(ctx)
+		if err != nil {
+			log.Error().Err(err).Send()
+		} else if sent > 0 {
+			log.Info().Int("sent", sent).Msg("Digests sent")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick processes one batch of users and reports how many digests were sent.
+func (j *Job) Tick(ctx context.Context) (sent int, err error) {
+
+	users, err := j.repo.ClaimDigestUsers(ctx, repositories.ClaimDigestUsersParams{
+		Hour:         int32(j.hour),
+		LimitVal:     j.batch,
+		LeaseSeconds: int32(claimLease / time.Second),
+	})
+
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	for _, user := range users {
+
+		digest, errB := build(ctx, j.repo, user.ID, user.Timezone, j.days, now)
+		if errB != nil {
+			// the user is claimed again once the lease is over
+			log.Error().Err(errB).Str("user_id", user.ID.String()).Send()
+			continue
+		}
+
+		if len(digest.Overdue) > 0 || len(digest.Upcoming) > 0 {
+			j.deliver(ctx, user, digest)
+			sent++
+		}
+
+		date, _ := time.Parse("2006-01-02", digest.Date)
+
+		err = j.repo.MarkDigestSent(ctx, repositories.MarkDigestSentParams{
+			ID:           user.ID,
+			DigestSentOn: pgtype.Date{Time: date, Valid: true},
+		})
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (j *Job) deliver(ctx context.Context, user repositories.User, digest Digest) {
+
+	msg := notify.Message{
+		Kind:    notify.KindDigest,
+		UserID:  user.ID.String(),
+		Subject: "Your task digest for " + digest.Date,
+		Body:    fmt.Sprintf("You have %d overdue and %d upcoming tasks.", len(digest.Overdue), len(digest.Upcoming)),
+	}
+
+	for _, item := range digest.Overdue {
+		msg.Items = append(msg.Items, notify.Item{Title: item.Title, Due: due(item), Overdue: true})
+	}
+
+	for _, item := range digest.Upcoming {
+		msg.Items = append(msg.Items, notify.Item{Title: item.Title, Due: due(item)})
+	}
+
+	kinds := []repositories.ReminderChannel{repositories.ReminderChannelInApp}
+	if user.Email.Valid {
+		kinds = append(kinds, repositories.ReminderChannelEmail)
+	}
+
+	for _, kind := range kinds {
+		channel, ok := j.channels[kind]
+		if !ok {
+			continue
+		}
+
+		if err := channel.Send(ctx, msg); err != nil {
+			log.Error().Err(err).Str("user_id", user.ID.String()).Str("channel", string(kind)).Send()
+		}
+	}
+}
+
+func due(item Item) string {
+
+	if item.DueAt != "" {
+		return item.DueAt
+	}
+
+	return item.DueDate
+}
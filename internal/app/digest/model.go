@@ -0,0 +1,19 @@
+package digest
+
+type DigestRequestParams struct {
+	Days *int `form:"days" binding:"omitempty,min=0,max=30"`
+}
+
+type Item struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	DueDate string `json:"due_date"`
+	DueAt   string `json:"due_at,omitempty"`
+}
+
+type Digest struct {
+	Date     string `json:"date"`
+	Timezone string `json:"timezone"`
+	Overdue  []Item `json:"overdue"`
+	Upcoming []Item `json:"upcoming"`
+}
@@ -0,0 +1,115 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const maxDigestItems = 200
+
+var ErrNoUser = errors.New("token is not bound to a user")
+
+type IDigestService interface {
+	GetDigest(ctx context.Context, days int) (digest Digest, err error)
+}
+
+type DigestService struct {
+	repo repositories.Querier
+}
+
+func NewDigestService(repo repositories.Querier) *DigestService {
+	return &DigestService{
+		repo: repo,
+	}
+}
+
+func (s *DigestService) GetDigest(ctx context.Context, days int) (digest Digest, err error) {
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		err = ErrNoUser
+		return
+	}
+
+	id := pgtype.UUID{Bytes: userId, Valid: true}
+
+	timezone := "UTC"
+
+	user, err := s.repo.GetUserById(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	if err == nil {
+		timezone = user.Timezone
+	}
+
+	digest, err = build(ctx, s.repo, id, timezone, days, time.Now())
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// build compiles the pending tasks of a user that are overdue or due within
+// the next days, today included, as seen from the user's time zone.
+func build(ctx context.Context, repo repositories.Querier, userId pgtype.UUID, timezone string, days int, now time.Time) (digest Digest, err error) {
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return
+	}
+
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	rows, err := repo.ListDigestTodos(ctx, repositories.ListDigestTodosParams{
+		UserID:    userId,
+		DueBefore: pgtype.Date{Time: today.AddDate(0, 0, days), Valid: true},
+		LimitVal:  maxDigestItems,
+	})
+
+	if err != nil {
+		return
+	}
+
+	digest = Digest{
+		Date:     today.Format("2006-01-02"),
+		Timezone: loc.String(),
+		Overdue:  []Item{},
+		Upcoming: []Item{},
+	}
+
+	for _, row := range rows {
+		item := Item{
+			ID:      row.ID.String(),
+			Title:   row.Title,
+			DueDate: row.DueDate.Time.Format("2006-01-02"),
+		}
+
+		overdue := row.DueDate.Time.Before(today)
+		if row.DueAt.Valid {
+			item.DueAt = row.DueAt.Time.In(loc).Format(time.RFC3339)
+			overdue = now.After(row.DueAt.Time)
+		}
+
+		if overdue {
+			digest.Overdue = append(digest.Overdue, item)
+		} else {
+			digest.Upcoming = append(digest.Upcoming, item)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,106 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/digest"
+	"ilcs/internal/constants"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepo struct {
+	mock.Mock
+	repositories.Querier
+}
+
+func (m *MockRepo) GetUserById(ctx context.Context, id pgtype.UUID) (repositories.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repositories.User), args.Error(1)
+}
+
+func (m *MockRepo) ListDigestTodos(ctx context.Context, params repositories.ListDigestTodosParams) ([]repositories.ListDigestTodosRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.ListDigestTodosRow), args.Error(1)
+}
+
+func TestGetDigest_SplitsOverdueAndUpcoming(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := digest.NewDigestService(mockRepo)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	local := time.Now().In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	rows := []repositories.ListDigestTodosRow{
+		{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:   "Yesterday",
+			DueDate: pgtype.Date{Time: today.AddDate(0, 0, -1), Valid: true},
+		},
+		{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:   "An hour ago",
+			DueDate: pgtype.Date{Time: today, Valid: true},
+			DueAt:   pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+		},
+		{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:   "Tomorrow",
+			DueDate: pgtype.Date{Time: today.AddDate(0, 0, 1), Valid: true},
+		},
+	}
+
+	mockRepo.On("GetUserById", mock.Anything, pgtype.UUID{Bytes: userId, Valid: true}).Return(repositories.User{Timezone: "Asia/Jakarta"}, nil)
+	// the next 3 days are today and the 2 days after, due_before is exclusive
+	mockRepo.On("ListDigestTodos", mock.Anything, mock.MatchedBy(func(params repositories.ListDigestTodosParams) bool {
+		return params.DueBefore.Time.Equal(today.AddDate(0, 0, 3))
+	})).Return(rows, nil)
+
+	result, err := service.GetDigest(ctx, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, today.Format("2006-01-02"), result.Date)
+	assert.Equal(t, "Asia/Jakarta", result.Timezone)
+	assert.Len(t, result.Overdue, 2)
+	assert.Len(t, result.Upcoming, 1)
+	assert.Equal(t, "Tomorrow", result.Upcoming[0].Title)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetDigest_UserWithoutSettings(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := digest.NewDigestService(mockRepo)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	mockRepo.On("GetUserById", mock.Anything, mock.Anything).Return(repositories.User{}, pgx.ErrNoRows)
+	mockRepo.On("ListDigestTodos", mock.Anything, mock.Anything).Return([]repositories.ListDigestTodosRow{}, nil)
+
+	result, err := service.GetDigest(ctx, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", result.Timezone)
+	assert.Empty(t, result.Overdue)
+	assert.Empty(t, result.Upcoming)
+}
+
+func TestGetDigest_NoUser(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := digest.NewDigestService(mockRepo)
+
+	_, err := service.GetDigest(context.Background(), 3)
+
+	assert.ErrorIs(t, err, digest.ErrNoUser)
+}
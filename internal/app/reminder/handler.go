@@ -0,0 +1,123 @@
+package reminder
+
+import (
+	"errors"
+	"ilcs/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type IReminderHandler interface {
+	CreateReminder(c *gin.Context)
+	ListReminders(c *gin.Context)
+	DeleteReminder(c *gin.Context)
+	ListNotifications(c *gin.Context)
+}
+
+type ReminderHandler struct {
+	service IReminderService
+}
+
+func NewReminderHandler(service IReminderService) *ReminderHandler {
+	return &ReminderHandler{
+		service: service,
+	}
+}
+
+func (h *ReminderHandler) CreateReminder(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req CreateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	reminder, err := h.service.CreateReminder(c, id, req)
+	if errors.Is(err, ErrInvalidTarget) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, ErrTodoNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"message": "Reminder created successfully", "reminder": reminder})
+}
+
+func (h *ReminderHandler) ListReminders(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	reminders, err := h.service.ListReminders(c, id)
+	if errors.Is(err, ErrTodoNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"reminders": reminders})
+}
+
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.service.DeleteReminder(c, id)
+	if errors.Is(err, ErrReminderNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Reminder deleted successfully"})
+}
+
+func (h *ReminderHandler) ListNotifications(c *gin.Context) {
+
+	notifications, err := h.service.ListNotifications(c)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"notifications": notifications})
+}
@@ -0,0 +1,8 @@
+package reminder
+
+type CreateReminderRequest struct {
+	RemindAt      string `json:"remind_at" binding:"required_without=OffsetMinutes,omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	OffsetMinutes *int   `json:"offset_minutes" binding:"omitempty,min=0"`
+	Channel       string `json:"channel" binding:"required,oneof=webhook email in_app"`
+	Target        string `json:"target" binding:"required_if=Channel webhook"`
+}
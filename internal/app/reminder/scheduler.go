@@ -0,0 +1,130 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"ilcs/internal/notify"
+	"ilcs/internal/repositories"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxAttempts = 5
+
+	// claimLease is how long a claimed batch is left to its worker, it has to
+	// outlast the delivery of the whole batch.
+	claimLease = 5 * time.Minute
+)
+
+// Scheduler polls for due reminders and hands them to the delivery channels.
+// Reminders are claimed for a lease with FOR UPDATE SKIP LOCKED, so several
+// workers can run side by side without firing a reminder twice, and delivered
+// once the claim is committed so a slow channel holds no row locks.
+type Scheduler struct {
+	repo     repositories.Querier
+	channels map[repositories.ReminderChannel]notify.Channel
+	interval time.Duration
+	batch    int32
+}
+
+func NewScheduler(db repositories.DBTX, channels map[repositories.ReminderChannel]notify.Channel, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		repo:     repositories.New(db),
+		channels: channels,
+		interval: interval,
+		batch:    100,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		fired, err := s.Tick(ctx)
+		if err != nil {
+			log.Error().Err(err).Send()
+		} else if fired > 0 {
+			log.Info().Int("fired", fired).Msg("Reminders fired")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick fires one batch of due reminders and reports how many were delivered.
+func (s *Scheduler) Tick(ctx context.Context) (fired int, err error) {
+
+	reminders, err := s.repo.ClaimDueReminders(ctx, repositories.ClaimDueRemindersParams{
+		MaxAttempts:  maxAttempts,
+		LimitVal:     s.batch,
+		LeaseSeconds: int32(claimLease / time.Second),
+	})
+
+	if err != nil {
+		return
+	}
+
+	for _, item := range reminders {
+
+		sendErr := s.deliver(ctx, item)
+		if sendErr != nil {
+			log.Error().Err(sendErr).Str("reminder_id", item.ID.String()).Send()
+
+			err = s.repo.MarkReminderFailed(ctx, repositories.MarkReminderFailedParams{
+				ID:        item.ID,
+				LastError: pgtype.Text{String: sendErr.Error(), Valid: true},
+			})
+
+			if err != nil {
+				return
+			}
+
+			continue
+		}
+
+		err = s.repo.MarkReminderFired(ctx, item.ID)
+		if err != nil {
+			return
+		}
+
+		fired++
+	}
+
+	return
+}
+
+func (s *Scheduler) deliver(ctx context.Context, item repositories.ClaimDueRemindersRow) error {
+
+	channel, ok := s.channels[item.Channel]
+	if !ok {
+		return fmt.Errorf("no delivery channel configured for %s", item.Channel)
+	}
+
+	due := item.DueDate.Time.Format("2006-01-02")
+	if item.DueAt.Valid {
+		due = item.DueAt.Time.UTC().Format(time.RFC3339)
+	}
+
+	msg := notify.Message{
+		Kind:    notify.KindReminder,
+		TodoID:  item.TodoID.String(),
+		Subject: "Reminder: " + item.Title,
+		Body:    fmt.Sprintf("Reminder: %q is due %s", item.Title, due),
+		Target:  item.Target.String,
+	}
+
+	if item.UserID.Valid {
+		msg.UserID = item.UserID.String()
+	}
+
+	return channel.Send(ctx, msg)
+}
@@ -0,0 +1,222 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidTarget    = errors.New("invalid reminder target")
+	ErrTodoNotFound     = errors.New("task not found")
+	ErrReminderNotFound = errors.New("reminder not found")
+)
+
+type IReminderService interface {
+	CreateReminder(ctx context.Context, todoId string, req CreateReminderRequest) (reminder repositories.Reminder, err error)
+	ListReminders(ctx context.Context, todoId string) (reminders []repositories.Reminder, err error)
+	DeleteReminder(ctx context.Context, id string) (err error)
+	ListNotifications(ctx context.Context) (notifications []repositories.Notification, err error)
+}
+
+type ReminderService struct {
+	repo repositories.Querier
+}
+
+func NewReminderService(repo repositories.Querier) *ReminderService {
+	return &ReminderService{
+		repo: repo,
+	}
+}
+
+func validateTarget(channel repositories.ReminderChannel, target string) error {
+
+	switch channel {
+	case repositories.ReminderChannelWebhook:
+		u, err := url.ParseRequestURI(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return ErrInvalidTarget
+		}
+	case repositories.ReminderChannelEmail:
+		// without a target the address from the user's preferences is used
+		if target == "" {
+			return nil
+		}
+
+		if _, err := mail.ParseAddress(target); err != nil {
+			return ErrInvalidTarget
+		}
+	}
+
+	return nil
+}
+
+// ownTodo returns the caller when the task is theirs, other users' tasks are
+// not found.
+func (s *ReminderService) ownTodo(ctx context.Context, todoId uuid.UUID) (userId pgtype.UUID, err error) {
+
+	uuidUser, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		err = ErrTodoNotFound
+		return
+	}
+
+	userId = pgtype.UUID{Bytes: uuidUser, Valid: true}
+
+	todo, err := s.repo.GetTodoById(ctx, pgtype.UUID{Bytes: todoId, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && todo.UserID != userId) {
+		err = ErrTodoNotFound
+	}
+
+	return
+}
+
+func (s *ReminderService) CreateReminder(ctx context.Context, todoId string, req CreateReminderRequest) (reminder repositories.Reminder, err error) {
+
+	uuidTodo, err := uuid.Parse(todoId)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	channel := repositories.ReminderChannel(req.Channel)
+
+	err = validateTarget(channel, req.Target)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	userId, err := s.ownTodo(ctx, uuidTodo)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	params := repositories.InsertReminderParams{
+		ID:      pgtype.UUID{Bytes: id, Valid: true},
+		TodoID:  pgtype.UUID{Bytes: uuidTodo, Valid: true},
+		UserID:  userId,
+		Channel: channel,
+	}
+
+	if req.Target != "" && channel != repositories.ReminderChannelInApp {
+		params.Target = pgtype.Text{String: req.Target, Valid: true}
+	}
+
+	if req.RemindAt != "" {
+		remindAt, errP := time.Parse(time.RFC3339, req.RemindAt)
+		if errP != nil {
+			err = errP
+			log.Error().Err(err).Send()
+			return
+		}
+
+		params.RemindAt = pgtype.Timestamptz{Time: remindAt, Valid: true}
+	} else {
+		params.OffsetMinutes = pgtype.Int4{Int32: int32(*req.OffsetMinutes), Valid: true}
+	}
+
+	reminder, err = s.repo.InsertReminder(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+func (s *ReminderService) ListReminders(ctx context.Context, todoId string) (reminders []repositories.Reminder, err error) {
+
+	uuidTodo, err := uuid.Parse(todoId)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	userId, err := s.ownTodo(ctx, uuidTodo)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	reminders, err = s.repo.ListRemindersByTodo(ctx, repositories.ListRemindersByTodoParams{
+		TodoID: pgtype.UUID{Bytes: uuidTodo, Valid: true},
+		UserID: userId,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+func (s *ReminderService) DeleteReminder(ctx context.Context, id string) (err error) {
+
+	uuidReminder, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		err = ErrReminderNotFound
+		return
+	}
+
+	deleted, err := s.repo.DeleteReminder(ctx, repositories.DeleteReminderParams{
+		ID:     pgtype.UUID{Bytes: uuidReminder, Valid: true},
+		UserID: pgtype.UUID{Bytes: userId, Valid: true},
+	})
+
+	if err == nil && deleted == 0 {
+		err = ErrReminderNotFound
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+func (s *ReminderService) ListNotifications(ctx context.Context) (notifications []repositories.Notification, err error) {
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		notifications = []repositories.Notification{}
+		err = nil
+		return
+	}
+
+	notifications, err = s.repo.ListNotificationsByUser(ctx, repositories.ListNotificationsByUserParams{
+		UserID:   pgtype.UUID{Bytes: userId, Valid: true},
+		LimitVal: 50,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
@@ -0,0 +1,186 @@
+package reminder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/reminder"
+	"ilcs/internal/constants"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepo struct {
+	mock.Mock
+	repositories.Querier
+}
+
+func (m *MockRepo) InsertReminder(ctx context.Context, params repositories.InsertReminderParams) (repositories.Reminder, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repositories.Reminder), args.Error(1)
+}
+
+func (m *MockRepo) GetTodoById(ctx context.Context, id pgtype.UUID) (repositories.GetTodoByIdRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repositories.GetTodoByIdRow), args.Error(1)
+}
+
+func (m *MockRepo) ListRemindersByTodo(ctx context.Context, params repositories.ListRemindersByTodoParams) ([]repositories.Reminder, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.Reminder), args.Error(1)
+}
+
+func (m *MockRepo) DeleteReminder(ctx context.Context, params repositories.DeleteReminderParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepo) ListNotificationsByUser(ctx context.Context, params repositories.ListNotificationsByUserParams) ([]repositories.Notification, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.Notification), args.Error(1)
+}
+
+func TestCreateReminder_Offset(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := reminder.NewReminderService(mockRepo)
+
+	todoId := uuid.New()
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	offset := 30
+	req := reminder.CreateReminderRequest{
+		OffsetMinutes: &offset,
+		Channel:       "webhook",
+		Target:        "https://example.com/hooks/reminder",
+	}
+
+	mockRepo.On("GetTodoById", mock.Anything, pgtype.UUID{Bytes: todoId, Valid: true}).
+		Return(repositories.GetTodoByIdRow{UserID: pgtype.UUID{Bytes: userId, Valid: true}}, nil)
+
+	mockRepo.On("InsertReminder", mock.Anything, mock.MatchedBy(func(params repositories.InsertReminderParams) bool {
+		return params.TodoID == pgtype.UUID{Bytes: todoId, Valid: true} &&
+			params.UserID == pgtype.UUID{Bytes: userId, Valid: true} &&
+			params.OffsetMinutes == pgtype.Int4{Int32: 30, Valid: true} &&
+			!params.RemindAt.Valid &&
+			params.Target.String == req.Target
+	})).Return(repositories.Reminder{}, nil)
+
+	_, err := service.CreateReminder(ctx, todoId.String(), req)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateReminder_Absolute(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := reminder.NewReminderService(mockRepo)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	req := reminder.CreateReminderRequest{
+		RemindAt: "2025-01-01T09:00:00+07:00",
+		Channel:  "in_app",
+	}
+
+	mockRepo.On("GetTodoById", mock.Anything, mock.Anything).
+		Return(repositories.GetTodoByIdRow{UserID: pgtype.UUID{Bytes: userId, Valid: true}}, nil)
+
+	mockRepo.On("InsertReminder", mock.Anything, mock.MatchedBy(func(params repositories.InsertReminderParams) bool {
+		return params.RemindAt.Time.Equal(time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)) &&
+			!params.OffsetMinutes.Valid &&
+			!params.Target.Valid
+	})).Return(repositories.Reminder{}, nil)
+
+	_, err := service.CreateReminder(ctx, uuid.New().String(), req)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateReminder_InvalidTarget(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := reminder.NewReminderService(mockRepo)
+
+	offset := 0
+	req := reminder.CreateReminderRequest{
+		OffsetMinutes: &offset,
+		Channel:       "email",
+		Target:        "not-an-email",
+	}
+
+	_, err := service.CreateReminder(context.Background(), uuid.New().String(), req)
+
+	assert.ErrorIs(t, err, reminder.ErrInvalidTarget)
+	mockRepo.AssertNotCalled(t, "InsertReminder")
+}
+
+func TestCreateReminder_OtherUsersTodo(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := reminder.NewReminderService(mockRepo)
+
+	ctx := context.WithValue(context.Background(), constants.USER_ID, uuid.New().String())
+
+	mockRepo.On("GetTodoById", mock.Anything, mock.Anything).
+		Return(repositories.GetTodoByIdRow{UserID: pgtype.UUID{Bytes: uuid.New(), Valid: true}}, nil)
+
+	offset := 10
+	_, err := service.CreateReminder(ctx, uuid.New().String(), reminder.CreateReminderRequest{
+		OffsetMinutes: &offset,
+		Channel:       "in_app",
+	})
+
+	assert.ErrorIs(t, err, reminder.ErrTodoNotFound)
+	mockRepo.AssertNotCalled(t, "InsertReminder")
+}
+
+func TestListReminders_OtherUsersTodo(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := reminder.NewReminderService(mockRepo)
+
+	ctx := context.WithValue(context.Background(), constants.USER_ID, uuid.New().String())
+
+	mockRepo.On("GetTodoById", mock.Anything, mock.Anything).
+		Return(repositories.GetTodoByIdRow{UserID: pgtype.UUID{Bytes: uuid.New(), Valid: true}}, nil)
+
+	_, err := service.ListReminders(ctx, uuid.New().String())
+
+	assert.ErrorIs(t, err, reminder.ErrTodoNotFound)
+	mockRepo.AssertNotCalled(t, "ListRemindersByTodo")
+}
+
+func TestDeleteReminder_ScopedToCaller(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := reminder.NewReminderService(mockRepo)
+
+	id := uuid.New()
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	mockRepo.On("DeleteReminder", mock.Anything, repositories.DeleteReminderParams{
+		ID:     pgtype.UUID{Bytes: id, Valid: true},
+		UserID: pgtype.UUID{Bytes: userId, Valid: true},
+	}).Return(int64(0), nil)
+
+	err := service.DeleteReminder(ctx, id.String())
+
+	assert.ErrorIs(t, err, reminder.ErrReminderNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListNotifications_AnonymousToken(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := reminder.NewReminderService(mockRepo)
+
+	notifications, err := service.ListNotifications(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, notifications)
+	mockRepo.AssertNotCalled(t, "ListNotificationsByUser")
+}
@@ -0,0 +1,83 @@
+package stream
+
+import (
+	"errors"
+	"ilcs/internal/utils"
+	"io"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+type IStreamHandler interface {
+	StreamTasks(c *gin.Context)
+}
+
+type StreamHandler struct {
+	service   IStreamService
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(service IStreamService) *StreamHandler {
+	return &StreamHandler{
+		service:   service,
+		heartbeat: 15 * time.Second,
+	}
+}
+
+func (h *StreamHandler) StreamTasks(c *gin.Context) {
+
+	ctx := c.Request.Context()
+
+	// EventSource only sends Last-Event-ID when it reconnects by itself, a
+	// client reconnecting with a new ticket passes it in the query
+	lastEventId := c.GetHeader("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = c.Query("last_event_id")
+	}
+
+	entries, err := h.service.Subscribe(ctx, utils.GetUserId(c), lastEventId)
+	if errors.Is(err, ErrNoUser) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case entry, ok := <-entries:
+			if !ok {
+				return false
+			}
+
+			event := sse.Event{Id: entry.ID, Event: entry.Type, Data: entry.Data}
+			if entry.Data == nil {
+				event.Data = "{}"
+			}
+
+			c.Render(-1, event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ilcs/internal/events"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// EventReset tells a resuming client that events were trimmed from the log
+// since its Last-Event-ID, so it has to reload its tasks.
+const EventReset = "reset"
+
+var ErrNoUser = errors.New("token is not bound to a user")
+
+type RedisClient interface {
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+	XRangeN(ctx context.Context, stream, start, stop string, count int64) *redis.XMessageSliceCmd
+}
+
+type IStreamService interface {
+	Subscribe(ctx context.Context, userId, lastEventId string) (entries <-chan events.FeedEntry, err error)
+}
+
+type StreamService struct {
+	client RedisClient
+	replay int64
+}
+
+func NewStreamService(client RedisClient) *StreamService {
+	return &StreamService{
+		client: client,
+		replay: 1000,
+	}
+}
+
+// Subscribe follows the task events of a user until ctx is done. When
+// lastEventId is set the entries logged after it are replayed first.
+func (s *StreamService) Subscribe(ctx context.Context, userId, lastEventId string) (entries <-chan events.FeedEntry, err error) {
+
+	if _, err = uuid.Parse(userId); err != nil {
+		err = ErrNoUser
+		log.Error().Err(err).Send()
+		return
+	}
+
+	pubsub := s.client.Subscribe(ctx, events.FeedChannel(userId))
+
+	// subscribe before reading the log so nothing published in between is lost
+	if _, err = pubsub.Receive(ctx); err != nil {
+		log.Error().Err(err).Send()
+		pubsub.Close()
+		return
+	}
+
+	var backlog []events.FeedEntry
+	if lastEventId != "" {
+		backlog, err = s.backlog(ctx, userId, lastEventId)
+		if err != nil {
+			log.Error().Err(err).Send()
+			pubsub.Close()
+			return
+		}
+	}
+
+	out := make(chan events.FeedEntry, 16)
+
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		last := lastEventId
+
+		send := func(entry events.FeedEntry) bool {
+			select {
+			case out <- entry:
+				if entry.ID != "" {
+					last = entry.ID
+				}
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, entry := range backlog {
+			if !send(entry) {
+				return
+			}
+		}
+
+		messages := pubsub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var entry events.FeedEntry
+				if err := json.Unmarshal([]byte(msg.Payload), &entry); err != nil {
+					log.Error().Err(err).Send()
+					continue
+				}
+
+				// already sent while replaying the log
+				if !after(entry.ID, last) {
+					continue
+				}
+
+				if !send(entry) {
+					return
+				}
+			}
+		}
+	}()
+
+	entries = out
+
+	return
+}
+
+// backlog returns the entries logged after lastEventId, preceded by a reset
+// entry when the log no longer reaches back to lastEventId.
+func (s *StreamService) backlog(ctx context.Context, userId, lastEventId string) (entries []events.FeedEntry, err error) {
+
+	key := events.FeedKey(userId)
+
+	if _, _, ok := parseID(lastEventId); !ok {
+		return []events.FeedEntry{{Type: EventReset}}, nil
+	}
+
+	oldest, err := s.client.XRangeN(ctx, key, "-", "+", 1).Result()
+	if err != nil {
+		return
+	}
+
+	if len(oldest) > 0 && after(oldest[0].ID, lastEventId) {
+		entries = append(entries, events.FeedEntry{Type: EventReset})
+	}
+
+	messages, err := s.client.XRangeN(ctx, key, "("+lastEventId, "+", s.replay).Result()
+	if err != nil {
+		return
+	}
+
+	for _, msg := range messages {
+		entry := events.FeedEntry{ID: msg.ID}
+		entry.Type, _ = msg.Values["type"].(string)
+
+		data, _ := msg.Values["data"].(string)
+		entry.Data = json.RawMessage(data)
+
+		entries = append(entries, entry)
+	}
+
+	return
+}
+
+// parseID splits a Redis stream id of the form "<ms>-<seq>".
+func parseID(id string) (ms, seq uint64, ok bool) {
+
+	msPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return
+	}
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return
+	}
+
+	seq, err = strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return
+	}
+
+	return ms, seq, true
+}
+
+// after reports whether stream id a comes after b. Every id comes after an
+// empty or malformed b.
+func after(a, b string) bool {
+
+	bMs, bSeq, ok := parseID(b)
+	if !ok {
+		return true
+	}
+
+	aMs, aSeq, ok := parseID(a)
+	if !ok {
+		return false
+	}
+
+	return aMs > bMs || (aMs == bMs && aSeq > bSeq)
+}
@@ -0,0 +1,122 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/stream"
+	"ilcs/internal/events"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T) *redis.Client {
+
+	server := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func publish(t *testing.T, feed *events.UserFeed, userId, eventType string) {
+	err := feed.Publish(context.Background(), events.New(eventType, userId, uuid.New().String(), nil))
+	require.NoError(t, err)
+}
+
+func receive(t *testing.T, entries <-chan events.FeedEntry) events.FeedEntry {
+
+	select {
+	case entry := <-entries:
+		return entry
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an entry")
+		return events.FeedEntry{}
+	}
+}
+
+func TestSubscribe_ReceivesLiveEvents(t *testing.T) {
+	client := newRedis(t)
+	feed := events.NewUserFeed(client)
+	service := stream.NewStreamService(client)
+
+	userId := uuid.New().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entries, err := service.Subscribe(ctx, userId, "")
+	require.NoError(t, err)
+
+	// events of other users are not delivered
+	publish(t, feed, uuid.New().String(), events.TaskCreated)
+	publish(t, feed, userId, events.TaskUpdated)
+
+	entry := receive(t, entries)
+	assert.Equal(t, events.TaskUpdated, entry.Type)
+	assert.NotEmpty(t, entry.ID)
+	assert.Contains(t, string(entry.Data), events.TaskUpdated)
+}
+
+func TestSubscribe_ResumesAfterLastEventID(t *testing.T) {
+	client := newRedis(t)
+	feed := events.NewUserFeed(client)
+	service := stream.NewStreamService(client)
+
+	userId := uuid.New().String()
+
+	publish(t, feed, userId, events.TaskCreated)
+	publish(t, feed, userId, events.TaskUpdated)
+	publish(t, feed, userId, events.TaskDeleted)
+
+	logged, err := client.XRange(context.Background(), events.FeedKey(userId), "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, logged, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entries, err := service.Subscribe(ctx, userId, logged[0].ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, logged[1].ID, receive(t, entries).ID)
+	assert.Equal(t, logged[2].ID, receive(t, entries).ID)
+
+	publish(t, feed, userId, events.TaskCompleted)
+
+	assert.Equal(t, events.TaskCompleted, receive(t, entries).Type)
+}
+
+func TestSubscribe_ResetWhenLogWasTrimmed(t *testing.T) {
+	client := newRedis(t)
+	feed := events.NewUserFeed(client)
+	service := stream.NewStreamService(client)
+
+	userId := uuid.New().String()
+
+	publish(t, feed, userId, events.TaskCreated)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entries, err := service.Subscribe(ctx, userId, "1-0")
+	require.NoError(t, err)
+
+	assert.Equal(t, stream.EventReset, receive(t, entries).Type)
+	assert.Equal(t, events.TaskCreated, receive(t, entries).Type)
+}
+
+func TestSubscribe_RequiresUser(t *testing.T) {
+	client := newRedis(t)
+	service := stream.NewStreamService(client)
+
+	_, err := service.Subscribe(context.Background(), "", "")
+
+	assert.ErrorIs(t, err, stream.ErrNoUser)
+}
@@ -0,0 +1,205 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ilcs/internal/audit"
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var ErrTooManyOperations = fmt.Errorf("a bulk request takes at most %d operations", MaxBulkOperations)
+
+// Bulk runs a batch of operations in order. Atomic batches run in one
+// transaction and stop at the first failure, which is returned as a BulkError.
+// Otherwise every operation commits on its own and its error is reported in
+// its result. Either way the cache is invalidated once for the whole batch and
+// a single undo token covers every operation that committed.
+func (s *TodoService) Bulk(ctx context.Context, req BulkRequest) (results []BulkResult, undo string, err error) {
+
+	if len(req.Operations) > MaxBulkOperations {
+		err = ErrTooManyOperations
+		log.Error().Err(err).Send()
+		return
+	}
+
+	loc := s.userLocation(ctx)
+
+	var steps undoSteps
+
+	if req.Atomic {
+		err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+			results = nil
+
+			for i, op := range req.Operations {
+				todo, err := s.bulkOperation(ctx, q, &steps, op, loc)
+				if err != nil {
+					return &BulkError{Index: i, Err: err}
+				}
+
+				results = append(results, BulkResult{Index: i, Op: op.Op, Task: todo})
+			}
+
+			return
+		})
+
+		if err != nil {
+			results = nil
+			log.Error().Err(err).Send()
+			return
+		}
+	} else {
+		for i, op := range req.Operations {
+			var opSteps undoSteps
+
+			result := BulkResult{Index: i, Op: op.Op}
+
+			result.Err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+				result.Task, err = s.bulkOperation(ctx, q, &opSteps, op, loc)
+				return
+			})
+
+			if result.Err != nil {
+				log.Error().Err(result.Err).Int("index", i).Send()
+				result.Task = repositories.Todo{}
+			} else {
+				steps = append(steps, opSteps...)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	var ids []string
+	for _, result := range results {
+		if result.Err == nil {
+			ids = append(ids, result.Task.ID.String())
+		}
+	}
+
+	if len(ids) > 0 {
+		s.invalidate(ctx, ids...)
+	}
+
+	undo = s.issueUndo(ctx, steps)
+
+	return
+}
+
+func (s *TodoService) bulkOperation(ctx context.Context, q repositories.Querier, steps *undoSteps, op BulkOperation, loc *time.Location) (todo repositories.Todo, err error) {
+
+	if op.Op == BulkCreate {
+		if op.Task == nil {
+			err = errors.New("create needs a task")
+			return
+		}
+
+		params, errP := newTodoParams(ctx, *op.Task, loc)
+		if errP != nil {
+			err = errP
+			return
+		}
+
+		return insertTodo(ctx, q, steps, params)
+	}
+
+	uuidTodo, err := uuid.Parse(op.ID)
+	if err != nil {
+		return
+	}
+
+	id := pgtype.UUID{Valid: true, Bytes: uuidTodo}
+
+	switch op.Op {
+	case BulkUpdate:
+		if op.Fields == nil {
+			err = errors.New("update needs fields")
+			return
+		}
+
+		todo, err = patchTodo(ctx, q, steps, id, *op.Fields, loc)
+	case BulkComplete:
+		todo, err = s.completeTodo(ctx, q, steps, id)
+	case BulkDelete:
+		todo, err = deleteTodo(ctx, q, steps, id)
+	case BulkTag:
+		todo, err = tagTodo(ctx, q, steps, id, op.AddTags, op.RemoveTags)
+	default:
+		err = fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	return
+}
+
+// patchTodo changes the given fields of a task and keeps the others.
+func patchTodo(ctx context.Context, q repositories.Querier, steps *undoSteps, id pgtype.UUID, fields BulkUpdateFields, loc *time.Location) (todo repositories.Todo, err error) {
+
+	before, err := q.GetTodoForUpdate(ctx, id)
+	if err != nil {
+		return
+	}
+
+	params := repositories.SetTodoFieldsParams{
+		ID:             id,
+		Title:          before.Title,
+		Description:    before.Description,
+		Status:         before.Status,
+		DueDate:        before.DueDate,
+		DueAt:          before.DueAt,
+		RecurrenceRule: before.RecurrenceRule,
+		CompletedAt:    before.CompletedAt,
+		Board:          before.Board,
+	}
+
+	if fields.Title != nil {
+		params.Title = *fields.Title
+	}
+
+	if fields.Description != nil {
+		params.Description = pgtype.Text{String: *fields.Description, Valid: true}
+	}
+
+	// like UpdateTodo, a due date without a time clears the time
+	if fields.DueAt != nil {
+		params.DueDate, params.DueAt, err = parseDue("", *fields.DueAt, loc)
+	} else if fields.DueDate != nil {
+		params.DueDate, params.DueAt, err = parseDue(*fields.DueDate, "", loc)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if fields.Board != nil {
+		params.Board = strings.TrimSpace(*fields.Board)
+		if params.Board == "" || len(params.Board) > 64 {
+			err = ErrInvalidBoard
+			return
+		}
+	}
+
+	todo, err = q.SetTodoFields(ctx, params)
+	if err != nil {
+		return
+	}
+
+	err = steps.record(ctx, q, audit.ActionUpdate, id, before, todo)
+	if err != nil {
+		return
+	}
+
+	if todo.Board != before.Board {
+		return todo, enqueue(ctx, q, events.TaskUpdated, id, movedTodo{Todo: todo, PreviousBoard: before.Board})
+	}
+
+	err = enqueue(ctx, q, events.TaskUpdated, id, todo)
+
+	return
+}
@@ -0,0 +1,124 @@
+package todo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"ilcs/internal/repositories"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor returns the cursor of a task: its creation time and id, which
+// is the sort key of ListTodoAfter.
+func encodeCursor(createdAt pgtype.Timestamptz, id pgtype.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Time.UTC().Format(time.RFC3339Nano) + "|" + id.String()))
+}
+
+func decodeCursor(cursor string) (createdAt pgtype.Timestamptz, id pgtype.UUID, err error) {
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		err = ErrInvalidCursor
+		return
+	}
+
+	at, key, ok := strings.Cut(string(data), "|")
+	if !ok {
+		err = ErrInvalidCursor
+		return
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		err = ErrInvalidCursor
+		return
+	}
+
+	parsed, err := uuid.Parse(key)
+	if err != nil {
+		err = ErrInvalidCursor
+		return
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}, pgtype.UUID{Bytes: parsed, Valid: true}, nil
+}
+
+// ListTodosAfter returns the page of tasks after the cursor of req, in the
+// order of GetListTodos. hasNext tells whether more tasks follow the last
+// edge, countData is the number of tasks matching the filters.
+func (s *TodoService) ListTodosAfter(ctx context.Context, req ListTodoAfterRequest) (edges []TodoEdge, hasNext bool, countData int64, err error) {
+
+	first := req.First
+	if first <= 0 {
+		first = 10
+	}
+
+	params := repositories.ListTodoAfterParams{
+		Status:   req.Status,
+		Search:   req.Search,
+		Board:    req.Board,
+		LimitVal: int32(first + 1),
+	}
+
+	if req.After != "" {
+		params.AfterCreatedAt, params.AfterID, err = decodeCursor(req.After)
+		if err != nil {
+			return
+		}
+	}
+
+	data, err := s.repo.ListTodoAfter(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	if len(data) > first {
+		data = data[:first]
+		hasNext = true
+	}
+
+	loc := s.userLocation(ctx)
+	now := time.Now()
+
+	edges = []TodoEdge{}
+
+	for _, item := range data {
+		todo := Todo{
+			ID:             item.ID.String(),
+			Title:          item.Title,
+			Description:    item.Description.String,
+			Status:         string(item.Status),
+			DueDate:        item.DueDate.Time.Format("2006-01-02"),
+			RecurrenceRule: item.RecurrenceRule.String,
+			DueAt:          formatTimestamp(item.DueAt),
+			Board:          item.Board,
+			UpdatedAt:      formatTimestamp(item.UpdatedAt),
+			CompletedAt:    formatTimestamp(item.CompletedAt),
+		}
+
+		localize(&todo, loc, now)
+
+		edges = append(edges, TodoEdge{Cursor: encodeCursor(item.CreatedAt, item.ID), Todo: todo})
+	}
+
+	countData, err = s.repo.CountTodo(ctx, repositories.CountTodoParams{
+		Status: req.Status,
+		Search: req.Search,
+		Board:  req.Board,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
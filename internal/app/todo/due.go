@@ -0,0 +1,107 @@
+package todo
+
+import (
+	"context"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// userLocation returns the time zone configured by the authenticated user,
+// falling back to UTC for anonymous tokens or users without settings.
+func (s *TodoService) userLocation(ctx context.Context) *time.Location {
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		return time.UTC
+	}
+
+	user, err := s.repo.GetUserById(ctx, pgtype.UUID{Valid: true, Bytes: userId})
+	if err != nil {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return time.UTC
+	}
+
+	return loc
+}
+
+// parseDue accepts either a date-only due date or an RFC 3339 due time. When a
+// due time is given, due_date is derived as the calendar day in loc so that
+// date based filtering keeps working.
+func parseDue(dueDate, dueAt string, loc *time.Location) (date pgtype.Date, at pgtype.Timestamptz, err error) {
+
+	if dueAt != "" {
+		t, errP := time.Parse(time.RFC3339, dueAt)
+		if errP != nil {
+			err = errP
+			return
+		}
+
+		local := t.In(loc)
+		date = pgtype.Date{Time: time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC), Valid: true}
+		at = pgtype.Timestamptz{Time: t, Valid: true}
+		return
+	}
+
+	t, err := time.Parse("2006-01-02", dueDate)
+	if err != nil {
+		return
+	}
+
+	date = pgtype.Date{Time: t, Valid: true}
+
+	return
+}
+
+// moveDueAt keeps the local time of day of dueAt while moving it to date.
+func moveDueAt(dueAt pgtype.Timestamptz, date time.Time, loc *time.Location) pgtype.Timestamptz {
+
+	if !dueAt.Valid {
+		return dueAt
+	}
+
+	local := dueAt.Time.In(loc)
+
+	return pgtype.Timestamptz{
+		Time:  time.Date(date.Year(), date.Month(), date.Day(), local.Hour(), local.Minute(), local.Second(), 0, loc),
+		Valid: true,
+	}
+}
+
+// formatTimestamp renders a timestamp in RFC 3339 UTC, or empty when null.
+func formatTimestamp(t pgtype.Timestamptz) string {
+
+	if !t.Valid {
+		return ""
+	}
+
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+// localize renders the due time in loc and computes whether the task is
+// overdue. Date-only tasks become overdue once the due day has passed in loc.
+func localize(todo *Todo, loc *time.Location, now time.Time) {
+
+	todo.Overdue = false
+
+	if todo.DueAt != "" {
+		dueAt, err := time.Parse(time.RFC3339, todo.DueAt)
+		if err == nil {
+			todo.DueAt = dueAt.In(loc).Format(time.RFC3339)
+			todo.Overdue = todo.Status != string(repositories.TodoStatusCompleted) && now.After(dueAt)
+			return
+		}
+	}
+
+	today := now.In(loc).Format("2006-01-02")
+	todo.Overdue = todo.Status != string(repositories.TodoStatusCompleted) && todo.DueDate < today
+}
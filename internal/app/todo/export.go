@@ -0,0 +1,159 @@
+package todo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"ilcs/internal/repositories"
+	"ilcs/internal/xlsx"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrExportFormat = errors.New("format must be csv, ndjson, xlsx, todotxt or markdown")
+
+// exportColumns are the columns of CSV and XLSX exports. They are named after
+// the JSON fields of a task, so an exported CSV file can be imported again.
+var exportColumns = []string{"id", "title", "description", "status", "due_date", "due_at", "recurrence_rule", "board", "overdue", "tags"}
+
+// exportEncoder writes the tasks of an export in one format.
+type exportEncoder interface {
+	encode(todo Todo) error
+	// close writes what the format needs after the last task.
+	close() error
+}
+
+// ExportTodos writes every task matching the filters to w, one at a time as
+// they are read from the database, so the size of an export is not bounded by
+// memory. A failure after the first task leaves w with a truncated export.
+func (s *TodoService) ExportTodos(ctx context.Context, w io.Writer, req ExportRequestParams) (err error) {
+
+	encoder, err := newExportEncoder(w, req.Format)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	loc := s.userLocation(ctx)
+	now := time.Now()
+
+	// the rows are streamed for as long as the client reads them
+	ctx = repositories.WithoutQueryTimeout(ctx)
+
+	params := repositories.ExportTodoParams{
+		Status: req.Status,
+		Search: req.Search,
+		Board:  req.Board,
+	}
+
+	err = s.repo.EachExportTodo(ctx, params, func(item repositories.ExportTodoRow) error {
+		todo := Todo{
+			ID:             item.ID.String(),
+			Title:          item.Title,
+			Description:    item.Description.String,
+			Status:         string(item.Status),
+			DueDate:        item.DueDate.Time.Format("2006-01-02"),
+			RecurrenceRule: item.RecurrenceRule.String,
+			DueAt:          formatTimestamp(item.DueAt),
+			Board:          item.Board,
+			Tags:           item.Tags,
+		}
+
+		localize(&todo, loc, now)
+
+		return encoder.encode(todo)
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	if err = encoder.close(); err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+func newExportEncoder(w io.Writer, format string) (exportEncoder, error) {
+
+	switch format {
+	case ExportCSV:
+		encoder := &csvEncoder{w: csv.NewWriter(w)}
+		return encoder, encoder.w.Write(exportColumns)
+	case ExportNDJSON:
+		return &ndjsonEncoder{w: json.NewEncoder(w)}, nil
+	case ExportXLSX:
+		sheet, err := xlsx.NewWriter(w, "Tasks")
+		if err != nil {
+			return nil, err
+		}
+
+		return &xlsxEncoder{w: sheet}, sheet.WriteRow(exportColumns)
+	case ExportTodoTxt:
+		return &todoTxtEncoder{w: w}, nil
+	case ExportMarkdown:
+		return &markdownEncoder{w: w}, nil
+	default:
+		return nil, ErrExportFormat
+	}
+}
+
+func exportRecord(todo Todo) []string {
+	return []string{
+		todo.ID,
+		todo.Title,
+		todo.Description,
+		todo.Status,
+		todo.DueDate,
+		todo.DueAt,
+		todo.RecurrenceRule,
+		todo.Board,
+		strconv.FormatBool(todo.Overdue),
+		strings.Join(todo.Tags, ","),
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) encode(todo Todo) error {
+	return e.w.Write(exportRecord(todo))
+}
+
+func (e *csvEncoder) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	w *json.Encoder
+}
+
+func (e *ndjsonEncoder) encode(todo Todo) error {
+	return e.w.Encode(todo)
+}
+
+func (e *ndjsonEncoder) close() error {
+	return nil
+}
+
+type xlsxEncoder struct {
+	w *xlsx.Writer
+}
+
+func (e *xlsxEncoder) encode(todo Todo) error {
+	return e.w.WriteRow(exportRecord(todo))
+}
+
+func (e *xlsxEncoder) close() error {
+	return e.w.Close()
+}
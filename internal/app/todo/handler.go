@@ -1,10 +1,18 @@
 package todo
 
 import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"ilcs/internal/utils"
+	"mime"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
 )
 
 type ITodoHandler interface {
@@ -14,6 +22,17 @@ type ITodoHandler interface {
 	UpdateTodo(c *gin.Context)
 	DeleteTodo(c *gin.Context)
 	GetToken(c *gin.Context)
+	PreviewOccurrences(c *gin.Context)
+	SkipOccurrence(c *gin.Context)
+	EndSeries(c *gin.Context)
+	MoveTodo(c *gin.Context)
+	CompleteTodo(c *gin.Context)
+	ListTrash(c *gin.Context)
+	RestoreTodo(c *gin.Context)
+	Undo(c *gin.Context)
+	Bulk(c *gin.Context)
+	ImportTodos(c *gin.Context)
+	ExportTodos(c *gin.Context)
 }
 
 type TodoHandler struct {
@@ -40,13 +59,18 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 		return
 	}
 
-	todo, err := h.service.CreateTodo(c, req)
+	todo, undo, err := h.service.CreateTodo(c, req)
+	if errors.Is(err, ErrInvalidRecurrenceRule) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(201, gin.H{"message": "Task created successfully", "task": todo})
+	c.JSON(201, gin.H{"message": "Task created successfully", "task": todo, "undo_token": undo})
 }
 
 func (h *TodoHandler) ListTodo(c *gin.Context) {
@@ -91,6 +115,11 @@ func (h *TodoHandler) GetTodoById(c *gin.Context) {
 	}
 
 	todo, err := h.service.GetTodo(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Task not found"})
+		return
+	}
+
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
@@ -121,13 +150,23 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 		return
 	}
 
-	todo, err := h.service.UpdateTodo(c, req, id)
+	todo, undo, err := h.service.UpdateTodo(c, req, id)
+	if errors.Is(err, ErrInvalidRecurrenceRule) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Task not found"})
+		return
+	}
+
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Task updated successfully", "task": todo})
+	c.JSON(200, gin.H{"message": "Task updated successfully", "task": todo, "undo_token": undo})
 }
 
 func (h *TodoHandler) DeleteTodo(c *gin.Context) {
@@ -140,13 +179,18 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 
 	}
 
-	err := h.service.DeleteTodo(c, id)
+	undo, err := h.service.DeleteTodo(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Task not found"})
+		return
+	}
+
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Task deleted successfully"})
+	c.JSON(200, gin.H{"message": "Task deleted successfully", "undo_token": undo})
 }
 
 func (h *TodoHandler) GetToken(c *gin.Context) {
@@ -159,3 +203,409 @@ func (h *TodoHandler) GetToken(c *gin.Context) {
 
 	c.JSON(200, gin.H{"token": token})
 }
+
+func (h *TodoHandler) PreviewOccurrences(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req OccurrencesRequestParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	count := 5
+	if req.Count != nil {
+		count = *req.Count
+	}
+
+	dates, err := h.service.PreviewOccurrences(c, id, count)
+	if errors.Is(err, ErrNotRecurring) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"occurrences": dates})
+}
+
+func (h *TodoHandler) SkipOccurrence(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, undo, err := h.service.SkipOccurrence(c, id)
+	if errors.Is(err, ErrNotRecurring) || errors.Is(err, ErrSeriesFinished) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Occurrence skipped successfully", "task": todo, "undo_token": undo})
+}
+
+func (h *TodoHandler) EndSeries(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	undo, err := h.service.EndSeries(c, id)
+	if errors.Is(err, ErrNotRecurring) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Series ended successfully", "undo_token": undo})
+}
+
+func (h *TodoHandler) MoveTodo(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req MoveTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, undo, err := h.service.MoveTodo(c, id, req.Board)
+	if errors.Is(err, ErrInvalidBoard) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Task moved successfully", "task": todo, "undo_token": undo})
+}
+
+func (h *TodoHandler) CompleteTodo(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, undo, err := h.service.CompleteTodo(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Task completed successfully", "task": todo, "undo_token": undo})
+}
+
+func (h *TodoHandler) ListTrash(c *gin.Context) {
+
+	var req ListTrashRequestParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if (req.Page != nil && *req.Page < 1) || (req.Limit != nil && *req.Limit < 1) {
+		c.JSON(400, gin.H{"error": "page and limit must be positive"})
+		return
+	}
+
+	todos, countData, currentPage, currentLimit, err := h.service.ListTrash(c, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	var response struct {
+		Tasks      []Todo `json:"tasks"`
+		Pagination struct {
+			CurrentPage int   `json:"current_page"`
+			TotalPage   int   `json:"total_page"`
+			TotalTasks  int64 `json:"total_tasks"`
+		} `json:"pagination"`
+	}
+
+	response.Tasks = todos
+	response.Pagination.CurrentPage = currentPage
+	response.Pagination.TotalPage = int((countData + int64(currentLimit) - 1) / int64(currentLimit))
+	response.Pagination.TotalTasks = countData
+
+	c.JSON(200, response)
+}
+
+func (h *TodoHandler) RestoreTodo(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, undo, err := h.service.RestoreTodo(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(404, gin.H{"error": "Task not found in trash"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Task restored successfully", "task": todo, "undo_token": undo})
+}
+
+func (h *TodoHandler) Undo(c *gin.Context) {
+
+	token := c.Param("token")
+
+	if err := utils.ValidateId(token); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.service.Undo(c, token)
+	if errors.Is(err, ErrUndoNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, ErrUndoConflict) {
+		c.JSON(409, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Change undone successfully", "tasks": todos})
+}
+
+func (h *TodoHandler) Bulk(c *gin.Context) {
+
+	var req BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, undo, err := h.service.Bulk(c, req)
+
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		code, message := bulkError(bulkErr.Err)
+		c.JSON(code, gin.H{"error": message, "index": bulkErr.Index})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]gin.H, 0, len(results))
+	for _, result := range results {
+		item := gin.H{"index": result.Index, "op": result.Op, "status": 200}
+
+		if result.Err != nil {
+			item["status"], item["error"] = bulkError(result.Err)
+		} else {
+			item["task"] = result.Task
+		}
+
+		items = append(items, item)
+	}
+
+	c.JSON(200, gin.H{"results": items, "undo_token": undo})
+}
+
+// bulkError gives the status and message the single task endpoints would
+// answer an error of a bulk operation with.
+func bulkError(err error) (code int, message string) {
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return 404, "Task not found"
+	case errors.Is(err, ErrInvalidRecurrenceRule), errors.Is(err, ErrInvalidBoard), errors.Is(err, ErrNoTags):
+		return 400, err.Error()
+	default:
+		return 500, err.Error()
+	}
+}
+
+// MaxImportBytes caps the size of an import file.
+const MaxImportBytes = 10 << 20
+
+func (h *TodoHandler) ImportTodos(c *gin.Context) {
+
+	var req ImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = importFormat(c.ContentType())
+	}
+
+	req.Columns = c.QueryMap("columns")
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportBytes)
+
+	report, err := h.service.ImportTodos(c, body, req)
+
+	var tooLarge *http.MaxBytesError
+	var parseErr *csv.ParseError
+
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(413, gin.H{"error": fmt.Sprintf("import file is larger than %d bytes", MaxImportBytes)})
+	case errors.Is(err, ErrImportFormat), errors.Is(err, ErrImportTitle), errors.Is(err, ErrImportField),
+		errors.Is(err, ErrImportTooLong), errors.Is(err, bufio.ErrTooLong), errors.As(err, &parseErr):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(500, gin.H{"error": err.Error()})
+	case len(report.Errors) > 0:
+		c.JSON(422, report)
+	case report.DryRun:
+		c.JSON(200, report)
+	default:
+		c.JSON(201, report)
+	}
+}
+
+// importFormat is the import format of a content type, when the format is not
+// given explicitly.
+func importFormat(contentType string) string {
+
+	switch contentType {
+	case "text/csv", "application/csv":
+		return ImportCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return ImportJSONLines
+	case "text/plain":
+		return ImportTodoTxt
+	case "text/markdown", "text/x-markdown":
+		return ImportMarkdown
+	default:
+		return ""
+	}
+}
+
+// exportTypes are the content types of the export formats.
+var exportTypes = map[string]string{
+	ExportCSV:      "text/csv; charset=utf-8",
+	ExportNDJSON:   "application/x-ndjson",
+	ExportXLSX:     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ExportTodoTxt:  "text/plain; charset=utf-8",
+	ExportMarkdown: "text/markdown; charset=utf-8",
+}
+
+// exportExtensions are the file extensions of the formats not named after
+// theirs.
+var exportExtensions = map[string]string{
+	ExportTodoTxt:  "txt",
+	ExportMarkdown: "md",
+}
+
+func (h *TodoHandler) ExportTodos(c *gin.Context) {
+
+	var req ExportRequestParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	extension, ok := exportExtensions[req.Format]
+	if !ok {
+		extension = req.Format
+	}
+
+	filename := fmt.Sprintf("tasks-%s.%s", time.Now().UTC().Format("2006-01-02"), extension)
+
+	c.Header("Content-Type", exportTypes[req.Format])
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Header("Cache-Control", "no-store")
+
+	err := h.service.ExportTodos(c, c.Writer, req)
+	if err == nil {
+		return
+	}
+
+	// the export fails before its first byte when the query does, it can still
+	// be answered with an error then
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Abort()
+}
@@ -0,0 +1,386 @@
+package todo
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ilcs/internal/audit"
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrImportFormat  = errors.New("format must be csv, jsonl, todotxt or markdown")
+	ErrImportTitle   = errors.New("no column maps to title")
+	ErrImportField   = errors.New("unknown task field")
+	ErrImportTooLong = fmt.Errorf("an import takes at most %d rows", MaxImportRows)
+	ErrImportTags    = fmt.Errorf("a task takes at most %d tags of at most %d characters", maxImportTags, maxTagLength)
+)
+
+const (
+	maxImportTags = 20
+	maxTagLength  = 64
+)
+
+// importFields are the CreateTodoRequest fields columns can map to, by their
+// JSON name.
+var importFields = []string{"title", "description", "due_date", "due_at", "recurrence_rule", "board"}
+
+// importRow is a row of an import file, with the line it starts on. Only
+// todo.txt and Markdown rows can be completed or have tags.
+type importRow struct {
+	line      int
+	fields    map[string]string
+	completed bool
+	tags      []string
+	err       any
+}
+
+// ImportTodos creates the tasks of a CSV, JSON Lines, todo.txt or Markdown
+// file. Every row is validated with the rules of POST /tasks first, and nothing
+// is written unless all of them are valid, so a fixed file can simply be
+// imported again. Tasks, their audit entries and their events are written with
+// COPY in one transaction.
+func (s *TodoService) ImportTodos(ctx context.Context, r io.Reader, req ImportRequest) (report ImportReport, err error) {
+
+	loc := s.userLocation(ctx)
+	now := time.Now()
+	today := now.In(loc).Format("2006-01-02")
+
+	var rows []importRow
+
+	switch req.Format {
+	case ImportCSV:
+		rows, report.IgnoredColumns, err = readCSV(r, req.Columns)
+	case ImportJSONLines:
+		rows, report.IgnoredColumns, err = readJSONLines(r, req.Columns)
+	case ImportTodoTxt:
+		rows, err = readTodoTxt(r, today)
+	case ImportMarkdown:
+		rows, err = readMarkdown(r, today)
+	default:
+		err = ErrImportFormat
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	report.DryRun = req.DryRun
+	report.Rows = len(rows)
+	report.Errors = []ImportError{}
+
+	todos := make([]repositories.CopyTodosParams, 0, len(rows))
+	tags := map[pgtype.UUID][]string{}
+
+	for _, row := range rows {
+		if row.err != nil {
+			report.Errors = append(report.Errors, ImportError{Line: row.line, Error: row.err})
+			continue
+		}
+
+		task := CreateTodoRequest{
+			Title:          row.fields["title"],
+			Description:    row.fields["description"],
+			DueDate:        row.fields["due_date"],
+			DueAt:          row.fields["due_at"],
+			RecurrenceRule: row.fields["recurrence_rule"],
+			Board:          row.fields["board"],
+		}
+
+		if err := binding.Validator.ValidateStruct(task); err != nil {
+			var errs validator.ValidationErrors
+			if errors.As(err, &errs) {
+				report.Errors = append(report.Errors, ImportError{Line: row.line, Error: utils.NewValidationError(errs)})
+			} else {
+				report.Errors = append(report.Errors, ImportError{Line: row.line, Error: err.Error()})
+			}
+
+			continue
+		}
+
+		if len(row.tags) > maxImportTags || slices.ContainsFunc(row.tags, func(tag string) bool { return len(tag) > maxTagLength }) {
+			report.Errors = append(report.Errors, ImportError{Line: row.line, Error: ErrImportTags.Error()})
+			continue
+		}
+
+		params, err := newTodoParams(ctx, task, loc)
+		if err != nil {
+			report.Errors = append(report.Errors, ImportError{Line: row.line, Error: err.Error()})
+			continue
+		}
+
+		status := repositories.TodoStatusPending
+		var completedAt pgtype.Timestamptz
+		if row.completed {
+			status = repositories.TodoStatusCompleted
+			completedAt = pgtype.Timestamptz{Time: now, Valid: true}
+		}
+
+		if len(row.tags) > 0 {
+			tags[params.ID] = row.tags
+		}
+
+		todos = append(todos, repositories.CopyTodosParams{
+			ID:              params.ID,
+			Title:           params.Title,
+			Description:     params.Description,
+			Status:          status,
+			DueDate:         params.DueDate,
+			RecurrenceRule:  params.RecurrenceRule,
+			RecurrenceStart: params.RecurrenceStart,
+			SeriesID:        params.SeriesID,
+			DueAt:           params.DueAt,
+			UserID:          params.UserID,
+			Board:           params.Board,
+			CreatedAt:       pgtype.Timestamptz{Time: now, Valid: true},
+			UpdatedAt:       pgtype.Timestamptz{Time: now, Valid: true},
+			CompletedAt:     completedAt,
+		})
+	}
+
+	if len(report.Errors) > 0 || req.DryRun || len(todos) == 0 {
+		return
+	}
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		report.Imported, err = q.CopyTodos(ctx, todos)
+		if err != nil {
+			return
+		}
+
+		mutations := make([]audit.Mutation, 0, len(todos))
+		created := make([]events.Event, 0, len(todos))
+
+		for _, params := range todos {
+			todo := importedTodo(params)
+
+			var payload any = todo
+			if list, ok := tags[todo.ID]; ok {
+				if _, err = q.AddTodoTags(ctx, repositories.AddTodoTagsParams{TodoID: todo.ID, Tags: list}); err != nil {
+					return
+				}
+
+				payload = taggedTodo{Todo: todo, Tags: list}
+			}
+
+			mutations = append(mutations, audit.Mutation{TaskID: todo.ID, After: payload})
+			created = append(created, events.New(events.TaskCreated, utils.GetUserId(ctx), todo.ID.String(), payload))
+		}
+
+		err = audit.RecordMany(ctx, q, audit.ActionCreate, mutations)
+		if err != nil {
+			return
+		}
+
+		return events.EnqueueMany(ctx, q, created)
+	})
+
+	if err != nil {
+		report.Imported = 0
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// importedTodo is the row CopyTodos writes for params.
+func importedTodo(params repositories.CopyTodosParams) repositories.Todo {
+	return repositories.Todo{
+		ID:              params.ID,
+		Title:           params.Title,
+		Description:     params.Description,
+		Status:          params.Status,
+		DueDate:         params.DueDate,
+		CreatedAt:       params.CreatedAt,
+		UpdatedAt:       params.UpdatedAt,
+		RecurrenceRule:  params.RecurrenceRule,
+		RecurrenceStart: params.RecurrenceStart,
+		SeriesID:        params.SeriesID,
+		DueAt:           params.DueAt,
+		UserID:          params.UserID,
+		CompletedAt:     params.CompletedAt,
+		Board:           params.Board,
+	}
+}
+
+// importColumns maps the columns of a file to task fields. A column is mapped
+// by the columns parameter, or else by its own name when it is a field name.
+// Other columns are ignored and returned so the report can mention them.
+func importColumns(names []string, columns map[string]string) (fields []string, ignored []string, err error) {
+
+	mapping := map[string]string{}
+	for column, field := range columns {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(importFields, field) {
+			err = fmt.Errorf("%w %q for column %q", ErrImportField, field, column)
+			return
+		}
+
+		mapping[strings.ToLower(strings.TrimSpace(column))] = field
+	}
+
+	fields = make([]string, len(names))
+
+	for i, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if field, ok := mapping[name]; ok {
+			fields[i] = field
+		} else if slices.Contains(importFields, name) {
+			fields[i] = name
+		} else {
+			ignored = append(ignored, names[i])
+		}
+	}
+
+	if !slices.Contains(fields, "title") {
+		err = ErrImportTitle
+	}
+
+	return
+}
+
+func readCSV(r io.Reader, columns map[string]string) (rows []importRow, ignored []string, err error) {
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		err = ErrImportTitle
+	}
+
+	if err != nil {
+		return
+	}
+
+	// spreadsheet exports often start with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	fields, ignored, err := importColumns(header, columns)
+	if err != nil {
+		return
+	}
+
+	for {
+		record, errR := reader.Read()
+		if errors.Is(errR, io.EOF) {
+			break
+		}
+
+		if errR != nil {
+			err = errR
+			return
+		}
+
+		if len(rows) == MaxImportRows {
+			err = ErrImportTooLong
+			return
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line, fields: map[string]string{}}
+
+		if len(record) != len(header) {
+			row.err = fmt.Sprintf("expected %d columns, got %d", len(header), len(record))
+		}
+
+		for i, value := range record {
+			if i < len(fields) && fields[i] != "" {
+				row.fields[fields[i]] = strings.TrimSpace(value)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return
+}
+
+func readJSONLines(r io.Reader, columns map[string]string) (rows []importRow, ignored []string, err error) {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	seen := map[string]bool{}
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if len(rows) == MaxImportRows {
+			err = ErrImportTooLong
+			return
+		}
+
+		row := importRow{line: line, fields: map[string]string{}}
+
+		var object map[string]any
+		if errJ := json.Unmarshal([]byte(text), &object); errJ != nil {
+			row.err = errJ.Error()
+			rows = append(rows, row)
+			continue
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+
+		fields, skipped, errC := importColumns(names, columns)
+		if errC != nil && !errors.Is(errC, ErrImportTitle) {
+			err = errC
+			return
+		}
+
+		for _, name := range skipped {
+			if !seen[name] {
+				seen[name] = true
+				ignored = append(ignored, name)
+			}
+		}
+
+		for i, name := range names {
+			if fields[i] == "" {
+				continue
+			}
+
+			switch value := object[name].(type) {
+			case nil:
+			case string:
+				row.fields[fields[i]] = strings.TrimSpace(value)
+			case float64, bool:
+				row.fields[fields[i]] = fmt.Sprint(value)
+			default:
+				row.err = fmt.Sprintf("%s must be a string", name)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	err = scanner.Err()
+
+	return
+}
@@ -0,0 +1,150 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)(\s+#+)?\s*$`)
+	itemPattern    = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+)
+
+// readMarkdown reads the "- [ ]" and "- [x]" items of a Markdown file. A
+// heading names the board of the items below it, indented lines under an item
+// are its description and nested items are tasks of their own. Other lines
+// are skipped, notes can stay in the file.
+func readMarkdown(r io.Reader, today string) (rows []importRow, err error) {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	board := ""
+	line := 0
+
+	// description collects the lines under the last item, until a line that
+	// is not indented
+	var description []string
+	var current *importRow
+
+	finish := func() {
+		if current != nil {
+			current.fields["description"] = strings.TrimSpace(strings.Join(description, "\n"))
+		}
+		current, description = nil, nil
+	}
+
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+
+		if match := itemPattern.FindStringSubmatch(text); match != nil {
+			finish()
+
+			if len(rows) == MaxImportRows {
+				err = ErrImportTooLong
+				return
+			}
+
+			task := parseInline(strings.Fields(match[2]), '#', false)
+			task.board = board
+
+			rows = append(rows, task.row(line, match[1] != " ", today))
+			current = &rows[len(rows)-1]
+
+			continue
+		}
+
+		if match := headingPattern.FindStringSubmatch(text); match != nil {
+			finish()
+			board = match[1]
+			continue
+		}
+
+		switch {
+		case current == nil:
+		case strings.TrimSpace(text) == "":
+			description = append(description, "")
+		case text[0] == ' ' || text[0] == '\t':
+			description = append(description, strings.TrimSpace(text))
+		default:
+			finish()
+		}
+	}
+
+	finish()
+
+	err = scanner.Err()
+
+	return
+}
+
+// formatMarkdown writes a task as a checklist item, followed by its
+// description indented under it.
+func formatMarkdown(todo Todo) string {
+
+	var line strings.Builder
+
+	if todo.Status == "completed" {
+		line.WriteString("- [x] ")
+	} else {
+		line.WriteString("- [ ] ")
+	}
+
+	pri, tags := priority(todo)
+	if pri != "" {
+		line.WriteString("(" + pri + ") ")
+	}
+
+	line.WriteString(todo.Title)
+
+	formatInline(&line, todo, tags, "#")
+
+	line.WriteString("\n")
+
+	if todo.Description != "" {
+		for _, text := range strings.Split(strings.ReplaceAll(todo.Description, "\r\n", "\n"), "\n") {
+			if strings.TrimSpace(text) == "" {
+				line.WriteString("\n")
+			} else {
+				line.WriteString("  " + text + "\n")
+			}
+		}
+	}
+
+	return line.String()
+}
+
+// markdownEncoder writes a heading whenever the board changes, the export
+// query returns the tasks grouped by board.
+type markdownEncoder struct {
+	w       io.Writer
+	board   string
+	started bool
+}
+
+func (e *markdownEncoder) encode(todo Todo) error {
+
+	var out strings.Builder
+
+	if !e.started || todo.Board != e.board {
+		if e.started {
+			out.WriteString("\n")
+		}
+
+		out.WriteString("## " + todo.Board + "\n\n")
+		e.board, e.started = todo.Board, true
+	}
+
+	out.WriteString(formatMarkdown(todo))
+
+	_, err := io.WriteString(e.w, out.String())
+
+	return err
+}
+
+func (e *markdownEncoder) close() error {
+	return nil
+}
@@ -1,17 +1,47 @@
 package todo
 
+import (
+	"errors"
+	"fmt"
+	"ilcs/internal/repositories"
+)
+
+// DefaultBoard is the board of tasks created without one.
+const DefaultBoard = "inbox"
+
+var ErrInvalidBoard = errors.New("board must be between 1 and 64 characters")
+
 type CreateTodoRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description"`
-	DueDate     string `json:"due_date" binding:"required,datetime=2006-01-02"`
+	DueDate     string `json:"due_date" binding:"required_without=DueAt,omitempty,datetime=2006-01-02"`
+	DueAt       string `json:"due_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	// RecurrenceRule is an optional RFC 5545 RRULE, e.g. "FREQ=MONTHLY;BYMONTHDAY=1".
+	RecurrenceRule string `json:"recurrence_rule"`
+	Board          string `json:"board" binding:"omitempty,max=64"`
 }
 
 type Todo struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	DueDate     string `json:"due_date"`
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	Status         string `json:"status"`
+	DueDate        string `json:"due_date"`
+	RecurrenceRule string `json:"recurrence_rule,omitempty"`
+	DueAt          string `json:"due_at,omitempty"`
+	Board          string `json:"board"`
+	Overdue        bool   `json:"overdue"`
+	// DeletedAt is only set on tasks in the trash.
+	DeletedAt string   `json:"deleted_at,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	UpdatedAt string   `json:"updated_at,omitempty"`
+	// CompletedAt is only set on completed tasks.
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+type ListTrashRequestParams struct {
+	Page  *int `form:"page"`
+	Limit *int `form:"limit"`
 }
 
 type ListTodoRequestParams struct {
@@ -19,11 +49,152 @@ type ListTodoRequestParams struct {
 	Limit  *int    `form:"limit"`
 	Status *string `form:"status"`
 	Search *string `form:"search"`
+	Board  *string `form:"board"`
+}
+
+// ListTodoAfterRequest pages through the tasks with a cursor instead of a
+// page number. After is the cursor of the last task of the previous page.
+type ListTodoAfterRequest struct {
+	First  int
+	After  string
+	Status *string
+	Search *string
+	Board  *string
+}
+
+// TodoEdge is a task of a cursor page, with the cursor to continue after it.
+type TodoEdge struct {
+	Cursor string
+	Todo   Todo
 }
 
 type UpdateTodoRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description"`
 	Status      string `json:"status" binding:"required,oneof=pending completed"`
-	DueDate     string `json:"due_date" binding:"required,datetime=2006-01-02"`
+	DueDate     string `json:"due_date" binding:"required_without=DueAt,omitempty,datetime=2006-01-02"`
+	DueAt       string `json:"due_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	// RecurrenceRule replaces the rule of the series when set; use the end-series endpoint to stop it.
+	RecurrenceRule *string `json:"recurrence_rule"`
+}
+
+type OccurrencesRequestParams struct {
+	Count *int `form:"count" binding:"omitempty,min=1,max=100"`
+}
+
+type MoveTodoRequest struct {
+	Board string `json:"board" binding:"required,max=64"`
+}
+
+// MaxBulkOperations caps the number of operations of one bulk request.
+const MaxBulkOperations = 500
+
+const (
+	BulkCreate   = "create"
+	BulkUpdate   = "update"
+	BulkComplete = "complete"
+	BulkDelete   = "delete"
+	BulkTag      = "tag"
+)
+
+type BulkRequest struct {
+	// Atomic runs every operation in one transaction, the first failure rolls
+	// back the whole batch.
+	Atomic     bool            `json:"atomic"`
+	Operations []BulkOperation `json:"operations" binding:"required,min=1,max=500,dive"`
+}
+
+type BulkOperation struct {
+	Op string `json:"op" binding:"required,oneof=create update complete delete tag"`
+	// ID is the task of every operation but create.
+	ID string `json:"id" binding:"required_unless=Op create,omitempty,uuid"`
+	// Task is the task a create inserts.
+	Task *CreateTodoRequest `json:"task" binding:"required_if=Op create,omitempty"`
+	// Fields are the fields an update changes, the others keep their value.
+	Fields     *BulkUpdateFields `json:"fields" binding:"required_if=Op update,omitempty"`
+	AddTags    []string          `json:"add_tags" binding:"omitempty,max=20,dive,min=1,max=64"`
+	RemoveTags []string          `json:"remove_tags" binding:"omitempty,max=20,dive,min=1,max=64"`
+}
+
+type BulkUpdateFields struct {
+	Title       *string `json:"title" binding:"omitempty,min=1"`
+	Description *string `json:"description"`
+	DueDate     *string `json:"due_date" binding:"omitempty,datetime=2006-01-02"`
+	DueAt       *string `json:"due_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Board       *string `json:"board" binding:"omitempty,max=64"`
+}
+
+// BulkResult is the outcome of one operation of a bulk request.
+type BulkResult struct {
+	Index int
+	Op    string
+	Task  repositories.Todo
+	Err   error
+}
+
+// BulkError reports the operation that rolled back an atomic bulk request.
+type BulkError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Err)
+}
+
+func (e *BulkError) Unwrap() error {
+	return e.Err
+}
+
+// MaxImportRows caps the number of tasks of one import.
+const MaxImportRows = 10000
+
+const (
+	ImportCSV       = "csv"
+	ImportJSONLines = "jsonl"
+	ImportTodoTxt   = "todotxt"
+	ImportMarkdown  = "markdown"
+)
+
+type ImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl todotxt markdown"`
+	// DryRun validates the file and reports its errors without importing it.
+	DryRun bool `form:"dry_run"`
+	// Columns maps the columns of the file to task fields, columns named after
+	// a field need no mapping. Only CSV and JSON Lines files have columns.
+	Columns map[string]string `form:"-"`
+}
+
+// ImportReport is the outcome of an import. Nothing is imported when Errors is
+// not empty.
+type ImportReport struct {
+	DryRun         bool          `json:"dry_run"`
+	Rows           int           `json:"rows"`
+	Imported       int64         `json:"imported"`
+	IgnoredColumns []string      `json:"ignored_columns,omitempty"`
+	Errors         []ImportError `json:"errors"`
+}
+
+// ImportError is the reason a row of an import is invalid, either a message or
+// the validation errors of its fields.
+type ImportError struct {
+	Line  int `json:"line"`
+	Error any `json:"error"`
+}
+
+const (
+	ExportCSV      = "csv"
+	ExportNDJSON   = "ndjson"
+	ExportXLSX     = "xlsx"
+	ExportTodoTxt  = "todotxt"
+	ExportMarkdown = "markdown"
+)
+
+// ExportRequestParams takes the filters of ListTodoRequestParams, an export
+// always holds every matching task.
+type ExportRequestParams struct {
+	Format string  `form:"format" binding:"required,oneof=csv ndjson xlsx todotxt markdown"`
+	Status *string `form:"status"`
+	Search *string `form:"search"`
+	Board  *string `form:"board"`
 }
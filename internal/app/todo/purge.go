@@ -0,0 +1,89 @@
+package todo
+
+import (
+	"context"
+	"ilcs/internal/audit"
+	"ilcs/internal/repositories"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// PurgeJob permanently deletes tasks that have been in the trash longer than
+// the retention period. Tasks are claimed with FOR UPDATE SKIP LOCKED, so
+// several workers can run side by side.
+type PurgeJob struct {
+	tx        repositories.Transactor
+	retention time.Duration
+	interval  time.Duration
+	batch     int32
+}
+
+func NewPurgeJob(tx repositories.Transactor, retention, interval time.Duration) *PurgeJob {
+	return &PurgeJob{
+		tx:        tx,
+		retention: retention,
+		interval:  interval,
+		batch:     100,
+	}
+}
+
+func (j *PurgeJob) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := j.Tick(ctx, time.Now())
+		if err != nil {
+			log.Error().Err(err).Send()
+		} else if purged > 0 {
+			log.Info().Int("purged", purged).Msg("Trashed tasks purged")
+		}
+
+		// keep going while the trash is backed up
+		if err == nil && purged == int(j.batch) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick purges one batch of expired tasks and reports how many were deleted.
+func (j *PurgeJob) Tick(ctx context.Context, now time.Time) (purged int, err error) {
+
+	err = j.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+
+		todos, err := q.PurgeTrashedTodos(ctx, repositories.PurgeTrashedTodosParams{
+			DeletedBefore: pgtype.Timestamptz{Time: now.Add(-j.retention), Valid: true},
+			LimitVal:      j.batch,
+		})
+
+		if err != nil {
+			return
+		}
+
+		for _, todo := range todos {
+			_, err = audit.Record(ctx, q, audit.ActionPurge, todo.ID, todo, nil)
+			if err != nil {
+				return
+			}
+		}
+
+		purged = len(todos)
+
+		return
+	})
+
+	if err != nil {
+		purged = 0
+	}
+
+	return
+}
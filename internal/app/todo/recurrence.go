@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var (
+	ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+	ErrNotRecurring          = errors.New("task is not recurring")
+	ErrSeriesFinished        = errors.New("series has no further occurrences")
+)
+
+// parseRecurrenceRule parses an RFC 5545 RRULE (with or without the "RRULE:"
+// prefix) anchored at the first due date of the series.
+func parseRecurrenceRule(rule string, start time.Time) (*rrule.RRule, error) {
+
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+
+	opt, err := rrule.StrToROption(rule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrenceRule, err)
+	}
+
+	// due dates have day precision, so sub-daily frequencies make no sense
+	if opt.Freq > rrule.DAILY {
+		return nil, fmt.Errorf("%w: frequency must be DAILY or longer", ErrInvalidRecurrenceRule)
+	}
+
+	opt.Dtstart = start
+
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrenceRule, err)
+	}
+
+	return r, nil
+}
+
+// nextOccurrences returns up to count occurrences strictly after the given date.
+func nextOccurrences(r *rrule.RRule, after time.Time, count int) []time.Time {
+
+	var dates []time.Time
+
+	next := r.Iterator()
+	for len(dates) < count {
+		date, ok := next()
+		if !ok {
+			break
+		}
+
+		if date.After(after) {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
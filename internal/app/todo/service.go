@@ -5,43 +5,72 @@ import (
 	"encoding/json"
 	"errors"
 	"ilcs/database"
+	"ilcs/internal/audit"
 	"ilcs/internal/constants"
+	"ilcs/internal/events"
 	"ilcs/internal/repositories"
-	"os"
+	"ilcs/internal/utils"
+	"io"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
+	"github.com/teambition/rrule-go"
 )
 
 type ITodoService interface {
-	CreateTodo(ctx context.Context, req CreateTodoRequest) (todo repositories.Todo, err error)
+	CreateTodo(ctx context.Context, req CreateTodoRequest) (todo repositories.Todo, undo string, err error)
+	CreateTodoIn(ctx context.Context, q repositories.Querier, req CreateTodoRequest, completed bool) (todo repositories.Todo, err error)
 	GetListTodos(ctx context.Context, req ListTodoRequestParams) (todos []Todo, countData int64, page, limit int, err error)
+	ListTodosAfter(ctx context.Context, req ListTodoAfterRequest) (edges []TodoEdge, hasNext bool, countData int64, err error)
 	GetTodo(ctx context.Context, id string) (todo Todo, err error)
-	UpdateTodo(ctx context.Context, req UpdateTodoRequest, id string) (todo repositories.Todo, err error)
-	DeleteTodo(ctx context.Context, id string) (err error)
+	UpdateTodo(ctx context.Context, req UpdateTodoRequest, id string) (todo repositories.Todo, undo string, err error)
+	DeleteTodo(ctx context.Context, id string) (undo string, err error)
 	GetToken(ctx context.Context) (token string, err error)
+	PreviewOccurrences(ctx context.Context, id string, count int) (dates []string, err error)
+	SkipOccurrence(ctx context.Context, id string) (todo repositories.Todo, undo string, err error)
+	EndSeries(ctx context.Context, id string) (undo string, err error)
+	MoveTodo(ctx context.Context, id, board string) (todo repositories.Todo, undo string, err error)
+	CompleteTodo(ctx context.Context, id string) (todo repositories.Todo, undo string, err error)
+	ListTrash(ctx context.Context, req ListTrashRequestParams) (todos []Todo, countData int64, page, limit int, err error)
+	RestoreTodo(ctx context.Context, id string) (todo repositories.Todo, undo string, err error)
+	Undo(ctx context.Context, token string) (todos []repositories.Todo, err error)
+	Bulk(ctx context.Context, req BulkRequest) (results []BulkResult, undo string, err error)
+	ImportTodos(ctx context.Context, r io.Reader, req ImportRequest) (report ImportReport, err error)
+	ExportTodos(ctx context.Context, w io.Writer, req ExportRequestParams) (err error)
+}
+
+// TodoRepository is the queries of the service, with the cursors exports
+// stream their rows from.
+type TodoRepository interface {
+	repositories.Querier
+	repositories.Cursor
 }
 
 type TodoService struct {
-	repo    repositories.Querier
+	repo    TodoRepository
 	redisDb database.RedisClient
+	tx      repositories.Transactor
+	tokens  *utils.Tokens
 }
 
-func NewTodoService(repo repositories.Querier, redisDb database.RedisClient) *TodoService {
+func NewTodoService(repo TodoRepository, redisDb database.RedisClient, tx repositories.Transactor, tokens *utils.Tokens) *TodoService {
 	return &TodoService{
 		repo:    repo,
 		redisDb: redisDb,
+		tx:      tx,
+		tokens:  tokens,
 	}
 }
 
-func (s *TodoService) CreateTodo(ctx context.Context, req CreateTodoRequest) (todo repositories.Todo, err error) {
+func (s *TodoService) CreateTodo(ctx context.Context, req CreateTodoRequest) (todo repositories.Todo, undo string, err error) {
 
 	var wg sync.WaitGroup
+	var steps undoSteps
 
 	errChan := make(chan error, 1)
 	todoChan := make(chan repositories.Todo, 1)
@@ -50,25 +79,21 @@ func (s *TodoService) CreateTodo(ctx context.Context, req CreateTodoRequest) (to
 	go func(ctx context.Context, req CreateTodoRequest) {
 		defer wg.Done()
 
-		id, err := uuid.NewV7()
-		if err != nil {
-			log.Error().Err(err).Send()
-			errChan <- err
-			return
+		loc := time.UTC
+		if req.DueAt != "" {
+			loc = s.userLocation(ctx)
 		}
 
-		timeDate, err := time.Parse("2006-01-02", req.DueDate)
+		params, err := newTodoParams(ctx, req, loc)
 		if err != nil {
 			log.Error().Err(err).Send()
 			errChan <- err
 			return
 		}
 
-		todo, err = s.repo.InsertTodo(ctx, repositories.InsertTodoParams{
-			ID:          pgtype.UUID{Bytes: id, Valid: true},
-			Title:       req.Title,
-			Description: pgtype.Text{String: req.Description, Valid: true},
-			DueDate:     pgtype.Date{Time: timeDate, Valid: true},
+		err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+			todo, err = insertTodo(ctx, q, &steps, params)
+			return
 		})
 
 		if err != nil {
@@ -90,7 +115,7 @@ func (s *TodoService) CreateTodo(ctx context.Context, req CreateTodoRequest) (to
 			return
 		}
 	case todo = <-todoChan:
-		return todo, nil
+		return todo, s.issueUndo(ctx, steps), nil
 	case <-ctx.Done():
 		err = ctx.Err()
 		log.Error().Err(err).Send()
@@ -103,6 +128,91 @@ func (s *TodoService) CreateTodo(ctx context.Context, req CreateTodoRequest) (to
 	return
 }
 
+// CreateTodoIn creates a task with the queries of a transaction the caller
+// runs, so what refers to the task is written along with it. The task is
+// completed right away when completed is set. No undo token is issued.
+func (s *TodoService) CreateTodoIn(ctx context.Context, q repositories.Querier, req CreateTodoRequest, completed bool) (todo repositories.Todo, err error) {
+
+	loc := time.UTC
+	if req.DueAt != "" {
+		loc = s.userLocation(ctx)
+	}
+
+	params, err := newTodoParams(ctx, req, loc)
+	if err != nil {
+		return
+	}
+
+	var steps undoSteps
+
+	todo, err = insertTodo(ctx, q, &steps, params)
+	if err != nil || !completed {
+		return
+	}
+
+	return s.completeTodo(ctx, q, &steps, todo.ID)
+}
+
+// newTodoParams validates a new task, due times are read in loc.
+func newTodoParams(ctx context.Context, req CreateTodoRequest, loc *time.Location) (params repositories.InsertTodoParams, err error) {
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return
+	}
+
+	dueDate, dueAt, err := parseDue(req.DueDate, req.DueAt, loc)
+	if err != nil {
+		return
+	}
+
+	params = repositories.InsertTodoParams{
+		ID:          pgtype.UUID{Bytes: id, Valid: true},
+		Title:       req.Title,
+		Description: pgtype.Text{String: req.Description, Valid: true},
+		DueDate:     dueDate,
+		DueAt:       dueAt,
+		Board:       DefaultBoard,
+	}
+
+	if req.Board != "" {
+		params.Board = req.Board
+	}
+
+	if userId, err := uuid.Parse(utils.GetUserId(ctx)); err == nil {
+		params.UserID = pgtype.UUID{Bytes: userId, Valid: true}
+	}
+
+	if req.RecurrenceRule != "" {
+		if _, err = parseRecurrenceRule(req.RecurrenceRule, dueDate.Time); err != nil {
+			return
+		}
+
+		params.RecurrenceRule = pgtype.Text{String: req.RecurrenceRule, Valid: true}
+		params.RecurrenceStart = params.DueDate
+		params.SeriesID = params.ID
+	}
+
+	return
+}
+
+func insertTodo(ctx context.Context, q repositories.Querier, steps *undoSteps, params repositories.InsertTodoParams) (todo repositories.Todo, err error) {
+
+	todo, err = q.InsertTodo(ctx, params)
+	if err != nil {
+		return
+	}
+
+	err = steps.record(ctx, q, audit.ActionCreate, params.ID, nil, todo)
+	if err != nil {
+		return
+	}
+
+	err = enqueue(ctx, q, events.TaskCreated, params.ID, todo)
+
+	return
+}
+
 func (s *TodoService) GetListTodos(ctx context.Context, req ListTodoRequestParams) (todos []Todo, countData int64, page, limit int, err error) {
 
 	if req.Page == nil {
@@ -137,6 +247,7 @@ func (s *TodoService) GetListTodos(ctx context.Context, req ListTodoRequestParam
 		LimitVal: int32(*req.Limit),
 		Status:   status,
 		Search:   search,
+		Board:    req.Board,
 	}
 
 	data, err := s.repo.ListTodo(ctx, params)
@@ -146,21 +257,32 @@ func (s *TodoService) GetListTodos(ctx context.Context, req ListTodoRequestParam
 		return
 	}
 
+	loc := s.userLocation(ctx)
+	now := time.Now()
+
 	for _, item := range data {
 		todo := Todo{
-			ID:          item.ID.String(),
-			Title:       item.Title,
-			Description: item.Description.String,
-			Status:      string(item.Status),
-			DueDate:     item.DueDate.Time.Format("2006-01-02"),
+			ID:             item.ID.String(),
+			Title:          item.Title,
+			Description:    item.Description.String,
+			Status:         string(item.Status),
+			DueDate:        item.DueDate.Time.Format("2006-01-02"),
+			RecurrenceRule: item.RecurrenceRule.String,
+			DueAt:          formatTimestamp(item.DueAt),
+			Board:          item.Board,
+			UpdatedAt:      formatTimestamp(item.UpdatedAt),
+			CompletedAt:    formatTimestamp(item.CompletedAt),
 		}
 
+		localize(&todo, loc, now)
+
 		todos = append(todos, todo)
 	}
 
 	countData, err = s.repo.CountTodo(ctx, repositories.CountTodoParams{
 		Status: status,
 		Search: search,
+		Board:  req.Board,
 	})
 
 	if err != nil {
@@ -171,6 +293,66 @@ func (s *TodoService) GetListTodos(ctx context.Context, req ListTodoRequestParam
 	return
 }
 
+// ListTrash returns the trashed tasks of the caller, most recently deleted
+// first.
+func (s *TodoService) ListTrash(ctx context.Context, req ListTrashRequestParams) (todos []Todo, countData int64, page, limit int, err error) {
+
+	page, limit = 1, 10
+
+	// tasks without an owner are nobody's trash
+	userId := callerId(ctx)
+
+	if req.Page != nil {
+		page = *req.Page
+	}
+
+	if req.Limit != nil {
+		limit = *req.Limit
+	}
+
+	data, err := s.repo.ListTrash(ctx, repositories.ListTrashParams{
+		UserID:   userId,
+		Page:     int32(page),
+		LimitVal: int32(limit),
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	loc := s.userLocation(ctx)
+	now := time.Now()
+
+	todos = []Todo{}
+
+	for _, item := range data {
+		todo := Todo{
+			ID:             item.ID.String(),
+			Title:          item.Title,
+			Description:    item.Description.String,
+			Status:         string(item.Status),
+			DueDate:        item.DueDate.Time.Format("2006-01-02"),
+			RecurrenceRule: item.RecurrenceRule.String,
+			DueAt:          formatTimestamp(item.DueAt),
+			Board:          item.Board,
+			DeletedAt:      item.DeletedAt.Time.UTC().Format(time.RFC3339),
+		}
+
+		localize(&todo, loc, now)
+
+		todos = append(todos, todo)
+	}
+
+	countData, err = s.repo.CountTrash(ctx, userId)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
 func (s *TodoService) GetTodo(ctx context.Context, id string) (todo Todo, err error) {
 
 	key := constants.CACHE_KEY + id
@@ -192,11 +374,23 @@ func (s *TodoService) GetTodo(ctx context.Context, id string) (todo Todo, err er
 		}
 
 		todo = Todo{
-			ID:          data.ID.String(),
-			Title:       data.Title,
-			Description: data.Description.String,
-			Status:      string(data.Status),
-			DueDate:     data.DueDate.Time.Format("2006-01-02"),
+			ID:             data.ID.String(),
+			Title:          data.Title,
+			Description:    data.Description.String,
+			Status:         string(data.Status),
+			DueDate:        data.DueDate.Time.Format("2006-01-02"),
+			RecurrenceRule: data.RecurrenceRule.String,
+			DueAt:          formatTimestamp(data.DueAt),
+			Board:          data.Board,
+			UpdatedAt:      formatTimestamp(data.UpdatedAt),
+			CompletedAt:    formatTimestamp(data.CompletedAt),
+		}
+
+		todo.Tags, errG = s.repo.ListTodoTags(ctx, data.ID)
+		if errG != nil {
+			log.Error().Err(errG).Send()
+			err = errG
+			return
 		}
 
 		dataByte, errG := json.Marshal(todo)
@@ -224,13 +418,20 @@ func (s *TodoService) GetTodo(ctx context.Context, id string) (todo Todo, err er
 		}
 	}
 
+	localize(&todo, s.userLocation(ctx), time.Now())
+
 	return
 
 }
 
-func (s *TodoService) UpdateTodo(ctx context.Context, req UpdateTodoRequest, id string) (todo repositories.Todo, err error) {
+func (s *TodoService) UpdateTodo(ctx context.Context, req UpdateTodoRequest, id string) (todo repositories.Todo, undo string, err error) {
+
+	loc := time.UTC
+	if req.DueAt != "" {
+		loc = s.userLocation(ctx)
+	}
 
-	timeDate, err := time.Parse("2006-01-02", req.DueDate)
+	dueDate, dueAt, err := parseDue(req.DueDate, req.DueAt, loc)
 	if err != nil {
 		log.Error().Err(err).Send()
 		return
@@ -242,12 +443,39 @@ func (s *TodoService) UpdateTodo(ctx context.Context, req UpdateTodoRequest, id
 		return
 	}
 
-	todo, err = s.repo.UpdateTodo(ctx, repositories.UpdateTodoParams{
-		ID:          pgtype.UUID{Valid: true, Bytes: uuidTodo},
-		Title:       req.Title,
-		Description: pgtype.Text{String: req.Description, Valid: true},
-		Status:      repositories.TodoStatus(req.Status),
-		DueDate:     pgtype.Date{Time: timeDate, Valid: true},
+	var recurrenceRule pgtype.Text
+	if req.RecurrenceRule != nil && *req.RecurrenceRule != "" {
+		if _, err = parseRecurrenceRule(*req.RecurrenceRule, dueDate.Time); err != nil {
+			log.Error().Err(err).Send()
+			return
+		}
+
+		recurrenceRule = pgtype.Text{String: *req.RecurrenceRule, Valid: true}
+	}
+
+	var steps undoSteps
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		before, err := q.GetTodoForUpdate(ctx, pgtype.UUID{Valid: true, Bytes: uuidTodo})
+		if err != nil {
+			return
+		}
+
+		todo, err = q.UpdateTodo(ctx, repositories.UpdateTodoParams{
+			ID:             pgtype.UUID{Valid: true, Bytes: uuidTodo},
+			Title:          req.Title,
+			Description:    pgtype.Text{String: req.Description, Valid: true},
+			Status:         repositories.TodoStatus(req.Status),
+			DueDate:        dueDate,
+			DueAt:          dueAt,
+			RecurrenceRule: recurrenceRule,
+		})
+
+		if err != nil {
+			return
+		}
+
+		return s.afterUpdate(ctx, q, &steps, before, todo)
 	})
 
 	if err != nil {
@@ -255,10 +483,14 @@ func (s *TodoService) UpdateTodo(ctx context.Context, req UpdateTodoRequest, id
 		return
 	}
 
+	s.invalidate(ctx, id)
+
+	undo = s.issueUndo(ctx, steps)
+
 	return
 }
 
-func (s *TodoService) DeleteTodo(ctx context.Context, id string) (err error) {
+func (s *TodoService) CompleteTodo(ctx context.Context, id string) (todo repositories.Todo, undo string, err error) {
 
 	uuidTodo, err := uuid.Parse(id)
 	if err != nil {
@@ -266,23 +498,483 @@ func (s *TodoService) DeleteTodo(ctx context.Context, id string) (err error) {
 		return
 	}
 
-	err = s.repo.DeleteTodo(ctx, pgtype.UUID{Valid: true, Bytes: uuidTodo})
+	var steps undoSteps
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		todo, err = s.completeTodo(ctx, q, &steps, pgtype.UUID{Valid: true, Bytes: uuidTodo})
+		return
+	})
 
 	if err != nil {
 		log.Error().Err(err).Send()
 		return
 	}
 
+	s.invalidate(ctx, id)
+
+	undo = s.issueUndo(ctx, steps)
+
 	return
 }
 
-func (s *TodoService) GetToken(ctx context.Context) (token string, err error) {
+func (s *TodoService) completeTodo(ctx context.Context, q repositories.Querier, steps *undoSteps, id pgtype.UUID) (todo repositories.Todo, err error) {
+
+	before, err := q.GetTodoForUpdate(ctx, id)
+	if err != nil {
+		return
+	}
+
+	todo, err = q.CompleteTodo(ctx, id)
+	if err != nil {
+		return
+	}
+
+	err = s.afterUpdate(ctx, q, steps, before, todo)
+
+	return
+}
+
+// afterUpdate records the events of an updated task and, when the update
+// completed an occurrence of a series, schedules the next one.
+func (s *TodoService) afterUpdate(ctx context.Context, q repositories.Querier, steps *undoSteps, before, todo repositories.Todo) (err error) {
+
+	err = steps.record(ctx, q, audit.ActionUpdate, todo.ID, before, todo)
+	if err != nil {
+		return
+	}
+
+	err = enqueue(ctx, q, events.TaskUpdated, todo.ID, todo)
+	if err != nil {
+		return
+	}
+
+	// completed_at is only stamped by the update that completes the task, later
+	// edits of a completed occurrence schedule nothing
+	if !todo.CompletedAt.Valid || !todo.CompletedAt.Time.Equal(todo.UpdatedAt.Time) {
+		return
+	}
+
+	err = enqueue(ctx, q, events.TaskCompleted, todo.ID, todo)
+	if err != nil {
+		return
+	}
+
+	if todo.RecurrenceRule.Valid {
+		err = s.scheduleNextOccurrence(ctx, q, steps, todo)
+	}
+
+	return
+}
+
+// movedTodo is the payload of the event of a task moved between boards.
+type movedTodo struct {
+	repositories.Todo
+	PreviousBoard string `json:"previous_board"`
+}
+
+func (s *TodoService) MoveTodo(ctx context.Context, id, board string) (todo repositories.Todo, undo string, err error) {
+
+	board = strings.TrimSpace(board)
+	if board == "" || len(board) > 64 {
+		err = ErrInvalidBoard
+		log.Error().Err(err).Send()
+		return
+	}
+
+	uuidTodo, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	var steps undoSteps
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		row, err := q.MoveTodo(ctx, repositories.MoveTodoParams{
+			ID:    pgtype.UUID{Valid: true, Bytes: uuidTodo},
+			Board: board,
+		})
 
-	claimsJwt := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+		if err != nil {
+			return
+		}
+
+		todo = repositories.Todo{
+			ID:              row.ID,
+			Title:           row.Title,
+			Description:     row.Description,
+			Status:          row.Status,
+			DueDate:         row.DueDate,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+			RecurrenceRule:  row.RecurrenceRule,
+			RecurrenceStart: row.RecurrenceStart,
+			SeriesID:        row.SeriesID,
+			DueAt:           row.DueAt,
+			UserID:          row.UserID,
+			CompletedAt:     row.CompletedAt,
+			Board:           row.Board,
+		}
+
+		err = steps.record(ctx, q, audit.ActionUpdate, todo.ID, map[string]string{"board": row.PreviousBoard}, map[string]string{"board": row.Board})
+		if err != nil {
+			return
+		}
+
+		return enqueue(ctx, q, events.TaskUpdated, todo.ID, movedTodo{Todo: todo, PreviousBoard: row.PreviousBoard})
 	})
 
-	token, err = claimsJwt.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	s.invalidate(ctx, id)
+
+	undo = s.issueUndo(ctx, steps)
+
+	return
+}
+
+// invalidate drops the cached copies GetTodo keeps of tasks, one key at a
+// time since the keys may live on different nodes of a Redis cluster.
+func (s *TodoService) invalidate(ctx context.Context, ids ...string) {
+
+	for _, id := range ids {
+		if err := s.redisDb.Del(ctx, constants.CACHE_KEY+id).Err(); err != nil {
+			log.Error().Err(err).Send()
+		}
+	}
+}
+
+// enqueue records a task event in the outbox using the queries of the
+// transaction that changed the task.
+func enqueue(ctx context.Context, q repositories.Querier, eventType string, taskId pgtype.UUID, data any) error {
+	return events.Enqueue(ctx, q, events.New(eventType, utils.GetUserId(ctx), taskId.String(), data))
+}
+
+// scheduleNextOccurrence inserts the occurrence following a completed task of a
+// series. It is idempotent: completing the same occurrence twice does not
+// create a duplicate thanks to the unique (series_id, due_date) index.
+func (s *TodoService) scheduleNextOccurrence(ctx context.Context, q repositories.Querier, steps *undoSteps, todo repositories.Todo) (err error) {
+
+	seriesID := todo.SeriesID
+	if !seriesID.Valid {
+		seriesID = todo.ID
+	}
+
+	start := todo.RecurrenceStart
+	if !start.Valid {
+		start = todo.DueDate
+	}
+
+	rule, err := parseRecurrenceRule(todo.RecurrenceRule.String, start.Time)
+	if err != nil {
+		return
+	}
+
+	next := rule.After(todo.DueDate.Time, false)
+	if next.IsZero() {
+		return
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return
+	}
+
+	params := repositories.InsertTodoOccurrenceParams{
+		ID:              pgtype.UUID{Bytes: id, Valid: true},
+		Title:           todo.Title,
+		Description:     todo.Description,
+		DueDate:         pgtype.Date{Time: next, Valid: true},
+		DueAt:           moveDueAt(todo.DueAt, next, s.userLocation(ctx)),
+		RecurrenceRule:  todo.RecurrenceRule,
+		RecurrenceStart: start,
+		SeriesID:        seriesID,
+		UserID:          todo.UserID,
+		Board:           todo.Board,
+	}
+
+	// the occurrence may already exist when the task is completed twice
+	inserted, err := q.InsertTodoOccurrence(ctx, params)
+	if err != nil || inserted == 0 {
+		return
+	}
+
+	return steps.record(ctx, q, audit.ActionCreate, params.ID, nil, params)
+}
+
+func (s *TodoService) getRecurrence(ctx context.Context, id string) (data repositories.GetTodoByIdRow, rule *rrule.RRule, err error) {
+
+	uuidTodo, err := uuid.Parse(id)
+	if err != nil {
+		return
+	}
+
+	data, err = s.repo.GetTodoById(ctx, pgtype.UUID{Valid: true, Bytes: uuidTodo})
+	if err != nil {
+		return
+	}
+
+	if !data.RecurrenceRule.Valid {
+		err = ErrNotRecurring
+		return
+	}
+
+	start := data.RecurrenceStart
+	if !start.Valid {
+		start = data.DueDate
+	}
+
+	rule, err = parseRecurrenceRule(data.RecurrenceRule.String, start.Time)
+
+	return
+}
+
+func (s *TodoService) PreviewOccurrences(ctx context.Context, id string, count int) (dates []string, err error) {
+
+	data, rule, err := s.getRecurrence(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	dates = []string{}
+	for _, date := range nextOccurrences(rule, data.DueDate.Time, count) {
+		dates = append(dates, date.Format("2006-01-02"))
+	}
+
+	return
+}
+
+func (s *TodoService) SkipOccurrence(ctx context.Context, id string) (todo repositories.Todo, undo string, err error) {
+
+	data, rule, err := s.getRecurrence(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	next := rule.After(data.DueDate.Time, false)
+	if next.IsZero() {
+		err = ErrSeriesFinished
+		log.Error().Err(err).Send()
+		return
+	}
+
+	loc := s.userLocation(ctx)
+
+	var steps undoSteps
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		before, err := q.GetTodoForUpdate(ctx, data.ID)
+		if err != nil {
+			return
+		}
+
+		todo, err = q.UpdateTodoDueDate(ctx, repositories.UpdateTodoDueDateParams{
+			ID:      data.ID,
+			DueDate: pgtype.Date{Time: next, Valid: true},
+			DueAt:   moveDueAt(data.DueAt, next, loc),
+		})
+
+		if err != nil {
+			return
+		}
+
+		err = steps.record(ctx, q, audit.ActionUpdate, todo.ID, before, todo)
+		if err != nil {
+			return
+		}
+
+		return enqueue(ctx, q, events.TaskUpdated, todo.ID, todo)
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	s.invalidate(ctx, id)
+
+	undo = s.issueUndo(ctx, steps)
+
+	return
+}
+
+func (s *TodoService) EndSeries(ctx context.Context, id string) (undo string, err error) {
+
+	data, _, err := s.getRecurrence(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	seriesID := data.SeriesID
+	if !seriesID.Valid {
+		seriesID = data.ID
+	}
+
+	var steps undoSteps
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		rows, err := q.EndTodoSeries(ctx, seriesID)
+		if err != nil {
+			return
+		}
+
+		for _, row := range rows {
+			err = steps.record(ctx, q, audit.ActionUpdate, row.ID, map[string]any{"recurrence_rule": row.PreviousRecurrenceRule}, map[string]any{"recurrence_rule": row.RecurrenceRule})
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.TaskID)
+	}
+
+	s.invalidate(ctx, ids...)
+
+	undo = s.issueUndo(ctx, steps)
+
+	return
+}
+
+// DeleteTodo moves a task to the trash, from where it can be restored until
+// the purge job deletes it.
+func (s *TodoService) DeleteTodo(ctx context.Context, id string) (undo string, err error) {
+
+	uuidTodo, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	taskId := pgtype.UUID{Valid: true, Bytes: uuidTodo}
+
+	var steps undoSteps
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		_, err = deleteTodo(ctx, q, &steps, taskId)
+		return
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	s.invalidate(ctx, id)
+
+	undo = s.issueUndo(ctx, steps)
+
+	return
+}
+
+// callerId is the id of the user of ctx, invalid when the token has none.
+func callerId(ctx context.Context) (userId pgtype.UUID) {
+
+	if id, err := uuid.Parse(utils.GetUserId(ctx)); err == nil {
+		userId = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	return
+}
+
+func deleteTodo(ctx context.Context, q repositories.Querier, steps *undoSteps, id pgtype.UUID) (todo repositories.Todo, err error) {
+
+	todo, err = q.DeleteTodo(ctx, repositories.DeleteTodoParams{ID: id, UserID: callerId(ctx)})
+	if err != nil {
+		return
+	}
+
+	err = steps.record(ctx, q, audit.ActionDelete, id, map[string]any{"deleted_at": nil}, map[string]any{"deleted_at": todo.DeletedAt})
+	if err != nil {
+		return
+	}
+
+	err = enqueue(ctx, q, events.TaskDeleted, id, todo)
+
+	return
+}
+
+func (s *TodoService) RestoreTodo(ctx context.Context, id string) (todo repositories.Todo, undo string, err error) {
+
+	uuidTodo, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	taskId := pgtype.UUID{Valid: true, Bytes: uuidTodo}
+
+	var steps undoSteps
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		row, err := q.RestoreTodo(ctx, repositories.RestoreTodoParams{ID: taskId, UserID: callerId(ctx)})
+		if err != nil {
+			return
+		}
+
+		todo = repositories.Todo{
+			ID:              row.ID,
+			Title:           row.Title,
+			Description:     row.Description,
+			Status:          row.Status,
+			DueDate:         row.DueDate,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+			RecurrenceRule:  row.RecurrenceRule,
+			RecurrenceStart: row.RecurrenceStart,
+			SeriesID:        row.SeriesID,
+			DueAt:           row.DueAt,
+			UserID:          row.UserID,
+			CompletedAt:     row.CompletedAt,
+			Board:           row.Board,
+		}
+
+		err = steps.record(ctx, q, audit.ActionRestore, taskId, map[string]any{"deleted_at": row.PreviousDeletedAt}, map[string]any{"deleted_at": nil})
+		if err != nil {
+			return
+		}
+
+		return enqueue(ctx, q, events.TaskRestored, taskId, todo)
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	s.invalidate(ctx, id)
+
+	undo = s.issueUndo(ctx, steps)
+
+	return
+}
+
+// GetToken signs a token of a new user, tokens of existing users are minted
+// with the token mint command.
+func (s *TodoService) GetToken(ctx context.Context) (token string, err error) {
+
+	userId, err := uuid.NewV7()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	token, err = s.tokens.Sign(userId.String(), time.Hour*24)
 	if err != nil {
 		log.Error().Err(err).Send()
 		return
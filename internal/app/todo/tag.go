@@ -0,0 +1,110 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"ilcs/internal/audit"
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrNoTags = errors.New("tag operations need add_tags or remove_tags")
+
+// taggedTodo is the payload of the event of a task whose tags changed.
+type taggedTodo struct {
+	repositories.Todo
+	Tags []string `json:"tags"`
+}
+
+// tagTodo removes and then adds tags of a task. The audit entry holds the
+// whole tag list before and after, which is what undo puts back.
+func tagTodo(ctx context.Context, q repositories.Querier, steps *undoSteps, id pgtype.UUID, add, remove []string) (todo repositories.Todo, err error) {
+
+	if len(add) == 0 && len(remove) == 0 {
+		err = ErrNoTags
+		return
+	}
+
+	todo, err = q.GetTodoForUpdate(ctx, id)
+	if err != nil {
+		return
+	}
+
+	before, err := q.ListTodoTags(ctx, id)
+	if err != nil {
+		return
+	}
+
+	if len(remove) > 0 {
+		if _, err = q.RemoveTodoTags(ctx, repositories.RemoveTodoTagsParams{TodoID: id, Tags: remove}); err != nil {
+			return
+		}
+	}
+
+	if len(add) > 0 {
+		if _, err = q.AddTodoTags(ctx, repositories.AddTodoTagsParams{TodoID: id, Tags: add}); err != nil {
+			return
+		}
+	}
+
+	after, err := q.ListTodoTags(ctx, id)
+	if err != nil {
+		return
+	}
+
+	// an unchanged list is not recorded, so nothing is left to notify about
+	if slices.Equal(before, after) {
+		return
+	}
+
+	err = steps.record(ctx, q, audit.ActionUpdate, id, map[string]any{"tags": before}, map[string]any{"tags": after})
+	if err != nil {
+		return
+	}
+
+	err = enqueue(ctx, q, events.TaskUpdated, id, taggedTodo{Todo: todo, Tags: after})
+
+	return
+}
+
+// revertTags sets the tags of a task back to the list recorded before a tag
+// operation.
+func revertTags(ctx context.Context, q repositories.Querier, id pgtype.UUID, change audit.Change) (todo repositories.Todo, err error) {
+
+	var target []string
+	if list, ok := change.Before.([]any); ok {
+		for _, tag := range list {
+			if tag, ok := tag.(string); ok {
+				target = append(target, tag)
+			}
+		}
+	}
+
+	current, err := q.ListTodoTags(ctx, id)
+	if err != nil {
+		return
+	}
+
+	var add, remove []string
+
+	for _, tag := range target {
+		if !slices.Contains(current, tag) {
+			add = append(add, tag)
+		}
+	}
+
+	for _, tag := range current {
+		if !slices.Contains(target, tag) {
+			remove = append(remove, tag)
+		}
+	}
+
+	if len(add) == 0 && len(remove) == 0 {
+		return q.GetTodoForUpdate(ctx, id)
+	}
+
+	return tagTodo(ctx, q, &undoSteps{}, id, add, remove)
+}
@@ -1,22 +1,77 @@
 package todo
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"ilcs/internal/app/todo"
+	"ilcs/internal/constants"
+	"ilcs/internal/events"
 	"ilcs/internal/repositories"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// MockRepo embeds the Querier interface so it only has to implement the
+// queries the todo service uses; calling any other query panics.
 type MockRepo struct {
 	mock.Mock
+	repositories.Querier
+	outbox []repositories.InsertOutboxEventParams
+	audit  []repositories.InsertTaskAuditParams
+}
+
+// InsertTaskAudit records audit entries the same way as InsertOutboxEvent,
+// their ids count up from 1.
+func (m *MockRepo) InsertTaskAudit(ctx context.Context, params repositories.InsertTaskAuditParams) (int64, error) {
+	m.audit = append(m.audit, params)
+	return int64(len(m.audit)), nil
+}
+
+// InsertOutboxEvent records events instead of expecting a call, tests that
+// care about events assert on them through outboxTypes.
+func (m *MockRepo) InsertOutboxEvent(ctx context.Context, params repositories.InsertOutboxEventParams) error {
+	m.outbox = append(m.outbox, params)
+	return nil
+}
+
+// CopyTaskAudit and CopyOutboxEvents record their rows like the single row
+// inserts do.
+func (m *MockRepo) CopyTaskAudit(ctx context.Context, rows []repositories.CopyTaskAuditParams) (int64, error) {
+	for _, row := range rows {
+		m.audit = append(m.audit, repositories.InsertTaskAuditParams(row))
+	}
+	return int64(len(rows)), nil
+}
+
+func (m *MockRepo) CopyOutboxEvents(ctx context.Context, rows []repositories.CopyOutboxEventsParams) (int64, error) {
+	for _, row := range rows {
+		m.outbox = append(m.outbox, repositories.InsertOutboxEventParams(row))
+	}
+	return int64(len(rows)), nil
+}
+
+func (m *MockRepo) outboxTypes() []string {
+	var types []string
+	for _, event := range m.outbox {
+		types = append(types, event.Type)
+	}
+	return types
 }
 
 func (m *MockRepo) InsertTodo(ctx context.Context, params repositories.InsertTodoParams) (repositories.Todo, error) {
@@ -29,6 +84,11 @@ func (m *MockRepo) ListTodo(ctx context.Context, params repositories.ListTodoPar
 	return args.Get(0).([]repositories.ListTodoRow), args.Error(1)
 }
 
+func (m *MockRepo) ListTodoAfter(ctx context.Context, params repositories.ListTodoAfterParams) ([]repositories.ListTodoAfterRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.ListTodoAfterRow), args.Error(1)
+}
+
 func (m *MockRepo) CountTodo(ctx context.Context, params repositories.CountTodoParams) (int64, error) {
 	args := m.Called(ctx, params)
 	return args.Get(0).(int64), args.Error(1)
@@ -44,9 +104,64 @@ func (m *MockRepo) UpdateTodo(ctx context.Context, params repositories.UpdateTod
 	return args.Get(0).(repositories.Todo), args.Error(1)
 }
 
-func (m *MockRepo) DeleteTodo(ctx context.Context, id pgtype.UUID) error {
+func (m *MockRepo) DeleteTodo(ctx context.Context, params repositories.DeleteTodoParams) (repositories.Todo, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) MoveTodo(ctx context.Context, params repositories.MoveTodoParams) (repositories.MoveTodoRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repositories.MoveTodoRow), args.Error(1)
+}
+
+func (m *MockRepo) CompleteTodo(ctx context.Context, id pgtype.UUID) (repositories.Todo, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) InsertTodoOccurrence(ctx context.Context, params repositories.InsertTodoOccurrenceParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepo) GetTodoForUpdate(ctx context.Context, id pgtype.UUID) (repositories.Todo, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) UpdateTodoDueDate(ctx context.Context, params repositories.UpdateTodoDueDateParams) (repositories.Todo, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) RestoreTodo(ctx context.Context, params repositories.RestoreTodoParams) (repositories.RestoreTodoRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repositories.RestoreTodoRow), args.Error(1)
+}
+
+func (m *MockRepo) PurgeTrashedTodos(ctx context.Context, params repositories.PurgeTrashedTodosParams) ([]repositories.Todo, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) ListTrash(ctx context.Context, params repositories.ListTrashParams) ([]repositories.Todo, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) CountTrash(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepo) GetUserById(ctx context.Context, id pgtype.UUID) (repositories.User, error) {
 	args := m.Called(ctx, id)
-	return args.Error(0)
+	return args.Get(0).(repositories.User), args.Error(1)
+}
+
+func (m *MockRepo) EndTodoSeries(ctx context.Context, seriesID pgtype.UUID) ([]repositories.EndTodoSeriesRow, error) {
+	args := m.Called(ctx, seriesID)
+	return args.Get(0).([]repositories.EndTodoSeriesRow), args.Error(1)
 }
 
 type MockRedisClient struct {
@@ -63,10 +178,79 @@ func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}
 	return redis.NewStatusResult(args.String(0), args.Error(1))
 }
 
+func (m *MockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	args := m.Called(ctx, keys)
+	return redis.NewIntResult(int64(args.Int(0)), args.Error(1))
+}
+
+// expectUndoToken lets mutations store the undo tokens they hand out.
+func expectUndoToken(m *MockRedisClient) {
+	m.On("Set", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "undo:")
+	}), mock.Anything, todo.UndoWindow).Return("OK", nil)
+}
+
+func (m *MockRepo) LockTodo(ctx context.Context, id pgtype.UUID) (repositories.Todo, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) GetLatestTaskAudit(ctx context.Context, taskID pgtype.UUID) (repositories.TaskAudit, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).(repositories.TaskAudit), args.Error(1)
+}
+
+func (m *MockRepo) SetTodoFields(ctx context.Context, params repositories.SetTodoFieldsParams) (repositories.Todo, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repositories.Todo), args.Error(1)
+}
+
+func (m *MockRepo) ListTodoTags(ctx context.Context, todoID pgtype.UUID) ([]string, error) {
+	args := m.Called(ctx, todoID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepo) AddTodoTags(ctx context.Context, params repositories.AddTodoTagsParams) ([]string, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepo) RemoveTodoTags(ctx context.Context, params repositories.RemoveTodoTagsParams) ([]string, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepo) CopyTodos(ctx context.Context, rows []repositories.CopyTodosParams) (int64, error) {
+	args := m.Called(ctx, rows)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// EachExportTodo streams the rows given to On like the cursor of the
+// repository does.
+func (m *MockRepo) EachExportTodo(ctx context.Context, params repositories.ExportTodoParams, fn func(repositories.ExportTodoRow) error) error {
+	args := m.Called(ctx, params)
+	for _, row := range args.Get(0).([]repositories.ExportTodoRow) {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+// FakeTransactor runs the unit of work directly against the mock.
+type FakeTransactor struct {
+	repo repositories.Querier
+}
+
+func (f FakeTransactor) InTx(ctx context.Context, fn func(q repositories.Querier) error) error {
+	return fn(f.repo)
+}
+
 func TestCreateTodo_Success(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockRedisClient := new(MockRedisClient)
-	service := todo.NewTodoService(mockRepo, mockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
 
 	req := todo.CreateTodoRequest{
 		Title:       "Test Todo",
@@ -83,17 +267,18 @@ func TestCreateTodo_Success(t *testing.T) {
 
 	mockRepo.On("InsertTodo", mock.Anything, mock.Anything).Return(expectedTodo, nil)
 
-	todo, err := service.CreateTodo(context.Background(), req)
+	todo, undo, err := service.CreateTodo(context.Background(), req)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedTodo, todo)
+	assert.NoError(t, uuid.Validate(undo))
 	mockRepo.AssertExpectations(t)
 }
 
 func TestCreateTodo_InvalidDate(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockRedisClient := new(MockRedisClient)
-	service := todo.NewTodoService(mockRepo, mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
 
 	req := todo.CreateTodoRequest{
 		Title:       "Test Todo",
@@ -101,7 +286,7 @@ func TestCreateTodo_InvalidDate(t *testing.T) {
 		DueDate:     "invalid-date",
 	}
 
-	_, err := service.CreateTodo(context.Background(), req)
+	_, _, err := service.CreateTodo(context.Background(), req)
 
 	assert.Error(t, err)
 	mockRepo.AssertNotCalled(t, "InsertTodo")
@@ -110,7 +295,7 @@ func TestCreateTodo_InvalidDate(t *testing.T) {
 func TestGetListTodos_Success(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockRedisClient := new(MockRedisClient)
-	service := todo.NewTodoService(mockRepo, mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
 
 	req := todo.ListTodoRequestParams{
 		Page:  func(i int) *int { return &i }(1),
@@ -138,12 +323,14 @@ func TestGetListTodos_Success(t *testing.T) {
 			Title:       returnTodos[0].Title,
 			Description: returnTodos[0].Description.String,
 			DueDate:     returnTodos[0].DueDate.Time.Format("2006-01-02"),
+			Overdue:     true,
 		},
 		{
 			ID:          returnTodos[1].ID.String(),
 			Title:       returnTodos[1].Title,
 			Description: returnTodos[1].Description.String,
 			DueDate:     returnTodos[1].DueDate.Time.Format("2006-01-02"),
+			Overdue:     true,
 		},
 	}
 
@@ -160,10 +347,56 @@ func TestGetListTodos_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestListTodosAfter_ContinuesAfterCursor(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	createdAt := time.Date(2025, 1, 1, 9, 0, 0, 123456000, time.UTC)
+
+	rows := make([]repositories.ListTodoAfterRow, 3)
+	for i := range rows {
+		rows[i] = repositories.ListTodoAfterRow{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:     fmt.Sprintf("Task %d", i),
+			Status:    repositories.TodoStatusPending,
+			DueDate:   pgtype.Date{Time: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			CreatedAt: pgtype.Timestamptz{Time: createdAt.Add(-time.Duration(i) * time.Minute), Valid: true},
+		}
+	}
+
+	mockRepo.On("ListTodoAfter", mock.Anything, mock.MatchedBy(func(params repositories.ListTodoAfterParams) bool {
+		return !params.AfterCreatedAt.Valid
+	})).Return(rows, nil).Once()
+	mockRepo.On("ListTodoAfter", mock.Anything, repositories.ListTodoAfterParams{
+		AfterCreatedAt: rows[1].CreatedAt,
+		AfterID:        rows[1].ID,
+		LimitVal:       3,
+	}).Return(rows[2:], nil).Once()
+	mockRepo.On("CountTodo", mock.Anything, mock.Anything).Return(int64(3), nil)
+
+	edges, hasNext, count, err := service.ListTodosAfter(context.Background(), todo.ListTodoAfterRequest{First: 2})
+	require.NoError(t, err)
+	assert.True(t, hasNext)
+	assert.Equal(t, int64(3), count)
+	require.Len(t, edges, 2)
+	assert.Equal(t, "Task 1", edges[1].Todo.Title)
+
+	edges, hasNext, _, err = service.ListTodosAfter(context.Background(), todo.ListTodoAfterRequest{First: 2, After: edges[1].Cursor})
+	require.NoError(t, err)
+	assert.False(t, hasNext)
+	require.Len(t, edges, 1)
+	assert.Equal(t, "Task 2", edges[0].Todo.Title)
+	mockRepo.AssertExpectations(t)
+
+	_, _, _, err = service.ListTodosAfter(context.Background(), todo.ListTodoAfterRequest{After: "not a cursor"})
+	assert.ErrorIs(t, err, todo.ErrInvalidCursor)
+}
+
 func TestGetTodo_Success(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockRedisClient := new(MockRedisClient)
-	service := todo.NewTodoService(mockRepo, mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
 
 	id := uuid.New().String()
 
@@ -180,9 +413,12 @@ func TestGetTodo_Success(t *testing.T) {
 		Title:       returnTodo.Title,
 		Description: returnTodo.Description.String,
 		DueDate:     returnTodo.DueDate.Time.Format("2006-01-02"),
+		Overdue:     true,
+		Tags:        []string{"home"},
 	}
 
 	mockRepo.On("GetTodoById", mock.Anything, mock.Anything).Return(returnTodo, nil)
+	mockRepo.On("ListTodoTags", mock.Anything, returnTodo.ID).Return([]string{"home"}, nil)
 	mockRedisClient.On("Get", mock.Anything, "todo:"+id).Return("", redis.Nil)
 	mockRedisClient.On("Set", mock.Anything, "todo:"+id, mock.Anything, mock.Anything).Return("OK", nil)
 
@@ -197,7 +433,8 @@ func TestGetTodo_Success(t *testing.T) {
 func TestUpdateTodo_Success(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockRedisClient := new(MockRedisClient)
-	service := todo.NewTodoService(mockRepo, mockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
 
 	id := uuid.New().String()
 
@@ -214,26 +451,1115 @@ func TestUpdateTodo_Success(t *testing.T) {
 		DueDate:     pgtype.Date{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
 	}
 
+	mockRepo.On("GetTodoForUpdate", mock.Anything, expectedTodo.ID).Return(repositories.Todo{ID: expectedTodo.ID, Title: "Old Title"}, nil)
 	mockRepo.On("UpdateTodo", mock.Anything, mock.Anything).Return(expectedTodo, nil)
 
-	todo, err := service.UpdateTodo(context.Background(), req, id)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id}).Return(1, nil)
+
+	todo, _, err := service.UpdateTodo(context.Background(), req, id)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedTodo, todo)
 	mockRepo.AssertExpectations(t)
+	mockRedisClient.AssertExpectations(t)
 }
 
 func TestDeleteTodo_Success(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockRedisClient := new(MockRedisClient)
-	service := todo.NewTodoService(mockRepo, mockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New().String()
+
+	mockRepo.On("DeleteTodo", mock.Anything, mock.Anything).Return(repositories.Todo{}, nil)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id}).Return(1, nil)
+
+	_, err := service.DeleteTodo(context.Background(), id)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRedisClient.AssertExpectations(t)
+}
+
+func TestCreateTodo_InvalidRecurrenceRule(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	req := todo.CreateTodoRequest{
+		Title:          "Test Todo",
+		DueDate:        "2025-01-01",
+		RecurrenceRule: "FREQ=SOMETIMES",
+	}
+
+	_, _, err := service.CreateTodo(context.Background(), req)
+
+	assert.ErrorIs(t, err, todo.ErrInvalidRecurrenceRule)
+	mockRepo.AssertNotCalled(t, "InsertTodo")
+}
+
+func TestUpdateTodo_CompletedRecurringCreatesNextOccurrence(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New()
+
+	req := todo.UpdateTodoRequest{
+		Title:   "Monthly invoices",
+		Status:  "completed",
+		DueDate: "2025-01-01",
+	}
+
+	updatedTodo := repositories.Todo{
+		ID:              pgtype.UUID{Bytes: id, Valid: true},
+		Title:           req.Title,
+		Status:          repositories.TodoStatusCompleted,
+		DueDate:         pgtype.Date{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		RecurrenceRule:  pgtype.Text{String: "FREQ=MONTHLY", Valid: true},
+		RecurrenceStart: pgtype.Date{Time: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		UpdatedAt:       pgtype.Timestamptz{Time: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), Valid: true},
+		CompletedAt:     pgtype.Timestamptz{Time: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), Valid: true},
+	}
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, updatedTodo.ID).Return(repositories.Todo{ID: updatedTodo.ID}, nil)
+	mockRepo.On("UpdateTodo", mock.Anything, mock.Anything).Return(updatedTodo, nil)
+	mockRepo.On("InsertTodoOccurrence", mock.Anything, mock.MatchedBy(func(params repositories.InsertTodoOccurrenceParams) bool {
+		return params.DueDate.Time.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) &&
+			params.SeriesID == updatedTodo.ID &&
+			params.RecurrenceStart == updatedTodo.RecurrenceStart
+	})).Return(int64(1), nil)
+
+	mockRedisClient.On("Del", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, _, err := service.UpdateTodo(context.Background(), req, id.String())
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPreviewOccurrences_Success(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New().String()
+
+	returnTodo := repositories.GetTodoByIdRow{
+		ID:             pgtype.UUID{Bytes: uuid.MustParse(id), Valid: true},
+		Title:          "Standup prep",
+		DueDate:        pgtype.Date{Time: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Valid: true},
+		RecurrenceRule: pgtype.Text{String: "RRULE:FREQ=WEEKLY;BYDAY=MO,FR", Valid: true},
+	}
+
+	mockRepo.On("GetTodoById", mock.Anything, mock.Anything).Return(returnTodo, nil)
+
+	dates, err := service.PreviewOccurrences(context.Background(), id, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2025-01-06", "2025-01-10", "2025-01-13"}, dates)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSkipOccurrence_NotRecurring(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
 
 	id := uuid.New().String()
 
-	mockRepo.On("DeleteTodo", mock.Anything, mock.Anything).Return(nil)
+	returnTodo := repositories.GetTodoByIdRow{
+		ID:      pgtype.UUID{Bytes: uuid.MustParse(id), Valid: true},
+		DueDate: pgtype.Date{Time: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Valid: true},
+	}
+
+	mockRepo.On("GetTodoById", mock.Anything, mock.Anything).Return(returnTodo, nil)
+
+	_, _, err := service.SkipOccurrence(context.Background(), id)
+
+	assert.ErrorIs(t, err, todo.ErrNotRecurring)
+	mockRepo.AssertNotCalled(t, "UpdateTodoDueDate")
+}
+
+func TestCreateTodo_DueAtInUserTimezone(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	req := todo.CreateTodoRequest{
+		Title: "Submit report",
+		DueAt: "2025-01-01T20:00:00Z",
+	}
+
+	mockRepo.On("GetUserById", mock.Anything, pgtype.UUID{Bytes: userId, Valid: true}).Return(repositories.User{Timezone: "Asia/Jakarta"}, nil)
+	mockRepo.On("InsertTodo", mock.Anything, mock.MatchedBy(func(params repositories.InsertTodoParams) bool {
+		return params.DueDate.Time.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)) &&
+			params.DueAt.Time.Equal(time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC))
+	})).Return(repositories.Todo{}, nil)
 
-	err := service.DeleteTodo(context.Background(), id)
+	_, _, err := service.CreateTodo(ctx, req)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetListTodos_DueAtLocalizedAndOverdue(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	returnTodos := []repositories.ListTodoRow{
+		{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:   "Past due time",
+			Status:  repositories.TodoStatusPending,
+			DueDate: pgtype.Date{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			DueAt:   pgtype.Timestamptz{Time: time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC), Valid: true},
+		},
+		{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:   "Due tomorrow",
+			Status:  repositories.TodoStatusPending,
+			DueDate: pgtype.Date{Time: tomorrow, Valid: true},
+		},
+		{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:   "Completed",
+			Status:  repositories.TodoStatusCompleted,
+			DueDate: pgtype.Date{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		},
+	}
+
+	mockRepo.On("GetUserById", mock.Anything, mock.Anything).Return(repositories.User{Timezone: "Asia/Jakarta"}, nil)
+	mockRepo.On("ListTodo", mock.Anything, mock.Anything).Return(returnTodos, nil)
+	mockRepo.On("CountTodo", mock.Anything, mock.Anything).Return(int64(len(returnTodos)), nil)
+
+	todos, _, _, _, err := service.GetListTodos(ctx, todo.ListTodoRequestParams{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-01-02T03:00:00+07:00", todos[0].DueAt)
+	assert.True(t, todos[0].Overdue)
+	assert.False(t, todos[1].Overdue)
+	assert.False(t, todos[2].Overdue)
+}
+
+func TestUpdateTodo_EditingCompletedOccurrenceSchedulesNothing(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New()
+
+	req := todo.UpdateTodoRequest{
+		Title:   "Monthly invoices",
+		Status:  "completed",
+		DueDate: "2025-01-05",
+	}
+
+	// completed earlier, the update moves its due date
+	updatedTodo := repositories.Todo{
+		ID:              pgtype.UUID{Bytes: id, Valid: true},
+		Title:           req.Title,
+		Status:          repositories.TodoStatusCompleted,
+		DueDate:         pgtype.Date{Time: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Valid: true},
+		RecurrenceRule:  pgtype.Text{String: "FREQ=MONTHLY", Valid: true},
+		RecurrenceStart: pgtype.Date{Time: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		UpdatedAt:       pgtype.Timestamptz{Time: time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), Valid: true},
+		CompletedAt:     pgtype.Timestamptz{Time: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), Valid: true},
+	}
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, updatedTodo.ID).Return(updatedTodo, nil)
+	mockRepo.On("UpdateTodo", mock.Anything, mock.Anything).Return(updatedTodo, nil)
+	mockRedisClient.On("Del", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, _, err := service.UpdateTodo(context.Background(), req, id.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{events.TaskUpdated}, mockRepo.outboxTypes())
+	mockRepo.AssertNotCalled(t, "InsertTodoOccurrence", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTodo_EnqueuesCompletedOnTransition(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New()
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	req := todo.UpdateTodoRequest{
+		Title:   "Ship release",
+		Status:  "completed",
+		DueDate: "2025-01-01",
+	}
+
+	now := time.Now()
+
+	updatedTodo := repositories.Todo{
+		ID:          pgtype.UUID{Bytes: id, Valid: true},
+		Title:       req.Title,
+		Status:      repositories.TodoStatusCompleted,
+		UpdatedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		CompletedAt: pgtype.Timestamptz{Time: now, Valid: true},
+	}
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, updatedTodo.ID).Return(repositories.Todo{ID: updatedTodo.ID}, nil)
+	mockRepo.On("UpdateTodo", mock.Anything, mock.Anything).Return(updatedTodo, nil).Once()
+
+	mockRedisClient.On("Del", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, _, err := service.UpdateTodo(ctx, req, id.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{events.TaskUpdated, events.TaskCompleted}, mockRepo.outboxTypes())
+	assert.Equal(t, pgtype.UUID{Bytes: userId, Valid: true}, mockRepo.outbox[1].UserID)
+	assert.Equal(t, updatedTodo.ID, mockRepo.outbox[1].TaskID)
+
+	// saving an already completed task again is only an update
+	updatedTodo.UpdatedAt = pgtype.Timestamptz{Time: now.Add(time.Minute), Valid: true}
+	mockRepo.On("UpdateTodo", mock.Anything, mock.Anything).Return(updatedTodo, nil).Once()
+
+	_, _, err = service.UpdateTodo(ctx, req, id.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{events.TaskUpdated, events.TaskCompleted, events.TaskUpdated}, mockRepo.outboxTypes())
+}
+
+func TestDeleteTodo_EnqueuesDeleted(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id, userId := uuid.New(), uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	mockRepo.On("DeleteTodo", mock.Anything, repositories.DeleteTodoParams{
+		ID:     pgtype.UUID{Bytes: id, Valid: true},
+		UserID: pgtype.UUID{Bytes: userId, Valid: true},
+	}).Return(repositories.Todo{ID: pgtype.UUID{Bytes: id, Valid: true}, Board: "sprint"}, nil)
+	mockRedisClient.On("Del", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, err := service.DeleteTodo(ctx, id.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{events.TaskDeleted}, mockRepo.outboxTypes())
+	assert.Equal(t, pgtype.UUID{Bytes: id, Valid: true}, mockRepo.outbox[0].TaskID)
+}
+
+func TestDeleteTodo_NoEventWhenDeleteFails(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	mockRepo.On("DeleteTodo", mock.Anything, mock.Anything).Return(repositories.Todo{}, errors.New("connection reset"))
+
+	_, err := service.DeleteTodo(context.Background(), uuid.New().String())
+
+	assert.Error(t, err)
+	assert.Empty(t, mockRepo.outbox)
+}
+
+func TestMoveTodo_EnqueuesPreviousBoard(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New()
+
+	mockRepo.On("MoveTodo", mock.Anything, repositories.MoveTodoParams{
+		ID:    pgtype.UUID{Bytes: id, Valid: true},
+		Board: "done",
+	}).Return(repositories.MoveTodoRow{
+		ID:            pgtype.UUID{Bytes: id, Valid: true},
+		Board:         "done",
+		PreviousBoard: "doing",
+	}, nil)
+
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id.String()}).Return(1, nil)
+
+	moved, _, err := service.MoveTodo(context.Background(), id.String(), " done ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "done", moved.Board)
+	assert.Equal(t, []string{events.TaskUpdated}, mockRepo.outboxTypes())
+	assert.Contains(t, string(mockRepo.outbox[0].Payload), `"previous_board":"doing"`)
+	mockRedisClient.AssertExpectations(t)
+}
+
+func TestMoveTodo_InvalidBoard(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	_, _, err := service.MoveTodo(context.Background(), uuid.New().String(), "  ")
+
+	assert.ErrorIs(t, err, todo.ErrInvalidBoard)
+	mockRepo.AssertNotCalled(t, "MoveTodo", mock.Anything, mock.Anything)
+}
+
+func TestCompleteTodo_RecurringCreatesNextOccurrence(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New()
+	now := time.Now()
+
+	completed := repositories.Todo{
+		ID:             pgtype.UUID{Bytes: id, Valid: true},
+		Status:         repositories.TodoStatusCompleted,
+		DueDate:        pgtype.Date{Time: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), Valid: true},
+		RecurrenceRule: pgtype.Text{String: "FREQ=WEEKLY", Valid: true},
+		UpdatedAt:      pgtype.Timestamptz{Time: now, Valid: true},
+		CompletedAt:    pgtype.Timestamptz{Time: now, Valid: true},
+		Board:          "standup",
+	}
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, completed.ID).Return(repositories.Todo{ID: completed.ID, Board: "standup"}, nil)
+	mockRepo.On("CompleteTodo", mock.Anything, pgtype.UUID{Bytes: id, Valid: true}).Return(completed, nil)
+	mockRepo.On("InsertTodoOccurrence", mock.Anything, mock.MatchedBy(func(params repositories.InsertTodoOccurrenceParams) bool {
+		return params.DueDate.Time.Equal(time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)) && params.Board == "standup"
+	})).Return(int64(1), nil)
+
+	mockRedisClient.On("Del", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, _, err := service.CompleteTodo(context.Background(), id.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{events.TaskUpdated, events.TaskCompleted}, mockRepo.outboxTypes())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateTodo_RecordsAudit(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New()
+	userId := uuid.New()
+
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+	ctx = context.WithValue(ctx, constants.TRACE_ID, "trace-1")
+
+	before := repositories.Todo{
+		ID:        pgtype.UUID{Bytes: id, Valid: true},
+		Title:     "Draft",
+		DueDate:   pgtype.Date{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		UpdatedAt: pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+
+	after := before
+	after.Title = "Final"
+	after.UpdatedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, before.ID).Return(before, nil)
+	mockRepo.On("UpdateTodo", mock.Anything, mock.Anything).Return(after, nil)
+
+	mockRedisClient.On("Del", mock.Anything, mock.Anything).Return(1, nil)
+
+	_, _, err := service.UpdateTodo(ctx, todo.UpdateTodoRequest{Title: "Final", Status: "pending", DueDate: "2025-01-02"}, id.String())
+
+	assert.NoError(t, err)
+	require.Len(t, mockRepo.audit, 1)
+	assert.Equal(t, "update", mockRepo.audit[0].Action)
+	assert.Equal(t, pgtype.UUID{Bytes: userId, Valid: true}, mockRepo.audit[0].Actor)
+	assert.Equal(t, "trace-1", mockRepo.audit[0].TraceID.String)
+	assert.JSONEq(t, `{"title":{"before":"Draft","after":"Final"}}`, string(mockRepo.audit[0].Changes))
+}
+
+func TestEndSeries_RecordsAuditPerTask(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := uuid.New()
+	rule := pgtype.Text{String: "FREQ=DAILY", Valid: true}
+
+	mockRepo.On("GetTodoById", mock.Anything, mock.Anything).Return(repositories.GetTodoByIdRow{
+		ID:             pgtype.UUID{Bytes: id, Valid: true},
+		DueDate:        pgtype.Date{Time: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Valid: true},
+		RecurrenceRule: rule,
+	}, nil)
+
+	otherId := uuid.New()
+
+	mockRepo.On("EndTodoSeries", mock.Anything, pgtype.UUID{Bytes: id, Valid: true}).Return([]repositories.EndTodoSeriesRow{
+		{ID: pgtype.UUID{Bytes: id, Valid: true}, PreviousRecurrenceRule: rule},
+		{ID: pgtype.UUID{Bytes: otherId, Valid: true}, PreviousRecurrenceRule: rule},
+	}, nil)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id.String()}).Return(1, nil)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + otherId.String()}).Return(1, nil)
+
+	_, err := service.EndSeries(context.Background(), id.String())
+
+	assert.NoError(t, err)
+	require.Len(t, mockRepo.audit, 2)
+	assert.JSONEq(t, `{"recurrence_rule":{"before":"FREQ=DAILY","after":null}}`, string(mockRepo.audit[0].Changes))
+	mockRedisClient.AssertExpectations(t)
+}
+
+func TestRestoreTodo_RecordsAndEnqueuesRestored(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id, userId := uuid.New(), uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+	deletedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mockRepo.On("RestoreTodo", mock.Anything, repositories.RestoreTodoParams{
+		ID:     pgtype.UUID{Bytes: id, Valid: true},
+		UserID: pgtype.UUID{Bytes: userId, Valid: true},
+	}).Return(repositories.RestoreTodoRow{
+		ID:                pgtype.UUID{Bytes: id, Valid: true},
+		Title:             "Recovered",
+		PreviousDeletedAt: pgtype.Timestamptz{Time: deletedAt, Valid: true},
+	}, nil)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id.String()}).Return(1, nil)
+
+	restored, _, err := service.RestoreTodo(ctx, id.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Recovered", restored.Title)
+	assert.Equal(t, []string{events.TaskRestored}, mockRepo.outboxTypes())
+	require.Len(t, mockRepo.audit, 1)
+	assert.Equal(t, "restore", mockRepo.audit[0].Action)
+	assert.JSONEq(t, `{"deleted_at":{"before":"2025-03-01T12:00:00Z","after":null}}`, string(mockRepo.audit[0].Changes))
+	mockRedisClient.AssertExpectations(t)
+}
+
+func TestRestoreTodo_NotInTrash(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	mockRepo.On("RestoreTodo", mock.Anything, mock.Anything).Return(repositories.RestoreTodoRow{}, pgx.ErrNoRows)
+
+	_, _, err := service.RestoreTodo(context.Background(), uuid.New().String())
+
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.Empty(t, mockRepo.outbox)
+	mockRedisClient.AssertNotCalled(t, "Del", mock.Anything, mock.Anything)
+}
+
+func TestListTrash_OnlyCallersTasks(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), FakeTransactor{mockRepo}, nil)
+
+	userId := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+	deletedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetUserById", mock.Anything, userId).Return(repositories.User{}, pgx.ErrNoRows)
+	mockRepo.On("ListTrash", mock.Anything, repositories.ListTrashParams{UserID: userId, LimitVal: 10, Page: 1}).Return([]repositories.Todo{{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Title:     "Trashed",
+		UserID:    userId,
+		DeletedAt: pgtype.Timestamptz{Time: deletedAt, Valid: true},
+	}}, nil)
+	mockRepo.On("CountTrash", mock.Anything, userId).Return(int64(1), nil)
+
+	todos, count, _, _, err := service.ListTrash(ctx, todo.ListTrashRequestParams{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, todos, 1)
+	assert.Equal(t, "2025-03-01T12:00:00Z", todos[0].DeletedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeJob_PurgesExpiredTrash(t *testing.T) {
+	mockRepo := new(MockRepo)
+	job := todo.NewPurgeJob(FakeTransactor{mockRepo}, 30*24*time.Hour, time.Hour)
+
+	now := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	id := uuid.New()
+
+	mockRepo.On("PurgeTrashedTodos", mock.Anything, repositories.PurgeTrashedTodosParams{
+		DeletedBefore: pgtype.Timestamptz{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		LimitVal:      100,
+	}).Return([]repositories.Todo{{ID: pgtype.UUID{Bytes: id, Valid: true}, Title: "Old"}}, nil)
+
+	purged, err := job.Tick(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	require.Len(t, mockRepo.audit, 1)
+	assert.Equal(t, "purge", mockRepo.audit[0].Action)
+	assert.False(t, mockRepo.audit[0].Actor.Valid)
+}
+
+func undoSetup(latestAuditId int64) (service *todo.TodoService, mockRepo *MockRepo, mockRedisClient *MockRedisClient, ctx context.Context, id uuid.UUID) {
+
+	mockRepo = new(MockRepo)
+	mockRedisClient = new(MockRedisClient)
+	service = todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	userId := uuid.New()
+	ctx = context.WithValue(context.Background(), constants.USER_ID, userId.String())
+	id = uuid.New()
+	taskId := pgtype.UUID{Bytes: id, Valid: true}
+
+	token := fmt.Sprintf(`{"user_id":%q,"steps":[{"task_id":%q,"audit_id":5}]}`, userId, id)
+	mockRedisClient.On("Get", mock.Anything, "undo:tok").Return(token, nil)
+
+	mockRepo.On("LockTodo", mock.Anything, taskId).Return(repositories.Todo{
+		ID:     taskId,
+		Title:  "New",
+		Status: repositories.TodoStatusPending,
+		Board:  "sprint",
+	}, nil)
+
+	mockRepo.On("GetLatestTaskAudit", mock.Anything, taskId).Return(repositories.TaskAudit{
+		ID:      latestAuditId,
+		TaskID:  taskId,
+		Action:  "update",
+		Changes: []byte(`{"title":{"before":"Old","after":"New"},"board":{"before":"inbox","after":"sprint"}}`),
+	}, nil)
+
+	return
+}
+
+func TestUndo_RevertsUpdate(t *testing.T) {
+	service, mockRepo, mockRedisClient, ctx, id := undoSetup(5)
+	taskId := pgtype.UUID{Bytes: id, Valid: true}
+
+	reverted := repositories.Todo{ID: taskId, Title: "Old", Status: repositories.TodoStatusPending, Board: "inbox"}
+
+	mockRepo.On("SetTodoFields", mock.Anything, repositories.SetTodoFieldsParams{
+		ID:     taskId,
+		Title:  "Old",
+		Status: repositories.TodoStatusPending,
+		Board:  "inbox",
+	}).Return(reverted, nil)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id.String()}).Return(1, nil)
+	mockRedisClient.On("Del", mock.Anything, []string{"undo:tok"}).Return(1, nil)
+
+	todos, err := service.Undo(ctx, "tok")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []repositories.Todo{reverted}, todos)
+	assert.Equal(t, []string{events.TaskUpdated}, mockRepo.outboxTypes())
+	require.Len(t, mockRepo.audit, 1)
+	assert.JSONEq(t, `{"title":{"before":"New","after":"Old"},"board":{"before":"sprint","after":"inbox"}}`, string(mockRepo.audit[0].Changes))
+	mockRepo.AssertExpectations(t)
+	mockRedisClient.AssertExpectations(t)
+}
+
+func TestUndo_ConflictWhenChangedSince(t *testing.T) {
+	service, mockRepo, mockRedisClient, ctx, _ := undoSetup(6)
+
+	_, err := service.Undo(ctx, "tok")
+
+	assert.ErrorIs(t, err, todo.ErrUndoConflict)
+	assert.Empty(t, mockRepo.audit)
+	assert.Empty(t, mockRepo.outbox)
+	mockRepo.AssertNotCalled(t, "SetTodoFields", mock.Anything, mock.Anything)
+	mockRedisClient.AssertNotCalled(t, "Del", mock.Anything, mock.Anything)
+}
+
+func TestUndo_OtherUsersToken(t *testing.T) {
+	service, mockRepo, _, _, _ := undoSetup(5)
+
+	ctx := context.WithValue(context.Background(), constants.USER_ID, uuid.NewString())
+
+	_, err := service.Undo(ctx, "tok")
+
+	assert.ErrorIs(t, err, todo.ErrUndoNotFound)
+	mockRepo.AssertNotCalled(t, "LockTodo", mock.Anything, mock.Anything)
+}
+
+func TestUndo_Expired(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	mockRedisClient.On("Get", mock.Anything, "undo:gone").Return("", redis.Nil)
+
+	_, err := service.Undo(context.Background(), "gone")
+
+	assert.ErrorIs(t, err, todo.ErrUndoNotFound)
+}
+
+func TestBulk_PerItemResults(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	done, missing := uuid.New(), uuid.New()
+	doneId := pgtype.UUID{Bytes: done, Valid: true}
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, doneId).Return(repositories.Todo{ID: doneId, Status: repositories.TodoStatusPending}, nil)
+	mockRepo.On("CompleteTodo", mock.Anything, doneId).Return(repositories.Todo{ID: doneId, Status: repositories.TodoStatusCompleted}, nil)
+	mockRepo.On("DeleteTodo", mock.Anything, repositories.DeleteTodoParams{ID: pgtype.UUID{Bytes: missing, Valid: true}}).Return(repositories.Todo{}, pgx.ErrNoRows)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + done.String()}).Return(1, nil).Once()
+
+	results, undo, err := service.Bulk(context.Background(), todo.BulkRequest{Operations: []todo.BulkOperation{
+		{Op: todo.BulkComplete, ID: done.String()},
+		{Op: todo.BulkDelete, ID: missing.String()},
+	}})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, undo)
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, repositories.TodoStatusCompleted, results[0].Task.Status)
+	assert.ErrorIs(t, results[1].Err, pgx.ErrNoRows)
+	assert.Equal(t, 1, results[1].Index)
+	mockRedisClient.AssertExpectations(t)
+}
+
+func TestBulk_AtomicStopsAtFirstFailure(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	mockRepo.On("InsertTodo", mock.Anything, mock.Anything).Return(repositories.Todo{Title: "New"}, nil)
+
+	results, undo, err := service.Bulk(context.Background(), todo.BulkRequest{Atomic: true, Operations: []todo.BulkOperation{
+		{Op: todo.BulkCreate, Task: &todo.CreateTodoRequest{Title: "New", DueDate: "2025-01-01"}},
+		{Op: todo.BulkTag, ID: uuid.NewString()},
+		{Op: todo.BulkDelete, ID: uuid.NewString()},
+	}})
+
+	var bulkErr *todo.BulkError
+	require.ErrorAs(t, err, &bulkErr)
+	assert.Equal(t, 1, bulkErr.Index)
+	assert.ErrorIs(t, err, todo.ErrNoTags)
+	assert.Nil(t, results)
+	assert.Empty(t, undo)
+	mockRepo.AssertNotCalled(t, "DeleteTodo", mock.Anything, mock.Anything)
+	mockRedisClient.AssertNotCalled(t, "Del", mock.Anything, mock.Anything)
+}
+
+func TestBulk_UpdateKeepsOtherFields(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	before := repositories.Todo{
+		ID:          id,
+		Title:       "Write report",
+		Description: pgtype.Text{String: "quarterly", Valid: true},
+		Status:      repositories.TodoStatusPending,
+		DueDate:     pgtype.Date{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		Board:       "inbox",
+	}
+
+	after := before
+	after.Board = "sprint"
+
+	board := " sprint "
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, id).Return(before, nil)
+	mockRepo.On("SetTodoFields", mock.Anything, repositories.SetTodoFieldsParams{
+		ID:          id,
+		Title:       before.Title,
+		Description: before.Description,
+		Status:      before.Status,
+		DueDate:     before.DueDate,
+		Board:       "sprint",
+	}).Return(after, nil)
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id.String()}).Return(1, nil)
+
+	results, _, err := service.Bulk(context.Background(), todo.BulkRequest{Operations: []todo.BulkOperation{
+		{Op: todo.BulkUpdate, ID: id.String(), Fields: &todo.BulkUpdateFields{Board: &board}},
+	}})
+
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	require.Len(t, mockRepo.outbox, 1)
+	assert.Contains(t, string(mockRepo.outbox[0].Payload), `"previous_board":"inbox"`)
+	require.Len(t, mockRepo.audit, 1)
+	assert.JSONEq(t, `{"board":{"before":"inbox","after":"sprint"}}`, string(mockRepo.audit[0].Changes))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBulk_TagRecordsTagLists(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	expectUndoToken(mockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	id := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	mockRepo.On("GetTodoForUpdate", mock.Anything, id).Return(repositories.Todo{ID: id}, nil)
+	mockRepo.On("ListTodoTags", mock.Anything, id).Return([]string{"old"}, nil).Once()
+	mockRepo.On("RemoveTodoTags", mock.Anything, repositories.RemoveTodoTagsParams{TodoID: id, Tags: []string{"old"}}).Return([]string{"old"}, nil)
+	mockRepo.On("AddTodoTags", mock.Anything, repositories.AddTodoTagsParams{TodoID: id, Tags: []string{"urgent"}}).Return([]string{"urgent"}, nil)
+	mockRepo.On("ListTodoTags", mock.Anything, id).Return([]string{"urgent"}, nil).Once()
+	mockRedisClient.On("Del", mock.Anything, []string{"todo:" + id.String()}).Return(1, nil)
+
+	_, _, err := service.Bulk(context.Background(), todo.BulkRequest{Operations: []todo.BulkOperation{
+		{Op: todo.BulkTag, ID: id.String(), AddTags: []string{"urgent"}, RemoveTags: []string{"old"}},
+	}})
+
+	assert.NoError(t, err)
+	require.Len(t, mockRepo.audit, 1)
+	assert.JSONEq(t, `{"tags":{"before":["old"],"after":["urgent"]}}`, string(mockRepo.audit[0].Changes))
+	assert.Contains(t, string(mockRepo.outbox[0].Payload), `"tags":["urgent"]`)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportTodos_CSVWithColumnMapping(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	var copied []repositories.CopyTodosParams
+	mockRepo.On("CopyTodos", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		copied = args.Get(1).([]repositories.CopyTodosParams)
+	}).Return(int64(2), nil)
+
+	file := "\ufeffName,Due,Notes,Priority\nBuy milk,2025-01-01,semi-skimmed,high\nCall mom,2025-01-02,,low\n"
+
+	report, err := service.ImportTodos(context.Background(), strings.NewReader(file), todo.ImportRequest{
+		Format:  todo.ImportCSV,
+		Columns: map[string]string{"Name": "title", "Due": "due_date", "Notes": "description"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Rows)
+	assert.Equal(t, int64(2), report.Imported)
+	assert.Equal(t, []string{"Priority"}, report.IgnoredColumns)
+	assert.Empty(t, report.Errors)
+	require.Len(t, copied, 2)
+	assert.Equal(t, "Buy milk", copied[0].Title)
+	assert.Equal(t, "semi-skimmed", copied[0].Description.String)
+	assert.Equal(t, todo.DefaultBoard, copied[1].Board)
+	assert.Equal(t, repositories.TodoStatusPending, copied[1].Status)
+	assert.Len(t, mockRepo.audit, 2)
+	assert.Equal(t, []string{events.TaskCreated, events.TaskCreated}, mockRepo.outboxTypes())
+}
+
+func TestImportTodos_ReportsEveryInvalidRow(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	file := `{"title": "Valid", "due_date": "2025-01-01"}
+
+{"due_date": "2025-01-01"}
+{"title": "Bad date", "due_date": "01/02/2025"}
+not json
+{"title": "Bad rule", "due_date": "2025-01-01", "recurrence_rule": "FREQ=SOMETIMES"}
+`
+
+	report, err := service.ImportTodos(context.Background(), strings.NewReader(file), todo.ImportRequest{Format: todo.ImportJSONLines})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Rows)
+	assert.Zero(t, report.Imported)
+
+	var lines []int
+	for _, rowErr := range report.Errors {
+		lines = append(lines, rowErr.Line)
+	}
+
+	assert.Equal(t, []int{3, 4, 5, 6}, lines)
+	mockRepo.AssertNotCalled(t, "CopyTodos", mock.Anything, mock.Anything)
+	assert.Empty(t, mockRepo.outbox)
+}
+
+func TestImportTodos_DryRunWritesNothing(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRedisClient := new(MockRedisClient)
+	service := todo.NewTodoService(mockRepo, mockRedisClient, FakeTransactor{mockRepo}, nil)
+
+	report, err := service.ImportTodos(context.Background(), strings.NewReader("title,due_date\nA,2025-01-01\nB,2025-01-02,extra\n"), todo.ImportRequest{
+		Format: todo.ImportCSV,
+		DryRun: true,
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 3, report.Errors[0].Line)
+	mockRepo.AssertNotCalled(t, "CopyTodos", mock.Anything, mock.Anything)
+}
+
+func TestImportTodos_NeedsTitleColumn(t *testing.T) {
+	service := todo.NewTodoService(new(MockRepo), new(MockRedisClient), nil, nil)
+
+	_, err := service.ImportTodos(context.Background(), strings.NewReader("name,due_date\nA,2025-01-01\n"), todo.ImportRequest{Format: todo.ImportCSV})
+
+	assert.ErrorIs(t, err, todo.ErrImportTitle)
+}
+
+func exportRows() []repositories.ExportTodoRow {
+	return []repositories.ExportTodoRow{
+		{
+			ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:       "Buy milk, eggs",
+			Description: pgtype.Text{String: "<semi-skimmed>", Valid: true},
+			Status:      repositories.TodoStatusPending,
+			DueDate:     pgtype.Date{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			Board:       "inbox",
+			Tags:        []string{"errand", "home"},
+		},
+		{
+			ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Title:   "Call mom",
+			Status:  repositories.TodoStatusCompleted,
+			DueDate: pgtype.Date{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			DueAt:   pgtype.Timestamptz{Time: time.Date(2025, 1, 2, 18, 30, 0, 0, time.UTC), Valid: true},
+			Board:   "family",
+		},
+	}
+}
+
+func TestExportTodos_CSV(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), nil, nil)
+
+	board := "inbox"
+	mockRepo.On("EachExportTodo", mock.Anything, repositories.ExportTodoParams{Board: &board}).Return(exportRows(), nil)
+
+	var out bytes.Buffer
+	err := service.ExportTodos(context.Background(), &out, todo.ExportRequestParams{Format: todo.ExportCSV, Board: &board})
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"id", "title", "description", "status", "due_date", "due_at", "recurrence_rule", "board", "overdue", "tags"}, records[0])
+	assert.Equal(t, "Buy milk, eggs", records[1][1])
+	assert.Equal(t, "true", records[1][8])
+	assert.Equal(t, "errand,home", records[1][9])
+	assert.Equal(t, "2025-01-02T18:30:00Z", records[2][5])
+	assert.Equal(t, "false", records[2][8])
+}
+
+func TestExportTodos_NDJSON(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), nil, nil)
+
+	mockRepo.On("EachExportTodo", mock.Anything, repositories.ExportTodoParams{}).Return(exportRows(), nil)
+
+	var out bytes.Buffer
+	require.NoError(t, service.ExportTodos(context.Background(), &out, todo.ExportRequestParams{Format: todo.ExportNDJSON}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var first todo.Todo
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "<semi-skimmed>", first.Description)
+	assert.Equal(t, []string{"errand", "home"}, first.Tags)
+}
+
+func TestExportTodos_XLSX(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), nil, nil)
+
+	mockRepo.On("EachExportTodo", mock.Anything, repositories.ExportTodoParams{}).Return(exportRows(), nil)
+
+	var out bytes.Buffer
+	require.NoError(t, service.ExportTodos(context.Background(), &out, todo.ExportRequestParams{Format: todo.ExportXLSX}))
+
+	archive, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+
+	sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(sheet)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "&lt;semi-skimmed&gt;")
+	assert.Equal(t, 3, strings.Count(string(data), "<row "))
+}
+
+func TestExportTodos_QueryFailure(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), nil, nil)
+
+	mockRepo.On("EachExportTodo", mock.Anything, repositories.ExportTodoParams{}).Return([]repositories.ExportTodoRow(nil), errors.New("connection reset"))
+
+	var out bytes.Buffer
+	err := service.ExportTodos(context.Background(), &out, todo.ExportRequestParams{Format: todo.ExportXLSX})
+
+	assert.Error(t, err)
+	_, err = zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	assert.Error(t, err, "a failed export must not look complete")
+}
+
+// plainTextRows are tasks the todo.txt and Markdown formats can hold, grouped
+// by board like the export query returns them.
+func plainTextRows() []repositories.ExportTodoRow {
+	return []repositories.ExportTodoRow{
+		{
+			Title:          "Water plants",
+			Status:         repositories.TodoStatusPending,
+			DueDate:        pgtype.Date{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			RecurrenceRule: pgtype.Text{String: "FREQ=WEEKLY;BYDAY=SA", Valid: true},
+			Board:          "garden",
+			Tags:           []string{"home", "pri:A"},
+		},
+		{
+			Title:   "Pay rent",
+			Status:  repositories.TodoStatusCompleted,
+			DueDate: pgtype.Date{Time: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+			Board:   "inbox",
+			Tags:    []string{"pri:B"},
+		},
+	}
+}
+
+// roundTrip exports rows, imports the export and exports the imported tasks
+// again.
+func roundTrip(t *testing.T, rows []repositories.ExportTodoRow, exportFormat, importFormat string) (first, second string) {
+
+	export := func(rows []repositories.ExportTodoRow) string {
+		mockRepo := new(MockRepo)
+		service := todo.NewTodoService(mockRepo, new(MockRedisClient), nil, nil)
+		mockRepo.On("EachExportTodo", mock.Anything, repositories.ExportTodoParams{}).Return(rows, nil)
+
+		var out bytes.Buffer
+		require.NoError(t, service.ExportTodos(context.Background(), &out, todo.ExportRequestParams{Format: exportFormat}))
+
+		return out.String()
+	}
+
+	first = export(rows)
+
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), FakeTransactor{mockRepo}, nil)
+
+	var copied []repositories.CopyTodosParams
+	mockRepo.On("CopyTodos", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		copied = args.Get(1).([]repositories.CopyTodosParams)
+	}).Return(int64(len(rows)), nil)
+
+	tags := map[pgtype.UUID][]string{}
+	mockRepo.On("AddTodoTags", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		params := args.Get(1).(repositories.AddTodoTagsParams)
+		tags[params.TodoID] = params.Tags
+	}).Return([]string{}, nil)
+
+	report, err := service.ImportTodos(context.Background(), strings.NewReader(first), todo.ImportRequest{Format: importFormat})
+	require.NoError(t, err)
+	require.Empty(t, report.Errors)
+	require.Len(t, copied, len(rows))
+
+	imported := make([]repositories.ExportTodoRow, 0, len(copied))
+	for _, params := range copied {
+		imported = append(imported, repositories.ExportTodoRow{
+			ID:             params.ID,
+			Title:          params.Title,
+			Description:    params.Description,
+			Status:         params.Status,
+			DueDate:        params.DueDate,
+			RecurrenceRule: params.RecurrenceRule,
+			DueAt:          params.DueAt,
+			Board:          params.Board,
+			Tags:           tags[params.ID],
+		})
+	}
+
+	second = export(imported)
+
+	return
+}
+
+func TestTodoTxt_RoundTrip(t *testing.T) {
+	first, second := roundTrip(t, plainTextRows(), todo.ExportTodoTxt, todo.ImportTodoTxt)
+
+	assert.Equal(t, "(A) Water plants +garden @home due:2025-03-01 rrule:FREQ=WEEKLY;BYDAY=SA\n"+
+		"x Pay rent due:2025-03-02 pri:B\n", first)
+	assert.Equal(t, first, second)
+}
+
+func TestMarkdown_RoundTrip(t *testing.T) {
+	rows := plainTextRows()
+	rows[1].Description = pgtype.Text{String: "Transfer to the landlord.\n\nReference: flat 3", Valid: true}
+
+	first, second := roundTrip(t, rows, todo.ExportMarkdown, todo.ImportMarkdown)
+
+	assert.Equal(t, "## garden\n\n"+
+		"- [ ] (A) Water plants #home due:2025-03-01 rrule:FREQ=WEEKLY;BYDAY=SA\n"+
+		"\n## inbox\n\n"+
+		"- [x] (B) Pay rent due:2025-03-02\n"+
+		"  Transfer to the landlord.\n\n  Reference: flat 3\n", first)
+	assert.Equal(t, first, second)
+}
+
+func TestImportTodos_TodoTxt(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), FakeTransactor{mockRepo}, nil)
+
+	var copied []repositories.CopyTodosParams
+	mockRepo.On("CopyTodos", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		copied = args.Get(1).([]repositories.CopyTodosParams)
+	}).Return(int64(2), nil)
+
+	var tagged []repositories.AddTodoTagsParams
+	mockRepo.On("AddTodoTags", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tagged = append(tagged, args.Get(1).(repositories.AddTodoTagsParams))
+	}).Return([]string{}, nil)
+
+	file := "(B) 2025-01-01 Call mom @phone @phone +family see http://example.com\n" +
+		"\n" +
+		"x 2025-01-03 2025-01-01 Renew passport due:2025-02-01 pri:C\n"
+
+	report, err := service.ImportTodos(context.Background(), strings.NewReader(file), todo.ImportRequest{Format: todo.ImportTodoTxt})
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	require.Len(t, copied, 2)
+
+	assert.Equal(t, "Call mom see http://example.com", copied[0].Title)
+	assert.Equal(t, "family", copied[0].Board)
+	assert.Equal(t, repositories.TodoStatusPending, copied[0].Status)
+	assert.False(t, copied[0].CompletedAt.Valid)
+	assert.Equal(t, time.Now().Format("2006-01-02"), copied[0].DueDate.Time.Format("2006-01-02"))
+
+	assert.Equal(t, "Renew passport", copied[1].Title)
+	assert.Equal(t, todo.DefaultBoard, copied[1].Board)
+	assert.Equal(t, repositories.TodoStatusCompleted, copied[1].Status)
+	assert.True(t, copied[1].CompletedAt.Valid)
+	assert.Equal(t, "2025-02-01", copied[1].DueDate.Time.Format("2006-01-02"))
+
+	require.Len(t, tagged, 2)
+	assert.Equal(t, []string{"phone", "pri:B"}, tagged[0].Tags)
+	assert.Equal(t, []string{"pri:C"}, tagged[1].Tags)
+}
+
+func TestImportTodos_MarkdownSkipsProse(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := todo.NewTodoService(mockRepo, new(MockRedisClient), FakeTransactor{mockRepo}, nil)
+
+	file := "# Week 12\n\nSome notes about the week.\n\n" +
+		"- [ ] Draft report due:2025-13-01\n" +
+		"- plain bullet\n" +
+		"## work\n" +
+		"* [X] Ship release #release\n" +
+		"  - [ ] Tag the build\n"
+
+	report, err := service.ImportTodos(context.Background(), strings.NewReader(file), todo.ImportRequest{Format: todo.ImportMarkdown, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Rows)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 5, report.Errors[0].Line)
+}
@@ -0,0 +1,225 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// PriorityTag prefixes the tag that holds the priority of a task, tasks have
+// no priority of their own. todo.txt tools use the same key to keep the
+// priority of completed tasks.
+const PriorityTag = "pri:"
+
+var (
+	priorityPattern = regexp.MustCompile(`^\(([A-Z])\)$`)
+	datePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// inlineTask is what a line of todo.txt or a Markdown checklist item says
+// about a task.
+type inlineTask struct {
+	title    string
+	board    string
+	due      string
+	rule     string
+	priority string
+	tags     []string
+}
+
+// parseInline reads the words of a task line. A leading "(A)" is the
+// priority, words starting with tagMark are tags, "+project" is the board
+// when boards is set, and due:, rrule: and pri: are fields. The other words
+// make up the title.
+func parseInline(words []string, tagMark byte, boards bool) (task inlineTask) {
+
+	if len(words) > 0 {
+		if match := priorityPattern.FindStringSubmatch(words[0]); match != nil {
+			task.priority = match[1]
+			words = words[1:]
+		}
+	}
+
+	var title []string
+
+	for _, word := range words {
+		key, value, _ := strings.Cut(word, ":")
+
+		switch {
+		case len(word) > 1 && word[0] == tagMark:
+			task.tags = append(task.tags, word[1:])
+		case boards && len(word) > 1 && word[0] == '+' && task.board == "":
+			task.board = word[1:]
+		case key == "due" && value != "":
+			task.due = value
+		case key == "rrule" && value != "":
+			task.rule = value
+		case key == "pri" && len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z':
+			task.priority = value
+		default:
+			title = append(title, word)
+		}
+	}
+
+	task.title = strings.Join(title, " ")
+
+	return
+}
+
+// row turns a parsed line into an import row, tasks without a due day are due
+// on today.
+func (task inlineTask) row(line int, completed bool, today string) importRow {
+
+	row := importRow{
+		line:      line,
+		completed: completed,
+		tags:      task.tags,
+		fields: map[string]string{
+			"title":           task.title,
+			"board":           task.board,
+			"due_date":        task.due,
+			"recurrence_rule": task.rule,
+		},
+	}
+
+	if task.due == "" {
+		row.fields["due_date"] = today
+	}
+
+	if task.priority != "" {
+		row.tags = append(row.tags, PriorityTag+task.priority)
+	}
+
+	slices.Sort(row.tags)
+	row.tags = slices.Compact(row.tags)
+
+	return row
+}
+
+// readTodoTxt reads a todo.txt file, one task per line. Completion and
+// creation dates are skipped, tasks do not keep them.
+func readTodoTxt(r io.Reader, today string) (rows []importRow, err error) {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+
+		if len(rows) == MaxImportRows {
+			err = ErrImportTooLong
+			return
+		}
+
+		completed := words[0] == "x"
+		if completed {
+			words = words[1:]
+		}
+
+		// a priority comes before the dates
+		var priority []string
+		if len(words) > 0 && priorityPattern.MatchString(words[0]) {
+			priority, words = words[:1], words[1:]
+		}
+
+		for i := 0; i < 2 && len(words) > 0 && datePattern.MatchString(words[0]); i++ {
+			words = words[1:]
+		}
+
+		task := parseInline(append(priority, words...), '@', true)
+		rows = append(rows, task.row(line, completed, today))
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+// priority is the priority a task has through its tags.
+func priority(todo Todo) (priority string, tags []string) {
+
+	for _, tag := range todo.Tags {
+		if value, ok := strings.CutPrefix(tag, PriorityTag); ok && priority == "" {
+			priority = value
+			continue
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return
+}
+
+// token makes text a single word, whitespace would split it when read back.
+func token(text string) string {
+	return strings.Join(strings.Fields(text), "-")
+}
+
+// formatInline writes the fields of a task after its title, the way
+// parseInline reads them.
+func formatInline(line *strings.Builder, todo Todo, tags []string, tagMark string) {
+
+	for _, tag := range tags {
+		line.WriteString(" " + tagMark + token(tag))
+	}
+
+	if todo.DueDate != "" {
+		line.WriteString(" due:" + todo.DueDate)
+	}
+
+	if todo.RecurrenceRule != "" {
+		line.WriteString(" rrule:" + token(todo.RecurrenceRule))
+	}
+}
+
+// formatTodoTxt writes a task as a todo.txt line. The board is its project,
+// unless it is the default one, and the description is dropped, todo.txt has
+// no place for it.
+func formatTodoTxt(todo Todo) string {
+
+	var line strings.Builder
+
+	pri, tags := priority(todo)
+
+	if todo.Status == "completed" {
+		line.WriteString("x ")
+	} else if pri != "" {
+		line.WriteString("(" + pri + ") ")
+	}
+
+	line.WriteString(todo.Title)
+
+	if todo.Board != "" && todo.Board != DefaultBoard {
+		line.WriteString(" +" + token(todo.Board))
+	}
+
+	formatInline(&line, todo, tags, "@")
+
+	if todo.Status == "completed" && pri != "" {
+		line.WriteString(" " + PriorityTag + pri)
+	}
+
+	return line.String()
+}
+
+type todoTxtEncoder struct {
+	w io.Writer
+}
+
+func (e *todoTxtEncoder) encode(todo Todo) error {
+	_, err := io.WriteString(e.w, formatTodoTxt(todo)+"\n")
+	return err
+}
+
+func (e *todoTxtEncoder) close() error {
+	return nil
+}
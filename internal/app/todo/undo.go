@@ -0,0 +1,277 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ilcs/internal/audit"
+	"ilcs/internal/constants"
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// UndoWindow is how long the token returned by a mutation can be used.
+const UndoWindow = 5 * time.Minute
+
+var (
+	ErrUndoNotFound = errors.New("undo token not found or expired")
+	ErrUndoConflict = errors.New("task was changed again since, it can no longer be undone")
+)
+
+// undoStep points at the audit entry of one task written by a mutation.
+type undoStep struct {
+	TaskID  string `json:"task_id"`
+	AuditID int64  `json:"audit_id"`
+}
+
+// undoToken is what an undo token refers to in Redis.
+type undoToken struct {
+	UserID string     `json:"user_id"`
+	Steps  []undoStep `json:"steps"`
+}
+
+// undoSteps collects the audit entries a mutation writes so they can be
+// handed out as an undo token once its transaction commits.
+type undoSteps []undoStep
+
+func (u *undoSteps) record(ctx context.Context, q repositories.Querier, action string, taskId pgtype.UUID, before, after any) error {
+
+	id, err := audit.Record(ctx, q, action, taskId, before, after)
+	if err != nil || id == 0 {
+		return err
+	}
+
+	*u = append(*u, undoStep{TaskID: taskId.String(), AuditID: id})
+
+	return nil
+}
+
+// issueUndo stores the steps of a committed mutation and returns the token
+// that undoes them. The mutation has already happened, so failing to store the
+// token only costs the undo and is logged.
+func (s *TodoService) issueUndo(ctx context.Context, steps undoSteps) (token string) {
+
+	if len(steps) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(undoToken{UserID: utils.GetUserId(ctx), Steps: steps})
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	id := uuid.NewString()
+
+	if err = s.redisDb.Set(ctx, constants.UNDO_KEY+id, data, UndoWindow).Err(); err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return id
+}
+
+// Undo reverts the mutation a token was issued for, provided none of its tasks
+// has been changed since. Steps are reverted newest first in one transaction,
+// so either the whole mutation is undone or nothing is.
+func (s *TodoService) Undo(ctx context.Context, token string) (todos []repositories.Todo, err error) {
+
+	key := constants.UNDO_KEY + token
+
+	val, err := s.redisDb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		err = ErrUndoNotFound
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	var data undoToken
+	if err = json.Unmarshal([]byte(val), &data); err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	// tokens are not shared, another user's token is as good as unknown
+	if data.UserID != utils.GetUserId(ctx) {
+		err = ErrUndoNotFound
+		log.Error().Err(err).Send()
+		return
+	}
+
+	err = s.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+		todos = nil
+
+		for i := len(data.Steps) - 1; i >= 0; i-- {
+			todo, err := revert(ctx, q, data.Steps[i])
+			if err != nil {
+				return err
+			}
+
+			todos = append(todos, todo)
+		}
+
+		return
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	ids := make([]string, 0, len(data.Steps))
+	for _, step := range data.Steps {
+		ids = append(ids, step.TaskID)
+	}
+
+	s.invalidate(ctx, ids...)
+
+	if err := s.redisDb.Del(ctx, key).Err(); err != nil {
+		log.Error().Err(err).Send()
+	}
+
+	return
+}
+
+// revert undoes one step, it is a conflict when the step is no longer the
+// latest change of its task.
+func revert(ctx context.Context, q repositories.Querier, step undoStep) (todo repositories.Todo, err error) {
+
+	uuidTodo, err := uuid.Parse(step.TaskID)
+	if err != nil {
+		return
+	}
+
+	taskId := pgtype.UUID{Valid: true, Bytes: uuidTodo}
+
+	// lock the task first so no other change can slip in after the check
+	current, err := q.LockTodo(ctx, taskId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrUndoConflict
+	}
+
+	if err != nil {
+		return
+	}
+
+	latest, err := q.GetLatestTaskAudit(ctx, taskId)
+	if err != nil {
+		return
+	}
+
+	if latest.ID != step.AuditID {
+		err = ErrUndoConflict
+		return
+	}
+
+	switch latest.Action {
+	case audit.ActionCreate, audit.ActionRestore:
+		todo, err = deleteTodo(ctx, q, &undoSteps{}, taskId)
+
+	case audit.ActionDelete:
+		row, errR := q.RestoreTodo(ctx, repositories.RestoreTodoParams{ID: taskId, UserID: callerId(ctx)})
+		if errR != nil {
+			err = errR
+			return
+		}
+
+		todo = current
+		todo.DeletedAt = pgtype.Timestamptz{}
+		todo.UpdatedAt = row.UpdatedAt
+
+		_, err = audit.Record(ctx, q, audit.ActionRestore, taskId, map[string]any{"deleted_at": row.PreviousDeletedAt}, map[string]any{"deleted_at": nil})
+		if err != nil {
+			return
+		}
+
+		err = enqueue(ctx, q, events.TaskRestored, taskId, todo)
+
+	case audit.ActionUpdate:
+		var changes map[string]audit.Change
+		if err = json.Unmarshal(latest.Changes, &changes); err != nil {
+			return
+		}
+
+		// tag operations only ever change the tags
+		if tags, ok := changes["tags"]; ok {
+			todo, err = revertTags(ctx, q, taskId, tags)
+			return
+		}
+
+		params, errR := revertParams(current, changes)
+		if errR != nil {
+			err = errR
+			return
+		}
+
+		todo, err = q.SetTodoFields(ctx, params)
+		if err != nil {
+			return
+		}
+
+		_, err = audit.Record(ctx, q, audit.ActionUpdate, taskId, current, todo)
+		if err != nil {
+			return
+		}
+
+		err = enqueue(ctx, q, events.TaskUpdated, taskId, todo)
+
+	default:
+		err = ErrUndoConflict
+	}
+
+	return
+}
+
+// revertParams puts the before value of every changed field back on the
+// current state of the task. Audit entries use the JSON names of the task
+// fields, so the round trip through JSON maps them back.
+func revertParams(current repositories.Todo, changes map[string]audit.Change) (params repositories.SetTodoFieldsParams, err error) {
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return
+	}
+
+	var fields map[string]any
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+
+	for name, change := range changes {
+		fields[name] = change.Before
+	}
+
+	if data, err = json.Marshal(fields); err != nil {
+		return
+	}
+
+	var reverted repositories.Todo
+	if err = json.Unmarshal(data, &reverted); err != nil {
+		return
+	}
+
+	params = repositories.SetTodoFieldsParams{
+		ID:             current.ID,
+		Title:          reverted.Title,
+		Description:    reverted.Description,
+		Status:         reverted.Status,
+		DueDate:        reverted.DueDate,
+		DueAt:          reverted.DueAt,
+		RecurrenceRule: reverted.RecurrenceRule,
+		CompletedAt:    reverted.CompletedAt,
+		Board:          reverted.Board,
+	}
+
+	return
+}
@@ -0,0 +1,154 @@
+package user
+
+import (
+	"errors"
+	"ilcs/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type IUserHandler interface {
+	GetSettings(c *gin.Context)
+	UpdateSettings(c *gin.Context)
+	GetPreferences(c *gin.Context)
+	UpdatePreferences(c *gin.Context)
+	Unsubscribe(c *gin.Context)
+	CreateTicket(c *gin.Context)
+}
+
+type UserHandler struct {
+	service IUserService
+}
+
+func NewUserHandler(service IUserService) *UserHandler {
+	return &UserHandler{
+		service: service,
+	}
+}
+
+func (h *UserHandler) GetSettings(c *gin.Context) {
+
+	settings, err := h.service.GetSettings(c)
+	if errors.Is(err, ErrNoUser) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, settings)
+}
+
+func (h *UserHandler) UpdateSettings(c *gin.Context) {
+
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c, req)
+	if errors.Is(err, ErrNoUser) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Settings updated successfully", "settings": settings})
+}
+
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+
+	preferences, err := h.service.GetPreferences(c)
+	if errors.Is(err, ErrNoUser) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, preferences)
+}
+
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(c, req)
+	if errors.Is(err, ErrNoUser) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Preferences updated successfully", "preferences": preferences})
+}
+
+func (h *UserHandler) Unsubscribe(c *gin.Context) {
+
+	var req UnsubscribeRequestParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.service.Unsubscribe(c, c.Param("token"), req.Kind)
+	if errors.Is(err, ErrUnknownUnsubscribe) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Unsubscribed successfully"})
+}
+
+func (h *UserHandler) CreateTicket(c *gin.Context) {
+
+	ticket, err := h.service.CreateTicket(c)
+	if errors.Is(err, ErrNoUser) {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, ticket)
+}
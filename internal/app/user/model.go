@@ -0,0 +1,31 @@
+package user
+
+type Settings struct {
+	UserID   string `json:"user_id"`
+	Timezone string `json:"timezone"`
+}
+
+type UpdateSettingsRequest struct {
+	Timezone string `json:"timezone" binding:"required,timezone"`
+}
+
+type Preferences struct {
+	Email          string `json:"email"`
+	EmailReminders bool   `json:"email_reminders"`
+	EmailDigest    bool   `json:"email_digest"`
+}
+
+type UpdatePreferencesRequest struct {
+	Email          string `json:"email" binding:"omitempty,email"`
+	EmailReminders *bool  `json:"email_reminders" binding:"required"`
+	EmailDigest    *bool  `json:"email_digest" binding:"required"`
+}
+
+type UnsubscribeRequestParams struct {
+	Kind string `form:"kind" binding:"omitempty,oneof=reminder digest all"`
+}
+
+type Ticket struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
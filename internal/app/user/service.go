@@ -0,0 +1,224 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// TicketTTL is how long a ticket can be used to open a stream or a board
+// socket, connections opened in time stay open after it.
+const TicketTTL = time.Minute
+
+var (
+	ErrNoUser             = errors.New("token is not bound to a user")
+	ErrUnknownUnsubscribe = errors.New("unknown unsubscribe link")
+)
+
+type IUserService interface {
+	GetSettings(ctx context.Context) (settings Settings, err error)
+	UpdateSettings(ctx context.Context, req UpdateSettingsRequest) (settings Settings, err error)
+	GetPreferences(ctx context.Context) (preferences Preferences, err error)
+	UpdatePreferences(ctx context.Context, req UpdatePreferencesRequest) (preferences Preferences, err error)
+	Unsubscribe(ctx context.Context, token string, kind string) (err error)
+	CreateTicket(ctx context.Context) (ticket Ticket, err error)
+}
+
+type UserService struct {
+	repo   repositories.Querier
+	tokens *utils.Tokens
+}
+
+func NewUserService(repo repositories.Querier, tokens *utils.Tokens) *UserService {
+	return &UserService{
+		repo:   repo,
+		tokens: tokens,
+	}
+}
+
+func currentUserId(ctx context.Context) (id pgtype.UUID, err error) {
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		err = ErrNoUser
+		return
+	}
+
+	id = pgtype.UUID{Valid: true, Bytes: userId}
+
+	return
+}
+
+func (s *UserService) GetSettings(ctx context.Context) (settings Settings, err error) {
+
+	id, err := currentUserId(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	settings = Settings{UserID: id.String(), Timezone: "UTC"}
+
+	user, err := s.repo.GetUserById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+		return
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	settings.Timezone = user.Timezone
+
+	return
+}
+
+func (s *UserService) UpdateSettings(ctx context.Context, req UpdateSettingsRequest) (settings Settings, err error) {
+
+	id, err := currentUserId(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	user, err := s.repo.UpsertUserTimezone(ctx, repositories.UpsertUserTimezoneParams{
+		ID:       id,
+		Timezone: req.Timezone,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	settings = Settings{UserID: user.ID.String(), Timezone: user.Timezone}
+
+	return
+}
+
+func toPreferences(user repositories.User) Preferences {
+	return Preferences{
+		Email:          user.Email.String,
+		EmailReminders: user.EmailReminders,
+		EmailDigest:    user.EmailDigest,
+	}
+}
+
+func (s *UserService) GetPreferences(ctx context.Context) (preferences Preferences, err error) {
+
+	id, err := currentUserId(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	preferences = Preferences{EmailReminders: true, EmailDigest: true}
+
+	user, err := s.repo.GetUserById(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+		return
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	preferences = toPreferences(user)
+
+	return
+}
+
+func (s *UserService) UpdatePreferences(ctx context.Context, req UpdatePreferencesRequest) (preferences Preferences, err error) {
+
+	id, err := currentUserId(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	// only used when the user has no unsubscribe token yet
+	token, err := uuid.NewRandom()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	user, err := s.repo.UpsertUserPreferences(ctx, repositories.UpsertUserPreferencesParams{
+		ID:               id,
+		Email:            pgtype.Text{String: req.Email, Valid: req.Email != ""},
+		EmailReminders:   *req.EmailReminders,
+		EmailDigest:      *req.EmailDigest,
+		UnsubscribeToken: pgtype.UUID{Bytes: token, Valid: true},
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	preferences = toPreferences(user)
+
+	return
+}
+
+func (s *UserService) Unsubscribe(ctx context.Context, token string, kind string) (err error) {
+
+	uuidToken, err := uuid.Parse(token)
+	if err != nil {
+		err = ErrUnknownUnsubscribe
+		return
+	}
+
+	if kind == "" {
+		kind = "all"
+	}
+
+	_, err = s.repo.UnsubscribeUser(ctx, repositories.UnsubscribeUserParams{
+		Kind:             kind,
+		UnsubscribeToken: pgtype.UUID{Bytes: uuidToken, Valid: true},
+	})
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrUnknownUnsubscribe
+		return
+	}
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+// CreateTicket issues a short-lived ticket of the caller for the browser APIs
+// that can't send the token in a header.
+func (s *UserService) CreateTicket(ctx context.Context) (ticket Ticket, err error) {
+
+	id, err := currentUserId(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	value, err := s.tokens.SignTicket(id.String(), TicketTTL)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	ticket = Ticket{Ticket: value, ExpiresIn: int(TicketTTL / time.Second)}
+
+	return
+}
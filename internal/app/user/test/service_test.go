@@ -0,0 +1,113 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/user"
+	"ilcs/internal/constants"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepo struct {
+	mock.Mock
+	repositories.Querier
+}
+
+func (m *MockRepo) GetUserById(ctx context.Context, id pgtype.UUID) (repositories.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repositories.User), args.Error(1)
+}
+
+func (m *MockRepo) UpsertUserTimezone(ctx context.Context, params repositories.UpsertUserTimezoneParams) (repositories.User, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(repositories.User), args.Error(1)
+}
+
+var tokens = utils.NewTokens("test-secret-that-is-long-enough-1234")
+
+func TestGetSettings_DefaultsToUTC(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := user.NewUserService(mockRepo, tokens)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	mockRepo.On("GetUserById", mock.Anything, pgtype.UUID{Bytes: userId, Valid: true}).Return(repositories.User{}, pgx.ErrNoRows)
+
+	settings, err := service.GetSettings(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, user.Settings{UserID: userId.String(), Timezone: "UTC"}, settings)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSettings_NoUser(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := user.NewUserService(mockRepo, tokens)
+
+	_, err := service.GetSettings(context.Background())
+
+	assert.ErrorIs(t, err, user.ErrNoUser)
+	mockRepo.AssertNotCalled(t, "GetUserById")
+}
+
+func TestUpdateSettings_Success(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := user.NewUserService(mockRepo, tokens)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	params := repositories.UpsertUserTimezoneParams{
+		ID:       pgtype.UUID{Bytes: userId, Valid: true},
+		Timezone: "Asia/Jakarta",
+	}
+
+	mockRepo.On("UpsertUserTimezone", mock.Anything, params).Return(repositories.User{ID: params.ID, Timezone: params.Timezone}, nil)
+
+	settings, err := service.UpdateSettings(ctx, user.UpdateSettingsRequest{Timezone: "Asia/Jakarta"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, user.Settings{UserID: userId.String(), Timezone: "Asia/Jakarta"}, settings)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTicket_OnlyValidAsTicket(t *testing.T) {
+	service := user.NewUserService(new(MockRepo), tokens)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	ticket, err := service.CreateTicket(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 60, ticket.ExpiresIn)
+
+	sub, err := tokens.TicketSubject(ticket.Ticket)
+	assert.NoError(t, err)
+	assert.Equal(t, userId.String(), sub)
+
+	// a ticket leaked through a URL is no API token
+	_, err = tokens.Subject(ticket.Ticket)
+	assert.ErrorIs(t, err, utils.ErrTicketAsToken)
+
+	token, _ := tokens.Sign(userId.String(), time.Hour)
+	_, err = tokens.TicketSubject(token)
+	assert.Error(t, err)
+}
+
+func TestCreateTicket_NoUser(t *testing.T) {
+	service := user.NewUserService(new(MockRepo), tokens)
+
+	_, err := service.CreateTicket(context.Background())
+
+	assert.ErrorIs(t, err, user.ErrNoUser)
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Dispatcher queues a delivery for every endpoint subscribed to an event. The
+// deliveries are sent, and retried, by Job.
+type Dispatcher struct {
+	repo repositories.Querier
+}
+
+func NewDispatcher(repo repositories.Querier) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event events.Event) error {
+
+	// anonymous tokens cannot register endpoints
+	userId, err := uuid.Parse(event.UserID)
+	if err != nil {
+		return nil
+	}
+
+	endpoints, err := d.repo.ListWebhookEndpointsForEvent(ctx, repositories.ListWebhookEndpointsForEventParams{
+		UserID: pgtype.UUID{Bytes: userId, Valid: true},
+		Event:  event.Type,
+	})
+
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+
+		_, err = d.repo.InsertWebhookDelivery(ctx, repositories.InsertWebhookDeliveryParams{
+			ID:         pgtype.UUID{Bytes: id, Valid: true},
+			EndpointID: endpoint.ID,
+			Event:      event.Type,
+			Payload:    payload,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
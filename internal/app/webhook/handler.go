@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"errors"
+	"ilcs/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type IWebhookHandler interface {
+	CreateEndpoint(c *gin.Context)
+	ListEndpoints(c *gin.Context)
+	DeleteEndpoint(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	SendTestEvent(c *gin.Context)
+}
+
+type WebhookHandler struct {
+	service IWebhookService
+}
+
+func NewWebhookHandler(service IWebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+func errorStatus(err error) int {
+
+	switch {
+	case errors.Is(err, ErrNoUser):
+		return 403
+	case errors.Is(err, ErrEndpointNotFound):
+		return 404
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrPrivateURL):
+		return 400
+	}
+
+	return 500
+}
+
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+
+	var req CreateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(400, gin.H{"error": utils.NewValidationError(errs)})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	endpoint, err := h.service.CreateEndpoint(c, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"message": "Webhook created successfully", "webhook": endpoint})
+}
+
+func (h *WebhookHandler) ListEndpoints(c *gin.Context) {
+
+	endpoints, err := h.service.ListEndpoints(c)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"webhooks": endpoints})
+}
+
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.service.DeleteEndpoint(c, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(c, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) SendTestEvent(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if err := utils.ValidateId(id); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	delivery, err := h.service.SendTestEvent(c, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"delivery": delivery})
+}
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"ilcs/internal/repositories"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxAttempts = 8
+	baseDelay   = 30 * time.Second
+	maxDelay    = 6 * time.Hour
+
+	// claimLease is how long a claimed batch is left to its worker, it has to
+	// outlast the sends of the whole batch.
+	claimLease = 5 * time.Minute
+)
+
+// backoff returns how long to wait before retrying a delivery that has failed
+// attempts times: 30s, 1m, 2m, ... capped at maxDelay.
+func backoff(attempts int32) time.Duration {
+
+	delay := baseDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+
+	return delay
+}
+
+// attemptParams records the outcome of one delivery attempt. attempts is the
+// number of attempts made before this one. Failed deliveries stay pending
+// with a backed off next attempt until maxAttempts is reached, unless retry
+// is false.
+func attemptParams(id pgtype.UUID, attempts int32, code int, sendErr error, retry bool, now time.Time) repositories.RecordWebhookAttemptParams {
+
+	params := repositories.RecordWebhookAttemptParams{
+		ID:            id,
+		Status:        repositories.WebhookDeliveryStatusSucceeded,
+		NextAttemptAt: pgtype.Timestamptz{Time: now, Valid: true},
+	}
+
+	if code != 0 {
+		params.LastResponseCode = pgtype.Int4{Int32: int32(code), Valid: true}
+	}
+
+	if sendErr == nil {
+		return params
+	}
+
+	params.LastError = pgtype.Text{String: sendErr.Error(), Valid: true}
+	params.Status = repositories.WebhookDeliveryStatusFailed
+
+	if retry && attempts+1 < maxAttempts {
+		params.Status = repositories.WebhookDeliveryStatusPending
+		params.NextAttemptAt = pgtype.Timestamptz{Time: now.Add(backoff(attempts + 1)), Valid: true}
+	}
+
+	return params
+}
+
+// Job sends pending webhook deliveries. Deliveries are claimed for a lease
+// with FOR UPDATE SKIP LOCKED, so several workers can run side by side, and
+// sent once the claim is committed so slow endpoints hold no row locks.
+type Job struct {
+	repo     repositories.Querier
+	sender   *Sender
+	interval time.Duration
+	batch    int32
+}
+
+func NewJob(db repositories.DBTX, sender *Sender, interval time.Duration) *Job {
+	return &Job{
+		repo:     repositories.New(db),
+		sender:   sender,
+		interval: interval,
+		batch:    20,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := j.Tick(ctx)
+		if err != nil {
+			log.Error().Err(err).Send()
+		} else if delivered > 0 {
+			log.Info().Int("delivered", delivered).Msg("Webhooks delivered")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick sends one batch of due deliveries and reports how many succeeded.
+func (j *Job) Tick(ctx context.Context) (delivered int, err error) {
+
+	deliveries, err := j.repo.ClaimWebhookDeliveries(ctx, repositories.ClaimWebhookDeliveriesParams{
+		LimitVal:     j.batch,
+		LeaseSeconds: int32(claimLease / time.Second),
+	})
+
+	if err != nil {
+		return
+	}
+
+	for _, item := range deliveries {
+
+		code, sendErr := j.sender.Send(ctx, item.Url, item.Secret, item.ID.String(), item.Event, item.Payload)
+		if sendErr != nil {
+			log.Error().Err(sendErr).Str("delivery_id", item.ID.String()).Send()
+		} else {
+			delivered++
+		}
+
+		_, err = j.repo.RecordWebhookAttempt(ctx, attemptParams(item.ID, item.Attempts, code, sendErr, true, time.Now()))
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"ilcs/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<payload>" keyed
+// with the endpoint secret. Covering the timestamp lets receivers reject
+// replayed deliveries.
+func Sign(secret string, timestamp int64, payload []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a sender that only connects to public addresses, the
+// endpoints are URLs given by users.
+func NewSender() *Sender {
+	return NewSenderWithClient(utils.PublicHTTPClient(10 * time.Second))
+}
+
+// NewSenderWithClient returns a sender that posts with client, e.g. one that
+// can reach receivers on loopback in tests.
+func NewSenderWithClient(client *http.Client) *Sender {
+	return &Sender{
+		client: client,
+	}
+}
+
+// Send posts a signed delivery and returns the response status code, which is
+// zero when the endpoint could not be reached. Non-2xx responses are errors.
+func (s *Sender) Send(ctx context.Context, url, secret, deliveryId, event string, payload []byte) (code int, err error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryId)
+	req.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(secret, timestamp, payload)))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	code = res.StatusCode
+
+	if code < 200 || code > 299 {
+		err = fmt.Errorf("webhook responded with status %d", code)
+	}
+
+	return
+}
@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// EventTest is the event type of deliveries sent by SendTestEvent.
+const EventTest = "webhook.test"
+
+var (
+	ErrNoUser           = errors.New("token is not bound to a user")
+	ErrInvalidURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrPrivateURL       = errors.New("webhook url must resolve to public addresses")
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+)
+
+type IWebhookService interface {
+	CreateEndpoint(ctx context.Context, req CreateEndpointRequest) (endpoint Endpoint, err error)
+	ListEndpoints(ctx context.Context) (endpoints []Endpoint, err error)
+	DeleteEndpoint(ctx context.Context, id string) (err error)
+	ListDeliveries(ctx context.Context, id string) (deliveries []Delivery, err error)
+	SendTestEvent(ctx context.Context, id string) (delivery Delivery, err error)
+}
+
+type WebhookService struct {
+	repo   repositories.Querier
+	sender *Sender
+}
+
+func NewWebhookService(repo repositories.Querier, sender *Sender) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		sender: sender,
+	}
+}
+
+func currentUser(ctx context.Context) (pgtype.UUID, error) {
+
+	userId, err := uuid.Parse(utils.GetUserId(ctx))
+	if err != nil {
+		return pgtype.UUID{}, ErrNoUser
+	}
+
+	return pgtype.UUID{Bytes: userId, Valid: true}, nil
+}
+
+func newSecret() (string, error) {
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func toEndpoint(data repositories.WebhookEndpoint) Endpoint {
+	return Endpoint{
+		ID:        data.ID.String(),
+		URL:       data.Url,
+		Events:    data.Events,
+		Active:    data.Active,
+		CreatedAt: data.CreatedAt.Time,
+	}
+}
+
+func toDelivery(data repositories.WebhookDelivery) Delivery {
+
+	delivery := Delivery{
+		ID:        data.ID.String(),
+		Event:     data.Event,
+		Status:    string(data.Status),
+		Attempts:  data.Attempts,
+		Error:     data.LastError.String,
+		CreatedAt: data.CreatedAt.Time,
+	}
+
+	if data.LastResponseCode.Valid {
+		delivery.ResponseCode = &data.LastResponseCode.Int32
+	}
+
+	if data.Status == repositories.WebhookDeliveryStatusPending {
+		delivery.NextAttemptAt = &data.NextAttemptAt.Time
+	}
+
+	if data.DeliveredAt.Valid {
+		delivery.DeliveredAt = &data.DeliveredAt.Time
+	}
+
+	return delivery
+}
+
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req CreateEndpointRequest) (endpoint Endpoint, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	u, err := url.ParseRequestURI(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		err = ErrInvalidURL
+		log.Error().Err(err).Send()
+		return
+	}
+
+	if err = utils.CheckPublicHost(ctx, u.Hostname()); err != nil {
+		log.Error().Err(err).Send()
+		err = ErrPrivateURL
+		return
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data, err := s.repo.InsertWebhookEndpoint(ctx, repositories.InsertWebhookEndpointParams{
+		ID:     pgtype.UUID{Bytes: id, Valid: true},
+		UserID: userId,
+		Url:    req.URL,
+		Secret: secret,
+		Events: req.Events,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	endpoint = toEndpoint(data)
+	endpoint.Secret = data.Secret
+
+	return
+}
+
+func (s *WebhookService) ListEndpoints(ctx context.Context) (endpoints []Endpoint, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data, err := s.repo.ListWebhookEndpointsByUser(ctx, userId)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	endpoints = []Endpoint{}
+	for _, item := range data {
+		endpoints = append(endpoints, toEndpoint(item))
+	}
+
+	return
+}
+
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id string) (err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	uuidEndpoint, err := uuid.Parse(id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	err = s.repo.DeleteWebhookEndpoint(ctx, repositories.DeleteWebhookEndpointParams{
+		ID:     pgtype.UUID{Bytes: uuidEndpoint, Valid: true},
+		UserID: userId,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	return
+}
+
+func (s *WebhookService) getEndpoint(ctx context.Context, id string) (endpoint repositories.WebhookEndpoint, err error) {
+
+	userId, err := currentUser(ctx)
+	if err != nil {
+		return
+	}
+
+	uuidEndpoint, err := uuid.Parse(id)
+	if err != nil {
+		return
+	}
+
+	endpoint, err = s.repo.GetWebhookEndpoint(ctx, repositories.GetWebhookEndpointParams{
+		ID:     pgtype.UUID{Bytes: uuidEndpoint, Valid: true},
+		UserID: userId,
+	})
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrEndpointNotFound
+	}
+
+	return
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, id string) (deliveries []Delivery, err error) {
+
+	endpoint, err := s.getEndpoint(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data, err := s.repo.ListWebhookDeliveries(ctx, repositories.ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		LimitVal:   50,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	deliveries = []Delivery{}
+	for _, item := range data {
+		deliveries = append(deliveries, toDelivery(item))
+	}
+
+	return
+}
+
+// SendTestEvent delivers a test event right away and returns the logged
+// attempt. Test deliveries are not retried.
+func (s *WebhookService) SendTestEvent(ctx context.Context, id string) (delivery Delivery, err error) {
+
+	endpoint, err := s.getEndpoint(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	event := events.New(EventTest, endpoint.UserID.String(), "", map[string]string{
+		"message": "This is a test event",
+	})
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	deliveryId, err := uuid.NewV7()
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	data, err := s.repo.InsertWebhookDelivery(ctx, repositories.InsertWebhookDeliveryParams{
+		ID:         pgtype.UUID{Bytes: deliveryId, Valid: true},
+		EndpointID: endpoint.ID,
+		Event:      EventTest,
+		Payload:    payload,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	code, sendErr := s.sender.Send(ctx, endpoint.Url, endpoint.Secret, data.ID.String(), EventTest, payload)
+
+	data, err = s.repo.RecordWebhookAttempt(ctx, attemptParams(data.ID, data.Attempts, code, sendErr, false, time.Now()))
+	if err != nil {
+		log.Error().Err(err).Send()
+		return
+	}
+
+	delivery = toDelivery(data)
+
+	return
+}
@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	// ActionPurge is recorded when a trashed task is deleted for good.
+	ActionPurge = "purge"
+)
+
+// ignored fields change on every write and would only add noise to the log.
+var ignored = map[string]bool{
+	"updated_at": true,
+}
+
+// Change is the value of a field before and after a mutation. Before is null
+// for created tasks and After is null for deleted ones.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff returns the fields whose JSON value differs between two snapshots of a
+// task. A nil snapshot has no fields.
+func Diff(before, after any) (changes map[string]Change, err error) {
+
+	beforeFields, err := fields(before)
+	if err != nil {
+		return
+	}
+
+	afterFields, err := fields(after)
+	if err != nil {
+		return
+	}
+
+	changes = map[string]Change{}
+
+	for name, value := range beforeFields {
+		if !ignored[name] && !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = Change{Before: value, After: afterFields[name]}
+		}
+	}
+
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok && !ignored[name] && value != nil {
+			changes[name] = Change{After: value}
+		}
+	}
+
+	return
+}
+
+func fields(snapshot any) (fields map[string]any, err error) {
+
+	if snapshot == nil || reflect.ValueOf(snapshot).IsZero() {
+		return
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &fields)
+
+	return
+}
+
+// Record appends the difference between two snapshots of a task to its audit
+// log, with the user and trace of the request as actor. Like events.Enqueue it
+// must use the queries of the transaction that writes the change. Updates that
+// change nothing are not recorded and return a zero id.
+func Record(ctx context.Context, q repositories.Querier, action string, taskId pgtype.UUID, before, after any) (id int64, err error) {
+
+	params, skip, err := entry(ctx, action, Mutation{TaskID: taskId, Before: before, After: after})
+	if err != nil || skip {
+		return
+	}
+
+	return q.InsertTaskAudit(ctx, params)
+}
+
+// Mutation is the change of one task recorded by RecordMany.
+type Mutation struct {
+	TaskID        pgtype.UUID
+	Before, After any
+}
+
+// RecordMany appends the entries of many tasks changed by the same action with
+// a single COPY, for imports too large to record row by row.
+func RecordMany(ctx context.Context, q repositories.Querier, action string, mutations []Mutation) error {
+
+	rows := make([]repositories.CopyTaskAuditParams, 0, len(mutations))
+
+	for _, mutation := range mutations {
+		params, skip, err := entry(ctx, action, mutation)
+		if err != nil {
+			return err
+		}
+
+		if !skip {
+			rows = append(rows, repositories.CopyTaskAuditParams(params))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	_, err := q.CopyTaskAudit(ctx, rows)
+
+	return err
+}
+
+func entry(ctx context.Context, action string, mutation Mutation) (params repositories.InsertTaskAuditParams, skip bool, err error) {
+
+	changes, err := Diff(mutation.Before, mutation.After)
+	if err != nil {
+		return
+	}
+
+	if len(changes) == 0 && action == ActionUpdate {
+		skip = true
+		return
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return
+	}
+
+	params = repositories.InsertTaskAuditParams{
+		TaskID:  mutation.TaskID,
+		Action:  action,
+		Changes: data,
+	}
+
+	if actor, err := uuid.Parse(utils.GetUserId(ctx)); err == nil {
+		params.Actor = pgtype.UUID{Bytes: actor, Valid: true}
+	}
+
+	if traceId := utils.GetTraceId(ctx); traceId != "" {
+		params.TraceID = pgtype.Text{String: traceId, Valid: true}
+	}
+
+	return
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"testing"
+
+	"ilcs/internal/audit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snapshot struct {
+	Title     string  `json:"title"`
+	Board     string  `json:"board"`
+	DueAt     *string `json:"due_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+func TestDiff_OnlyChangedFields(t *testing.T) {
+	changes, err := audit.Diff(
+		snapshot{Title: "a", Board: "inbox", UpdatedAt: "1"},
+		snapshot{Title: "b", Board: "inbox", UpdatedAt: "2"},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]audit.Change{"title": {Before: "a", After: "b"}}, changes)
+}
+
+func TestDiff_CreateAndDelete(t *testing.T) {
+	task := snapshot{Title: "a", Board: "inbox"}
+
+	created, err := audit.Diff(nil, task)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]audit.Change{
+		"title": {After: "a"},
+		"board": {After: "inbox"},
+	}, created)
+
+	deleted, err := audit.Diff(task, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]audit.Change{
+		"title": {Before: "a"},
+		"board": {Before: "inbox"},
+	}, deleted)
+}
@@ -0,0 +1,308 @@
+// Package client talks to the REST API, it is what the ilcs command line
+// client is built on.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Task is a task as the REST API answers it.
+type Task struct {
+	ID             string   `json:"id"`
+	Title          string   `json:"title"`
+	Description    string   `json:"description"`
+	Status         string   `json:"status"`
+	DueDate        string   `json:"due_date"`
+	RecurrenceRule string   `json:"recurrence_rule,omitempty"`
+	DueAt          string   `json:"due_at,omitempty"`
+	Board          string   `json:"board"`
+	Overdue        bool     `json:"overdue"`
+	Tags           []string `json:"tags,omitempty"`
+}
+
+// TaskInput is the body of POST /tasks and PUT /tasks/:id, Status is only
+// read by the update.
+type TaskInput struct {
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	Status         string  `json:"status,omitempty"`
+	DueDate        string  `json:"due_date,omitempty"`
+	DueAt          string  `json:"due_at,omitempty"`
+	RecurrenceRule *string `json:"recurrence_rule,omitempty"`
+	Board          string  `json:"board,omitempty"`
+}
+
+// ListOptions are the filters of GET /tasks, zero values are left out.
+type ListOptions struct {
+	Page   int
+	Limit  int
+	Status string
+	Search string
+	Board  string
+}
+
+func (o ListOptions) query() url.Values {
+
+	query := url.Values{}
+
+	if o.Page > 0 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	if o.Status != "" {
+		query.Set("status", o.Status)
+	}
+
+	if o.Search != "" {
+		query.Set("search", o.Search)
+	}
+
+	if o.Board != "" {
+		query.Set("board", o.Board)
+	}
+
+	return query
+}
+
+type TaskList struct {
+	Tasks      []Task `json:"tasks"`
+	Pagination struct {
+		CurrentPage int   `json:"current_page"`
+		TotalPage   int   `json:"total_page"`
+		TotalTasks  int64 `json:"total_tasks"`
+	} `json:"pagination"`
+}
+
+// Error is an answer of the API with an error status.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Status)
+}
+
+type Client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+// New returns a client of the API at server, token is sent as the bearer
+// token of every request and may be empty for the calls that need none.
+func New(server, token string) *Client {
+	return &Client{
+		server: strings.TrimRight(server, "/"),
+		token:  token,
+		http:   &http.Client{},
+	}
+}
+
+// do sends a request and decodes a JSON answer into out, an answer with an
+// error status becomes an Error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+
+	res, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := c.server + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 400 {
+		defer res.Body.Close()
+		return nil, readError(res)
+	}
+
+	return res, nil
+}
+
+// readError reads the error of an answer, a message or the validation errors
+// of the request.
+func readError(res *http.Response) error {
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err := json.Unmarshal(data, &body); err != nil || len(body.Error) == 0 {
+		return &Error{Status: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+	}
+
+	var message string
+	if err := json.Unmarshal(body.Error, &message); err == nil {
+		return &Error{Status: res.StatusCode, Message: message}
+	}
+
+	var fields []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body.Error, &fields); err == nil && len(fields) > 0 {
+		messages := make([]string, 0, len(fields))
+		for _, field := range fields {
+			messages = append(messages, field.Message)
+		}
+		return &Error{Status: res.StatusCode, Message: strings.Join(messages, "; ")}
+	}
+
+	return &Error{Status: res.StatusCode, Message: string(body.Error)}
+}
+
+// Login asks the API for a token of a new user.
+func (c *Client) Login(ctx context.Context) (token string, err error) {
+
+	var res struct {
+		Token string `json:"token"`
+	}
+
+	if err = c.do(ctx, http.MethodGet, "/api/v1/token", nil, nil, &res); err != nil {
+		return
+	}
+
+	return res.Token, nil
+}
+
+func (c *Client) ListTasks(ctx context.Context, opts ListOptions) (list TaskList, err error) {
+	err = c.do(ctx, http.MethodGet, "/api/v1/tasks", opts.query(), nil, &list)
+	return
+}
+
+func (c *Client) GetTask(ctx context.Context, id string) (task Task, err error) {
+	err = c.do(ctx, http.MethodGet, "/api/v1/tasks/"+url.PathEscape(id), nil, nil, &task)
+	return
+}
+
+// mutation is the answer of the calls that change a task.
+type mutation struct {
+	Task      Task   `json:"task"`
+	UndoToken string `json:"undo_token"`
+}
+
+func (c *Client) CreateTask(ctx context.Context, input TaskInput) (task Task, undo string, err error) {
+
+	var res mutation
+	if err = c.do(ctx, http.MethodPost, "/api/v1/tasks", nil, input, &res); err != nil {
+		return
+	}
+
+	return res.Task, res.UndoToken, nil
+}
+
+func (c *Client) UpdateTask(ctx context.Context, id string, input TaskInput) (task Task, undo string, err error) {
+
+	var res mutation
+	if err = c.do(ctx, http.MethodPut, "/api/v1/tasks/"+url.PathEscape(id), nil, input, &res); err != nil {
+		return
+	}
+
+	return res.Task, res.UndoToken, nil
+}
+
+// MoveTask moves a task to another board.
+func (c *Client) MoveTask(ctx context.Context, id, board string) (task Task, undo string, err error) {
+
+	var res mutation
+	body := map[string]string{"board": board}
+	if err = c.do(ctx, http.MethodPost, "/api/v1/tasks/"+url.PathEscape(id)+"/move", nil, body, &res); err != nil {
+		return
+	}
+
+	return res.Task, res.UndoToken, nil
+}
+
+func (c *Client) CompleteTask(ctx context.Context, id string) (task Task, undo string, err error) {
+
+	var res mutation
+	if err = c.do(ctx, http.MethodPost, "/api/v1/tasks/"+url.PathEscape(id)+"/complete", nil, nil, &res); err != nil {
+		return
+	}
+
+	return res.Task, res.UndoToken, nil
+}
+
+func (c *Client) DeleteTask(ctx context.Context, id string) (undo string, err error) {
+
+	var res mutation
+	if err = c.do(ctx, http.MethodDelete, "/api/v1/tasks/"+url.PathEscape(id), nil, nil, &res); err != nil {
+		return
+	}
+
+	return res.UndoToken, nil
+}
+
+// Export copies the export of the tasks matching opts to w, in one of the
+// formats of GET /tasks/export.
+func (c *Client) Export(ctx context.Context, w io.Writer, format string, opts ListOptions) error {
+
+	query := opts.query()
+	query.Del("page")
+	query.Del("limit")
+	query.Set("format", format)
+
+	res, err := c.send(ctx, http.MethodGet, "/api/v1/tasks/export", query, nil)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	_, err = io.Copy(w, res.Body)
+
+	return err
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// DefaultServer is the API the client talks to until login is given another.
+const DefaultServer = "http://localhost:8080"
+
+var ErrNotLoggedIn = errors.New("not logged in, run ilcs login first")
+
+// Config is what login stores: the API and the token to call it with.
+type Config struct {
+	Server string `json:"server"`
+	Token  string `json:"token"`
+}
+
+// ConfigPath is the config file in the user's config dir, ILCS_CONFIG
+// overrides it.
+func ConfigPath() (string, error) {
+
+	if path := os.Getenv("ILCS_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ilcs", "config.json"), nil
+}
+
+// LoadConfig reads the config file, a missing file is an empty config.
+func LoadConfig(path string) (config Config, err error) {
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{Server: DefaultServer}, nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(data, &config); err != nil {
+		return
+	}
+
+	if config.Server == "" {
+		config.Server = DefaultServer
+	}
+
+	return
+}
+
+// SaveConfig writes the config file, readable by the user only since it holds
+// the token.
+func SaveConfig(path string, config Config) error {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ilcs/internal/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/token", r.URL.Path)
+		assert.Empty(t, r.URL.RawQuery)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"token":"jwt"}`))
+	}))
+	defer server.Close()
+
+	token, err := client.New(server.URL+"/", "").Login(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", token)
+}
+
+func TestListTasks_SendsFiltersAndToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer jwt", r.Header.Get("Authorization"))
+		assert.Equal(t, "page=2&search=milk&status=pending", r.URL.RawQuery)
+		w.Write([]byte(`{"tasks":[{"id":"1","title":"Buy milk","tags":["home"]}],"pagination":{"current_page":2,"total_page":2,"total_tasks":11}}`))
+	}))
+	defer server.Close()
+
+	list, err := client.New(server.URL, "jwt").ListTasks(context.Background(), client.ListOptions{
+		Page:   2,
+		Status: "pending",
+		Search: "milk",
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Tasks, 1)
+	assert.Equal(t, "Buy milk", list.Tasks[0].Title)
+	assert.Equal(t, []string{"home"}, list.Tasks[0].Tags)
+	assert.Equal(t, int64(11), list.Pagination.TotalTasks)
+}
+
+func TestCreateTask_DecodesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		body    string
+		message string
+	}{
+		{`{"error":"Invalid token"}`, "Invalid token"},
+		{`{"error":[{"field":"title","message":"title is required"},{"field":"due_date","message":"due_date is required"}]}`, "title is required; due_date is required"},
+		{`not json`, "Bad Request"},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(400)
+			w.Write([]byte(tc.body))
+		}))
+
+		_, _, err := client.New(server.URL, "jwt").CreateTask(context.Background(), client.TaskInput{})
+		server.Close()
+
+		var apiErr *client.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 400, apiErr.Status)
+		assert.Equal(t, tc.message, apiErr.Message)
+	}
+}
+
+func TestUpdateTask_SendsRecurrenceRule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/api/v1/tasks/1", r.URL.Path)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "", body["recurrence_rule"])
+		assert.NotContains(t, body, "board")
+
+		w.Write([]byte(`{"message":"Task updated","task":{"id":"1","title":"Done"},"undo_token":"undo"}`))
+	}))
+	defer server.Close()
+
+	rule := ""
+	task, undo, err := client.New(server.URL, "jwt").UpdateTask(context.Background(), "1", client.TaskInput{
+		Title:          "Done",
+		Status:         "completed",
+		DueDate:        "2025-01-01",
+		RecurrenceRule: &rule,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Done", task.Title)
+	assert.Equal(t, "undo", undo)
+}
+
+func TestExport_SkipsPaging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/tasks/export", r.URL.Path)
+		assert.Equal(t, "board=work&format=csv", r.URL.RawQuery)
+		w.Write([]byte("id,title\n"))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := client.New(server.URL, "jwt").Export(context.Background(), &out, "csv", client.ListOptions{Page: 3, Limit: 5, Board: "work"})
+	require.NoError(t, err)
+	assert.Equal(t, "id,title\n", out.String())
+}
+
+func TestConfig_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ilcs", "config.json")
+
+	config, err := client.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, client.Config{Server: client.DefaultServer}, config)
+
+	config.Token = "jwt"
+	require.NoError(t, client.SaveConfig(path, config))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := client.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, config, loaded)
+}
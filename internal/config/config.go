@@ -0,0 +1,205 @@
+// Package config loads the settings of the server and the worker from the
+// environment and an optional YAML file, and checks them before anything
+// starts.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MinSecretLength is the shortest JWT_SECRET accepted, HS256 keys should have
+// at least 256 bits.
+const MinSecretLength = 32
+
+type Config struct {
+	HTTP     HTTP     `yaml:"http"`
+	Auth     Auth     `yaml:"auth"`
+	Database Database `yaml:"database"`
+	Redis    Redis    `yaml:"redis"`
+	SMTP     SMTP     `yaml:"smtp"`
+	Worker   Worker   `yaml:"worker"`
+	// AppURL is the base url of the links in emails.
+	AppURL string `yaml:"app_url" env:"APP_URL"`
+}
+
+type HTTP struct {
+	Port string `yaml:"port" env:"PORT"`
+	// GRPCPort serves the gRPC API when set.
+	GRPCPort string `yaml:"grpc_port" env:"GRPC_PORT"`
+}
+
+type Auth struct {
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET"`
+	// AdminUserIDs may use the /api/v1/admin endpoints.
+	AdminUserIDs []string `yaml:"admin_user_ids" env:"ADMIN_USER_IDS"`
+}
+
+// Database holds the pool settings, zero values keep the defaults of pgx.
+type Database struct {
+	URL string `yaml:"url" env:"GOOSE_DBSTRING"`
+	// AutoMigrate migrates the database on startup.
+	AutoMigrate       bool          `yaml:"auto_migrate" env:"AUTO_MIGRATE"`
+	MaxConns          int           `yaml:"max_conns" env:"DB_MAX_CONNS"`
+	MinConns          int           `yaml:"min_conns" env:"DB_MIN_CONNS"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" env:"DB_HEALTH_CHECK_PERIOD"`
+	ConnectTimeout    time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	// StatementTimeout is the statement_timeout of the connections, so the
+	// server stops any statement running longer. Migrations are not bound by it.
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// RequestTimeout bounds every query made for an HTTP request, the queries
+	// stop earlier when the client goes away.
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"DB_REQUEST_TIMEOUT"`
+}
+
+// Redis connects to one server, to a master through its sentinels when
+// SentinelMaster is set, or to a cluster.
+type Redis struct {
+	// Addrs is the server, the sentinels or the cluster nodes.
+	Addrs            []string      `yaml:"addrs" env:"REDIS_ADDR"`
+	Username         string        `yaml:"username" env:"REDIS_USERNAME"`
+	Password         string        `yaml:"password" env:"REDIS_PASSWORD"`
+	DB               int           `yaml:"db" env:"REDIS_DB"`
+	TLS              bool          `yaml:"tls" env:"REDIS_TLS"`
+	SentinelMaster   string        `yaml:"sentinel_master" env:"REDIS_SENTINEL_MASTER"`
+	SentinelPassword string        `yaml:"sentinel_password" env:"REDIS_SENTINEL_PASSWORD"`
+	Cluster          bool          `yaml:"cluster" env:"REDIS_CLUSTER"`
+	PoolSize         int           `yaml:"pool_size" env:"REDIS_POOL_SIZE"`
+	DialTimeout      time.Duration `yaml:"dial_timeout" env:"REDIS_DIAL_TIMEOUT"`
+	ReadTimeout      time.Duration `yaml:"read_timeout" env:"REDIS_READ_TIMEOUT"`
+	WriteTimeout     time.Duration `yaml:"write_timeout" env:"REDIS_WRITE_TIMEOUT"`
+}
+
+type SMTP struct {
+	Addr     string `yaml:"addr" env:"SMTP_ADDR"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+}
+
+type Worker struct {
+	// DigestDays is how many days ahead the daily digest looks.
+	DigestDays int `yaml:"digest_days" env:"DIGEST_DAYS"`
+	// DigestHour is the local hour users receive the daily digest at.
+	DigestHour int `yaml:"digest_hour" env:"DIGEST_HOUR"`
+	// TrashRetentionDays is how long deleted tasks stay in the trash.
+	TrashRetentionDays int `yaml:"trash_retention_days" env:"TRASH_RETENTION_DAYS"`
+}
+
+// Default is the config before the file and the environment are read.
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Port: "8080",
+		},
+		Worker: Worker{
+			DigestDays:         3,
+			DigestHour:         8,
+			TrashRetentionDays: 30,
+		},
+	}
+}
+
+// Validate reports every missing or malformed value at once, named after its
+// environment variable.
+func (c Config) Validate() error {
+
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(isPort(c.HTTP.Port), "PORT must be a port number, got %q", c.HTTP.Port)
+	check(c.HTTP.GRPCPort == "" || isPort(c.HTTP.GRPCPort), "GRPC_PORT must be a port number, got %q", c.HTTP.GRPCPort)
+
+	check(c.Auth.JWTSecret != "", "JWT_SECRET is required")
+	check(c.Auth.JWTSecret == "" || len(c.Auth.JWTSecret) >= MinSecretLength, "JWT_SECRET must be at least %d characters long", MinSecretLength)
+	for _, id := range c.Auth.AdminUserIDs {
+		_, err := uuid.Parse(id)
+		check(err == nil, "ADMIN_USER_IDS must be user ids, got %q", id)
+	}
+
+	if c.Database.URL == "" {
+		check(false, "GOOSE_DBSTRING is required")
+	} else {
+		_, err := pgxpool.ParseConfig(c.Database.URL)
+		check(err == nil, "GOOSE_DBSTRING is not a valid connection string: %v", err)
+	}
+
+	for _, n := range []struct {
+		name  string
+		value int
+	}{
+		{"DB_MAX_CONNS", c.Database.MaxConns},
+		{"DB_MIN_CONNS", c.Database.MinConns},
+		{"REDIS_DB", c.Redis.DB},
+		{"REDIS_POOL_SIZE", c.Redis.PoolSize},
+	} {
+		check(n.value >= 0, "%s must not be negative, got %d", n.name, n.value)
+	}
+	check(c.Database.MaxConns == 0 || c.Database.MinConns <= c.Database.MaxConns,
+		"DB_MIN_CONNS must not be above DB_MAX_CONNS, got %d and %d", c.Database.MinConns, c.Database.MaxConns)
+
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"DB_MAX_CONN_LIFETIME", c.Database.MaxConnLifetime},
+		{"DB_MAX_CONN_IDLE_TIME", c.Database.MaxConnIdleTime},
+		{"DB_HEALTH_CHECK_PERIOD", c.Database.HealthCheckPeriod},
+		{"DB_CONNECT_TIMEOUT", c.Database.ConnectTimeout},
+		{"DB_STATEMENT_TIMEOUT", c.Database.StatementTimeout},
+		{"DB_REQUEST_TIMEOUT", c.Database.RequestTimeout},
+		{"REDIS_DIAL_TIMEOUT", c.Redis.DialTimeout},
+		{"REDIS_READ_TIMEOUT", c.Redis.ReadTimeout},
+		{"REDIS_WRITE_TIMEOUT", c.Redis.WriteTimeout},
+	} {
+		check(d.value >= 0, "%s must not be negative, got %s", d.name, d.value)
+	}
+
+	check(len(c.Redis.Addrs) > 0, "REDIS_ADDR is required")
+	for _, addr := range c.Redis.Addrs {
+		check(isHostPort(addr), "REDIS_ADDR must be host:port, got %q", addr)
+	}
+	check(c.Redis.SentinelMaster == "" || !c.Redis.Cluster, "REDIS_SENTINEL_MASTER and REDIS_CLUSTER cannot be used together")
+	check(!c.Redis.Cluster || c.Redis.DB == 0, "REDIS_DB must be 0 with REDIS_CLUSTER")
+	check(c.Redis.Cluster || c.Redis.SentinelMaster != "" || len(c.Redis.Addrs) <= 1, "REDIS_ADDR has several addresses, set REDIS_SENTINEL_MASTER or REDIS_CLUSTER")
+
+	check(c.SMTP.Addr == "" || isHostPort(c.SMTP.Addr), "SMTP_ADDR must be host:port, got %q", c.SMTP.Addr)
+
+	if c.AppURL != "" {
+		u, err := url.Parse(c.AppURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "APP_URL must be an http(s) url, got %q", c.AppURL)
+	}
+
+	check(c.Worker.DigestDays >= 1, "DIGEST_DAYS must be at least 1, got %d", c.Worker.DigestDays)
+	check(c.Worker.DigestHour >= 0 && c.Worker.DigestHour <= 23, "DIGEST_HOUR must be between 0 and 23, got %d", c.Worker.DigestHour)
+	check(c.Worker.TrashRetentionDays >= 1, "TRASH_RETENTION_DAYS must be at least 1, got %d", c.Worker.TrashRetentionDays)
+
+	return errors.Join(errs...)
+}
+
+func isPort(value string) bool {
+
+	port, err := strconv.Atoi(value)
+
+	return err == nil && port > 0 && port <= 65535
+}
+
+func isHostPort(value string) bool {
+
+	_, port, err := net.SplitHostPort(value)
+
+	return err == nil && isPort(port)
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load returns the validated config: the defaults, overridden by the YAML file
+// at path (or CONFIG_FILE when path is empty), overridden by the environment.
+// A variable can also be read from the file named by <NAME>_FILE, which is how
+// secrets are usually mounted.
+func Load(path string) (config Config, err error) {
+
+	config = Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	if path != "" {
+		if err = readFile(path, &config); err != nil {
+			return
+		}
+	}
+
+	// a malformed variable keeps its default, so both are reported together
+	err = errors.Join(readEnv(reflect.ValueOf(&config).Elem()), config.Validate())
+
+	return
+}
+
+func readFile(path string, config *Config) error {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	// an empty file is an empty config
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readEnv sets the fields tagged with env from the environment, in the
+// structs of config too.
+func readEnv(config reflect.Value) error {
+
+	var errs []error
+
+	for i := 0; i < config.NumField(); i++ {
+
+		field := config.Field(i)
+		name := config.Type().Field(i).Tag.Get("env")
+
+		if field.Kind() == reflect.Struct && name == "" {
+			errs = append(errs, readEnv(field))
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+
+		value, ok, err := lookupEnv(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := setField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// lookupEnv reads name, or the file named by name_FILE. Empty variables are
+// unset, like the ones of an env file left blank.
+func lookupEnv(name string) (value string, ok bool, err error) {
+
+	value = os.Getenv(name)
+	ok = value != ""
+
+	file, fromFile := os.LookupEnv(name + "_FILE")
+	if !fromFile || file == "" {
+		return
+	}
+
+	if ok {
+		return "", false, fmt.Errorf("set %s or %s_FILE, not both", name, name)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+func setField(field reflect.Value, value string) error {
+
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+		field.SetBool(b)
+
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", value)
+		}
+		field.SetInt(int64(n))
+
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 30s, got %q", value)
+		}
+		field.SetInt(int64(d))
+
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+
+	default:
+		return fmt.Errorf("has an unsupported type %s", field.Type())
+	}
+
+	return nil
+}
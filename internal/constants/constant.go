@@ -3,4 +3,6 @@ package constants
 const (
 	TRACE_ID  = "trace_id"
 	CACHE_KEY = "todo:"
+	USER_ID   = "user_id"
+	UNDO_KEY  = "undo:"
 )
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const boardChannelPrefix = "events:board:"
+
+// BoardChannel is the pub/sub channel the events of a board are announced on.
+func BoardChannel(board string) string {
+	return boardChannelPrefix + board
+}
+
+// BoardFromChannel returns the board a channel made by BoardChannel belongs to.
+func BoardFromChannel(channel string) (board string, ok bool) {
+	return strings.CutPrefix(channel, boardChannelPrefix)
+}
+
+type BoardClient interface {
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
+}
+
+// BoardFeed announces task events to the viewers of the board the task is on.
+// A task moved between boards is announced on both, so viewers of the old
+// board can drop it.
+type BoardFeed struct {
+	client BoardClient
+}
+
+func NewBoardFeed(client BoardClient) *BoardFeed {
+	return &BoardFeed{
+		client: client,
+	}
+}
+
+func (f *BoardFeed) Publish(ctx context.Context, event Event) error {
+
+	boards := boardsOf(event.Data)
+	if len(boards) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	entry, err := json.Marshal(FeedEntry{ID: event.ID, Type: event.Type, Data: data})
+	if err != nil {
+		return err
+	}
+
+	for _, board := range boards {
+		if err := f.client.Publish(ctx, BoardChannel(board), entry).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// boardsOf reads the board, and previous_board of moved tasks, from the data
+// of an event.
+func boardsOf(data any) (boards []string) {
+
+	var fields struct {
+		Board         string `json:"board"`
+		PreviousBoard string `json:"previous_board"`
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return
+	}
+
+	if fields.Board != "" {
+		boards = append(boards, fields.Board)
+	}
+
+	if fields.PreviousBoard != "" && fields.PreviousBoard != fields.Board {
+		boards = append(boards, fields.PreviousBoard)
+	}
+
+	return
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TaskCreated   = "task.created"
+	TaskUpdated   = "task.updated"
+	TaskCompleted = "task.completed"
+	TaskDeleted   = "task.deleted"
+	TaskRestored  = "task.restored"
+)
+
+// TaskEvents lists the event types users can subscribe to.
+var TaskEvents = []string{TaskCreated, TaskUpdated, TaskCompleted, TaskDeleted, TaskRestored}
+
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	TaskID     string    `json:"task_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data,omitempty"`
+	// UserID is the user the event is delivered to.
+	UserID string `json:"-"`
+}
+
+func New(eventType, userId, taskId string, data any) Event {
+
+	event := Event{
+		Type:       eventType,
+		TaskID:     taskId,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+		UserID:     userId,
+	}
+
+	if id, err := uuid.NewV7(); err == nil {
+		event.ID = id.String()
+	}
+
+	return event
+}
+
+// Publisher hands an event to its subscribers.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
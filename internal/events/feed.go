@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// FeedKey is the Redis stream holding the recent events of a user.
+func FeedKey(userId string) string {
+	return "events:user:" + userId
+}
+
+// FeedChannel is the pub/sub channel new feed entries are announced on.
+func FeedChannel(userId string) string {
+	return "events:user:" + userId + ":live"
+}
+
+// FeedEntry is an event as stored in a user feed. ID is the Redis stream id,
+// which increases monotonically and doubles as the SSE event id.
+type FeedEntry struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type FeedClient interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
+}
+
+// UserFeed keeps a bounded log of every user's task events in Redis and
+// announces new entries over pub/sub, so live connections on any replica
+// receive them and reconnecting clients can resume from the log.
+type UserFeed struct {
+	client FeedClient
+	maxLen int64
+}
+
+func NewUserFeed(client FeedClient) *UserFeed {
+	return &UserFeed{
+		client: client,
+		maxLen: 1000,
+	}
+}
+
+func (f *UserFeed) Publish(ctx context.Context, event Event) error {
+
+	if event.UserID == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	id, err := f.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: FeedKey(event.UserID),
+		MaxLen: f.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type": event.Type,
+			"data": data,
+		},
+	}).Result()
+
+	if err != nil {
+		return err
+	}
+
+	entry, err := json.Marshal(FeedEntry{ID: id, Type: event.Type, Data: data})
+	if err != nil {
+		return err
+	}
+
+	return f.client.Publish(ctx, FeedChannel(event.UserID), entry).Err()
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Enqueue stores an event in the outbox. It must use the queries of the
+// transaction that writes the change, so the event is published by the Relay
+// if and only if the change is committed.
+func Enqueue(ctx context.Context, q repositories.Querier, event Event) error {
+
+	params, err := outboxParams(event)
+	if err != nil {
+		return err
+	}
+
+	return q.InsertOutboxEvent(ctx, params)
+}
+
+// EnqueueMany stores events in the outbox with a single COPY, in order.
+func EnqueueMany(ctx context.Context, q repositories.Querier, events []Event) error {
+
+	rows := make([]repositories.CopyOutboxEventsParams, 0, len(events))
+
+	for _, event := range events {
+		params, err := outboxParams(event)
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, repositories.CopyOutboxEventsParams(params))
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	_, err := q.CopyOutboxEvents(ctx, rows)
+
+	return err
+}
+
+func outboxParams(event Event) (params repositories.InsertOutboxEventParams, err error) {
+
+	eventId, err := uuid.Parse(event.ID)
+	if err != nil {
+		return
+	}
+
+	taskId, err := uuid.Parse(event.TaskID)
+	if err != nil {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	params = repositories.InsertOutboxEventParams{
+		EventID: pgtype.UUID{Bytes: eventId, Valid: true},
+		Type:    event.Type,
+		TaskID:  pgtype.UUID{Bytes: taskId, Valid: true},
+		Payload: payload,
+	}
+
+	if userId, err := uuid.Parse(event.UserID); err == nil {
+		params.UserID = pgtype.UUID{Bytes: userId, Valid: true}
+	}
+
+	return
+}
+
+func fromOutbox(row repositories.Outbox) (event Event, err error) {
+
+	err = json.Unmarshal(row.Payload, &event)
+	if err != nil {
+		return
+	}
+
+	if row.UserID.Valid {
+		event.UserID = row.UserID.String()
+	}
+
+	return
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TaskStream is the Redis stream task events are appended to.
+const TaskStream = "events:tasks"
+
+type StreamClient interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+}
+
+// RedisStream appends events to a capped Redis stream. Consumers should
+// deduplicate on the event id since delivery is at least once.
+type RedisStream struct {
+	client StreamClient
+	maxLen int64
+}
+
+func NewRedisStream(client StreamClient) *RedisStream {
+	return &RedisStream{
+		client: client,
+		maxLen: 10000,
+	}
+}
+
+func (r *RedisStream) Publish(ctx context.Context, event Event) error {
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: TaskStream,
+		MaxLen: r.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"id":      event.ID,
+			"type":    event.Type,
+			"task_id": event.TaskID,
+			"user_id": event.UserID,
+			"payload": payload,
+		},
+	}).Err()
+}
@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"ilcs/internal/repositories"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// Relay publishes outbox events to the sinks at least once. Only one relay
+// dispatches at a time (guarded by an advisory lock) and events are published
+// in insertion order, so the events of a task arrive in the order they were
+// written. A failing sink stops the batch; it is retried on the next tick and
+// sinks that already received the events may see them again.
+type Relay struct {
+	tx        repositories.Transactor
+	sinks     []Publisher
+	interval  time.Duration
+	retention time.Duration
+	batch     int32
+}
+
+func NewRelay(tx repositories.Transactor, sinks []Publisher, interval time.Duration) *Relay {
+	return &Relay{
+		tx:        tx,
+		sinks:     sinks,
+		interval:  interval,
+		retention: 24 * time.Hour,
+		batch:     100,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		dispatched, err := r.Tick(ctx)
+		if err != nil {
+			log.Error().Err(err).Send()
+		}
+
+		// keep draining while the outbox is backed up
+		if err == nil && dispatched == int(r.batch) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick publishes one batch of pending events and reports how many were
+// dispatched.
+func (r *Relay) Tick(ctx context.Context) (dispatched int, err error) {
+
+	err = r.tx.InTx(ctx, func(q repositories.Querier) (err error) {
+
+		locked, err := q.LockOutboxRelay(ctx)
+		if err != nil || !locked {
+			return
+		}
+
+		rows, err := q.ListPendingOutboxEvents(ctx, r.batch)
+		if err != nil {
+			return
+		}
+
+		var ids []int64
+
+		// events published before a failure are still marked as dispatched
+		defer func() {
+			if len(ids) == 0 {
+				return
+			}
+
+			if errM := q.MarkOutboxDispatched(ctx, ids); errM != nil && err == nil {
+				err = errM
+			}
+
+			if err == nil {
+				dispatched = len(ids)
+			}
+		}()
+
+		for _, row := range rows {
+
+			event, errD := fromOutbox(row)
+			if errD != nil {
+				// an undecodable row would block the outbox forever
+				log.Error().Err(errD).Int64("outbox_id", row.ID).Send()
+				ids = append(ids, row.ID)
+				continue
+			}
+
+			for _, sink := range r.sinks {
+				if errP := sink.Publish(ctx, event); errP != nil {
+					log.Error().Err(errP).Int64("outbox_id", row.ID).Send()
+					return
+				}
+			}
+
+			ids = append(ids, row.ID)
+		}
+
+		return q.PurgeDispatchedOutbox(ctx, pgtype.Timestamptz{Time: time.Now().Add(-r.retention), Valid: true})
+	})
+
+	if err != nil {
+		dispatched = 0
+	}
+
+	return
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepo struct {
+	mock.Mock
+	repositories.Querier
+}
+
+func (m *MockRepo) LockOutboxRelay(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepo) ListPendingOutboxEvents(ctx context.Context, limitVal int32) ([]repositories.Outbox, error) {
+	args := m.Called(ctx, limitVal)
+	return args.Get(0).([]repositories.Outbox), args.Error(1)
+}
+
+func (m *MockRepo) MarkOutboxDispatched(ctx context.Context, ids []int64) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockRepo) PurgeDispatchedOutbox(ctx context.Context, dispatchedAt pgtype.Timestamptz) error {
+	args := m.Called(ctx, dispatchedAt)
+	return args.Error(0)
+}
+
+type FakeTransactor struct {
+	repo repositories.Querier
+}
+
+func (f FakeTransactor) InTx(ctx context.Context, fn func(q repositories.Querier) error) error {
+	return fn(f.repo)
+}
+
+// FakeSink records events and fails for the event ids in failOn.
+type FakeSink struct {
+	events []events.Event
+	failOn map[string]bool
+}
+
+func (f *FakeSink) Publish(ctx context.Context, event events.Event) error {
+	if f.failOn[event.ID] {
+		return errors.New("sink unavailable")
+	}
+
+	f.events = append(f.events, event)
+	return nil
+}
+
+func outboxRow(t *testing.T, id int64, eventType string, taskId, userId uuid.UUID) repositories.Outbox {
+
+	event := events.New(eventType, userId.String(), taskId.String(), map[string]string{"title": "Write docs"})
+
+	payload, err := json.Marshal(event)
+	assert.NoError(t, err)
+
+	return repositories.Outbox{
+		ID:      id,
+		EventID: pgtype.UUID{Bytes: uuid.MustParse(event.ID), Valid: true},
+		Type:    eventType,
+		TaskID:  pgtype.UUID{Bytes: taskId, Valid: true},
+		UserID:  pgtype.UUID{Bytes: userId, Valid: true},
+		Payload: payload,
+	}
+}
+
+func TestRelayTick_PublishesInOrderAndMarksDispatched(t *testing.T) {
+	mockRepo := new(MockRepo)
+	sink := &FakeSink{}
+	relay := events.NewRelay(FakeTransactor{mockRepo}, []events.Publisher{sink}, time.Second)
+
+	taskId := uuid.New()
+	userId := uuid.New()
+
+	rows := []repositories.Outbox{
+		outboxRow(t, 1, events.TaskCreated, taskId, userId),
+		outboxRow(t, 2, events.TaskUpdated, taskId, userId),
+	}
+
+	mockRepo.On("LockOutboxRelay", mock.Anything).Return(true, nil)
+	mockRepo.On("ListPendingOutboxEvents", mock.Anything, int32(100)).Return(rows, nil)
+	mockRepo.On("MarkOutboxDispatched", mock.Anything, []int64{1, 2}).Return(nil)
+	mockRepo.On("PurgeDispatchedOutbox", mock.Anything, mock.Anything).Return(nil)
+
+	dispatched, err := relay.Tick(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dispatched)
+	assert.Len(t, sink.events, 2)
+	assert.Equal(t, events.TaskCreated, sink.events[0].Type)
+	assert.Equal(t, events.TaskUpdated, sink.events[1].Type)
+	assert.Equal(t, userId.String(), sink.events[0].UserID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRelayTick_StopsAtFailingEvent(t *testing.T) {
+	mockRepo := new(MockRepo)
+
+	taskId := uuid.New()
+	userId := uuid.New()
+
+	rows := []repositories.Outbox{
+		outboxRow(t, 1, events.TaskCreated, taskId, userId),
+		outboxRow(t, 2, events.TaskUpdated, taskId, userId),
+		outboxRow(t, 3, events.TaskDeleted, taskId, userId),
+	}
+
+	var failing events.Event
+	assert.NoError(t, json.Unmarshal(rows[1].Payload, &failing))
+
+	sink := &FakeSink{failOn: map[string]bool{failing.ID: true}}
+	relay := events.NewRelay(FakeTransactor{mockRepo}, []events.Publisher{sink}, time.Second)
+
+	mockRepo.On("LockOutboxRelay", mock.Anything).Return(true, nil)
+	mockRepo.On("ListPendingOutboxEvents", mock.Anything, mock.Anything).Return(rows, nil)
+	mockRepo.On("MarkOutboxDispatched", mock.Anything, []int64{1}).Return(nil)
+
+	dispatched, err := relay.Tick(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, dispatched)
+	assert.Len(t, sink.events, 1)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "PurgeDispatchedOutbox", mock.Anything, mock.Anything)
+}
+
+func TestRelayTick_SkipsWhenAnotherRelayHoldsTheLock(t *testing.T) {
+	mockRepo := new(MockRepo)
+	sink := &FakeSink{}
+	relay := events.NewRelay(FakeTransactor{mockRepo}, []events.Publisher{sink}, time.Second)
+
+	mockRepo.On("LockOutboxRelay", mock.Anything).Return(false, nil)
+
+	dispatched, err := relay.Tick(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+	mockRepo.AssertNotCalled(t, "ListPendingOutboxEvents", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,98 @@
+// Package graph serves the tasks over GraphQL, so a client can read tasks
+// with their tags, reminders and history in one request.
+package graph
+
+import (
+	"context"
+	"errors"
+	"ilcs/internal/repositories"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// MaxRequestBytes caps the body of a GraphQL request.
+const MaxRequestBytes = 64 << 10
+
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type IGraphHandler interface {
+	Query(c *gin.Context)
+}
+
+type GraphHandler struct {
+	schema graphql.Schema
+	repo   repositories.Querier
+}
+
+func NewGraphHandler(schema graphql.Schema, repo repositories.Querier) *GraphHandler {
+	return &GraphHandler{
+		schema: schema,
+		repo:   repo,
+	}
+}
+
+// Execute runs a request. The query is checked against MaxDepth and
+// MaxComplexity before anything is resolved, and every request gets loaders
+// of its own.
+func (h *GraphHandler) Execute(ctx context.Context, req Request) *graphql.Result {
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if err := checkLimits(doc, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, NewLoaders(h.repo)),
+	})
+}
+
+func (h *GraphHandler) Query(c *gin.Context) {
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxRequestBytes)
+
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(413, gin.H{"error": "request is too large"})
+			return
+		}
+
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := h.Execute(c, req)
+
+	// a request that could not run at all has no data
+	if result.Data == nil && result.HasErrors() {
+		c.JSON(400, result)
+		return
+	}
+
+	c.JSON(200, result)
+}
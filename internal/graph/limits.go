@@ -0,0 +1,190 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// MaxDepth is how deeply fields may be nested, introspection fields are
+	// not counted.
+	MaxDepth = 8
+	// MaxComplexity caps the cost of a query: every field costs 1, and the
+	// fields below a connection cost as many times as the page has tasks.
+	MaxComplexity = 1000
+	// MaxFirst is the largest page of a connection.
+	MaxFirst = 100
+	// DefaultFirst is the page size of a connection queried without first.
+	DefaultFirst = 10
+)
+
+var (
+	ErrTooDeep    = errors.New("query is too deep")
+	ErrTooComplex = errors.New("query is too complex")
+)
+
+// limits measures the operations of a validated document.
+type limits struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits rejects the operation of doc that nests fields deeper than
+// MaxDepth or costs more than MaxComplexity. Documents are validated first,
+// so fragments are known and not cyclic.
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+
+	l := limits{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+	}
+
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			l.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if operationName != "" && (operation.Name == nil || operation.Name.Value != operationName) {
+			continue
+		}
+
+		cost, err := l.cost(operation.SelectionSet, 0)
+		if err != nil {
+			return err
+		}
+
+		if cost > MaxComplexity {
+			return fmt.Errorf("%w: costs %d, the limit is %d", ErrTooComplex, cost, MaxComplexity)
+		}
+	}
+
+	return nil
+}
+
+func (l limits) cost(set *ast.SelectionSet, depth int) (cost int, err error) {
+
+	if set == nil {
+		return
+	}
+
+	for _, selection := range set.Selections {
+
+		var c int
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+
+			if depth+1 > MaxDepth {
+				return 0, fmt.Errorf("%w: the limit is %d levels", ErrTooDeep, MaxDepth)
+			}
+
+			c, err = l.cost(selection.SelectionSet, depth+1)
+			if err != nil {
+				return
+			}
+
+			c = 1 + c*l.multiplier(selection)
+
+		case *ast.InlineFragment:
+			c, err = l.cost(selection.SelectionSet, depth)
+			if err != nil {
+				return
+			}
+
+		case *ast.FragmentSpread:
+			if fragment, ok := l.fragments[selection.Name.Value]; ok {
+				c, err = l.cost(fragment.SelectionSet, depth)
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		cost += c
+		if cost > MaxComplexity {
+			return 0, fmt.Errorf("%w: the limit is %d", ErrTooComplex, MaxComplexity)
+		}
+	}
+
+	return
+}
+
+// multiplier is how many times the fields below field are resolved: the page
+// size for connections, once for every other field.
+func (l limits) multiplier(field *ast.Field) int {
+
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+
+		return clampFirst(l.value(argument.Value))
+	}
+
+	if field.SelectionSet != nil && hasField(field.SelectionSet, "edges") {
+		return DefaultFirst
+	}
+
+	return 1
+}
+
+func (l limits) value(value ast.Value) int {
+
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, _ := strconv.Atoi(value.Value)
+		return n
+	case *ast.Variable:
+		switch v := l.variables[value.Name.Value].(type) {
+		case int:
+			return v
+		case float64:
+			return int(v)
+		case json.Number:
+			n, _ := v.Int64()
+			return int(n)
+		}
+	}
+
+	return DefaultFirst
+}
+
+func hasField(set *ast.SelectionSet, name string) bool {
+
+	for _, selection := range set.Selections {
+		if field, ok := selection.(*ast.Field); ok && field.Name.Value == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clampFirst gives the page size a connection is resolved with.
+func clampFirst(first int) int {
+
+	if first <= 0 {
+		return DefaultFirst
+	}
+
+	if first > MaxFirst {
+		return MaxFirst
+	}
+
+	return first
+}
@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"context"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Loader batches the loads of one request. Load only records the key and
+// returns a thunk: the executor calls the thunks of a level once all its
+// fields are resolved, and the first one fetches every recorded key with a
+// single query. Keys fetch leaves out get the zero value.
+type Loader[K comparable, V any] struct {
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error)
+	mu      sync.Mutex
+	pending map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		pending: map[K]bool{},
+		values:  map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (interface{}, error) {
+
+	l.mu.Lock()
+	_, done := l.values[key]
+	if !done {
+		l.pending[key] = true
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		return l.get(ctx, key)
+	}
+}
+
+func (l *Loader[K, V]) get(ctx context.Context, key K) (value V, err error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pending[key] {
+		keys := make([]K, 0, len(l.pending))
+		for k := range l.pending {
+			keys = append(keys, k)
+		}
+		l.pending = map[K]bool{}
+
+		values, errF := l.fetch(ctx, keys)
+
+		for _, k := range keys {
+			if errF != nil {
+				l.errs[k] = errF
+				continue
+			}
+			l.values[k] = values[k]
+		}
+	}
+
+	if err = l.errs[key]; err != nil {
+		return
+	}
+
+	return l.values[key], nil
+}
+
+// Loaders are the loaders of one request, the fields of a task that would
+// otherwise be queried once per task.
+type Loaders struct {
+	Tags      *Loader[string, []string]
+	Reminders *Loader[string, []repositories.Reminder]
+	History   *Loader[string, []repositories.TaskAudit]
+}
+
+func NewLoaders(repo repositories.Querier) *Loaders {
+	return &Loaders{
+		Tags: NewLoader(func(ctx context.Context, ids []string) (map[string][]string, error) {
+
+			data, err := repo.ListTagsByTodos(ctx, toUUIDs(ids))
+			if err != nil {
+				return nil, err
+			}
+
+			tags := map[string][]string{}
+			for _, id := range ids {
+				tags[id] = []string{}
+			}
+
+			for _, item := range data {
+				tags[item.TodoID.String()] = append(tags[item.TodoID.String()], item.Tag)
+			}
+
+			return tags, nil
+		}),
+		Reminders: NewLoader(func(ctx context.Context, ids []string) (map[string][]repositories.Reminder, error) {
+
+			// only the reminders the caller set
+			var userId pgtype.UUID
+			if id, err := uuid.Parse(utils.GetUserId(ctx)); err == nil {
+				userId = pgtype.UUID{Bytes: id, Valid: true}
+			}
+
+			data, err := repo.ListRemindersByTodos(ctx, repositories.ListRemindersByTodosParams{
+				TodoIds: toUUIDs(ids),
+				UserID:  userId,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			reminders := map[string][]repositories.Reminder{}
+			for _, id := range ids {
+				reminders[id] = []repositories.Reminder{}
+			}
+
+			for _, item := range data {
+				reminders[item.TodoID.String()] = append(reminders[item.TodoID.String()], item)
+			}
+
+			return reminders, nil
+		}),
+		History: NewLoader(func(ctx context.Context, ids []string) (map[string][]repositories.TaskAudit, error) {
+
+			data, err := repo.ListTaskHistories(ctx, toUUIDs(ids))
+			if err != nil {
+				return nil, err
+			}
+
+			history := map[string][]repositories.TaskAudit{}
+			for _, id := range ids {
+				history[id] = []repositories.TaskAudit{}
+			}
+
+			for _, item := range data {
+				history[item.TaskID.String()] = append(history[item.TaskID.String()], item)
+			}
+
+			return history, nil
+		}),
+	}
+}
+
+// toUUIDs converts the task ids of a batch, they are ids of tasks read from
+// the database so none is invalid.
+func toUUIDs(ids []string) []pgtype.UUID {
+
+	values := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		if parsed, err := uuid.Parse(id); err == nil {
+			values = append(values, pgtype.UUID{Bytes: parsed, Valid: true})
+		}
+	}
+
+	return values
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
@@ -0,0 +1,534 @@
+package graph
+
+import (
+	"errors"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/repositories"
+	"ilcs/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Error is an error answered with a code in its extensions, so clients can
+// tell bad input from missing tasks without parsing messages.
+type Error struct {
+	Code string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// toError gives the code of the errors the REST API answers with a 4xx.
+func toError(err error) error {
+
+	var errs validator.ValidationErrors
+
+	switch {
+	case errors.As(err, &errs),
+		errors.Is(err, todo.ErrInvalidRecurrenceRule),
+		errors.Is(err, todo.ErrInvalidBoard),
+		errors.Is(err, todo.ErrInvalidCursor):
+		return &Error{Code: "BAD_USER_INPUT", Err: err}
+	case errors.Is(err, pgx.ErrNoRows):
+		return &Error{Code: "NOT_FOUND", Err: errors.New("task not found")}
+	}
+
+	return &Error{Code: "INTERNAL", Err: err}
+}
+
+// connection is a page of tasks, with the total of tasks matching the filters.
+type connection struct {
+	edges   []todo.TodoEdge
+	hasNext bool
+	total   int64
+}
+
+// payload answers a mutation with the task as it is after it, and the token
+// that reverts it.
+type payload struct {
+	task todo.Todo
+	undo string
+}
+
+type deletePayload struct {
+	id   string
+	undo string
+}
+
+// optional turns the empty values of the REST fields into nulls.
+func optional(value string) interface{} {
+
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+func timestamp(t pgtype.Timestamptz) interface{} {
+
+	if !t.Valid {
+		return nil
+	}
+
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+func resolve[T any](t graphql.Output, value func(T) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return value(p.Source.(T)), nil
+		},
+	}
+}
+
+var statusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "Status",
+	Values: graphql.EnumValueConfigMap{
+		"PENDING":   &graphql.EnumValueConfig{Value: string(repositories.TodoStatusPending)},
+		"COMPLETED": &graphql.EnumValueConfig{Value: string(repositories.TodoStatusCompleted)},
+	},
+})
+
+var reminderType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Reminder",
+	Fields: graphql.Fields{
+		"id":       resolve(graphql.NewNonNull(graphql.ID), func(r repositories.Reminder) interface{} { return r.ID.String() }),
+		"channel":  resolve(graphql.NewNonNull(graphql.String), func(r repositories.Reminder) interface{} { return string(r.Channel) }),
+		"target":   resolve(graphql.String, func(r repositories.Reminder) interface{} { return optional(r.Target.String) }),
+		"remindAt": resolve(graphql.String, func(r repositories.Reminder) interface{} { return timestamp(r.RemindAt) }),
+		"offsetMinutes": resolve(graphql.Int, func(r repositories.Reminder) interface{} {
+			if !r.OffsetMinutes.Valid {
+				return nil
+			}
+			return int(r.OffsetMinutes.Int32)
+		}),
+		"attempts": resolve(graphql.NewNonNull(graphql.Int), func(r repositories.Reminder) interface{} { return int(r.Attempts) }),
+		"firedAt":  resolve(graphql.String, func(r repositories.Reminder) interface{} { return timestamp(r.FiredAt) }),
+	},
+})
+
+var auditEntryType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "AuditEntry",
+	Description: "A change of a task, see GET /tasks/:id/history.",
+	Fields: graphql.Fields{
+		"id": resolve(graphql.NewNonNull(graphql.ID), func(a repositories.TaskAudit) interface{} { return a.ID }),
+		"actor": resolve(graphql.ID, func(a repositories.TaskAudit) interface{} {
+			if !a.Actor.Valid {
+				return nil
+			}
+			return a.Actor.String()
+		}),
+		"action":  resolve(graphql.NewNonNull(graphql.String), func(a repositories.TaskAudit) interface{} { return a.Action }),
+		"traceId": resolve(graphql.String, func(a repositories.TaskAudit) interface{} { return optional(a.TraceID.String) }),
+		"changes": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: `The changed fields as JSON, {"field": {"before": ..., "after": ...}}.`,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return string(p.Source.(repositories.TaskAudit).Changes), nil
+			},
+		},
+		"createdAt": resolve(graphql.String, func(a repositories.TaskAudit) interface{} { return timestamp(a.CreatedAt) }),
+	},
+})
+
+var taskType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Task",
+	Fields: graphql.Fields{
+		"id":             resolve(graphql.NewNonNull(graphql.ID), func(t todo.Todo) interface{} { return t.ID }),
+		"title":          resolve(graphql.NewNonNull(graphql.String), func(t todo.Todo) interface{} { return t.Title }),
+		"description":    resolve(graphql.NewNonNull(graphql.String), func(t todo.Todo) interface{} { return t.Description }),
+		"status":         resolve(graphql.NewNonNull(statusEnum), func(t todo.Todo) interface{} { return t.Status }),
+		"dueDate":        resolve(graphql.NewNonNull(graphql.String), func(t todo.Todo) interface{} { return t.DueDate }),
+		"dueAt":          resolve(graphql.String, func(t todo.Todo) interface{} { return optional(t.DueAt) }),
+		"recurrenceRule": resolve(graphql.String, func(t todo.Todo) interface{} { return optional(t.RecurrenceRule) }),
+		"board":          resolve(graphql.NewNonNull(graphql.String), func(t todo.Todo) interface{} { return t.Board }),
+		"overdue":        resolve(graphql.NewNonNull(graphql.Boolean), func(t todo.Todo) interface{} { return t.Overdue }),
+		"updatedAt":      resolve(graphql.String, func(t todo.Todo) interface{} { return optional(t.UpdatedAt) }),
+		"tags": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return loadersFrom(p.Context).Tags.Load(p.Context, p.Source.(todo.Todo).ID), nil
+			},
+		},
+		"reminders": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(reminderType))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return loadersFrom(p.Context).Reminders.Load(p.Context, p.Source.(todo.Todo).ID), nil
+			},
+		},
+		"history": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(auditEntryType))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return loadersFrom(p.Context).History.Load(p.Context, p.Source.(todo.Todo).ID), nil
+			},
+		},
+	},
+})
+
+var taskEdgeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TaskEdge",
+	Fields: graphql.Fields{
+		"cursor": resolve(graphql.NewNonNull(graphql.String), func(e todo.TodoEdge) interface{} { return e.Cursor }),
+		"node":   resolve(graphql.NewNonNull(taskType), func(e todo.TodoEdge) interface{} { return e.Todo }),
+	},
+})
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": resolve(graphql.NewNonNull(graphql.Boolean), func(c connection) interface{} { return c.hasNext }),
+		"endCursor": resolve(graphql.String, func(c connection) interface{} {
+			if len(c.edges) == 0 {
+				return nil
+			}
+			return c.edges[len(c.edges)-1].Cursor
+		}),
+	},
+})
+
+var taskConnectionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TaskConnection",
+	Fields: graphql.Fields{
+		"edges":      resolve(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskEdgeType))), func(c connection) interface{} { return c.edges }),
+		"pageInfo":   resolve(graphql.NewNonNull(pageInfoType), func(c connection) interface{} { return c }),
+		"totalCount": resolve(graphql.NewNonNull(graphql.Int), func(c connection) interface{} { return int(c.total) }),
+	},
+})
+
+var taskPayloadType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TaskPayload",
+	Fields: graphql.Fields{
+		"task":      resolve(graphql.NewNonNull(taskType), func(p payload) interface{} { return p.task }),
+		"undoToken": resolve(graphql.NewNonNull(graphql.String), func(p payload) interface{} { return p.undo }),
+	},
+})
+
+var deletePayloadType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DeleteTaskPayload",
+	Fields: graphql.Fields{
+		"id":        resolve(graphql.NewNonNull(graphql.ID), func(p deletePayload) interface{} { return p.id }),
+		"undoToken": resolve(graphql.NewNonNull(graphql.String), func(p deletePayload) interface{} { return p.undo }),
+	},
+})
+
+var createTaskInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "CreateTaskInput",
+	Description: "The fields of POST /tasks, one of dueDate (YYYY-MM-DD) and dueAt (RFC 3339) is required.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"title":          &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"description":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"dueDate":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"dueAt":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"recurrenceRule": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"board":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var updateTaskInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "UpdateTaskInput",
+	Description: "The fields of PUT /tasks/:id, recurrenceRule is only changed when set.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"title":          &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"description":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"status":         &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(statusEnum)},
+		"dueDate":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"dueAt":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"recurrenceRule": &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+// Resolver resolves the queries and mutations with the task service, so they
+// follow the same rules as the REST API.
+type Resolver struct {
+	todos todo.ITodoService
+}
+
+// NewSchema returns the schema over the tasks. The fields of a task that need
+// queries of their own are read through the loaders of the request context.
+func NewSchema(todos todo.ITodoService) (graphql.Schema, error) {
+
+	r := &Resolver{todos: todos}
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"task": &graphql.Field{
+					Type:    taskType,
+					Args:    idArgs,
+					Resolve: r.task,
+				},
+				"tasks": &graphql.Field{
+					Type:        graphql.NewNonNull(taskConnectionType),
+					Description: "The tasks matching the filters of GET /tasks, newest first.",
+					Args: graphql.FieldConfigArgument{
+						"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: DefaultFirst},
+						"after":  &graphql.ArgumentConfig{Type: graphql.String},
+						"status": &graphql.ArgumentConfig{Type: statusEnum},
+						"search": &graphql.ArgumentConfig{Type: graphql.String},
+						"board":  &graphql.ArgumentConfig{Type: graphql.String},
+					},
+					Resolve: r.tasks,
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"createTask": &graphql.Field{
+					Type: graphql.NewNonNull(taskPayloadType),
+					Args: graphql.FieldConfigArgument{
+						"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createTaskInput)},
+					},
+					Resolve: r.createTask,
+				},
+				"updateTask": &graphql.Field{
+					Type: graphql.NewNonNull(taskPayloadType),
+					Args: graphql.FieldConfigArgument{
+						"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+						"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateTaskInput)},
+					},
+					Resolve: r.updateTask,
+				},
+				"completeTask": &graphql.Field{
+					Type:    graphql.NewNonNull(taskPayloadType),
+					Args:    idArgs,
+					Resolve: r.completeTask,
+				},
+				"moveTask": &graphql.Field{
+					Type: graphql.NewNonNull(taskPayloadType),
+					Args: graphql.FieldConfigArgument{
+						"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+						"board": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Resolve: r.moveTask,
+				},
+				"deleteTask": &graphql.Field{
+					Type:    graphql.NewNonNull(deletePayloadType),
+					Args:    idArgs,
+					Resolve: r.deleteTask,
+				},
+				"restoreTask": &graphql.Field{
+					Type:    graphql.NewNonNull(taskPayloadType),
+					Args:    idArgs,
+					Resolve: r.restoreTask,
+				},
+			},
+		}),
+	})
+}
+
+// id reads and validates the id argument.
+func id(p graphql.ResolveParams) (string, error) {
+
+	value, _ := p.Args["id"].(string)
+
+	if err := utils.ValidateId(value); err != nil {
+		return "", &Error{Code: "BAD_USER_INPUT", Err: err}
+	}
+
+	return value, nil
+}
+
+// str reads an optional string argument or input field.
+func str(args map[string]interface{}, key string) string {
+	value, _ := args[key].(string)
+	return value
+}
+
+func strPtr(args map[string]interface{}, key string) *string {
+
+	value, ok := args[key].(string)
+	if !ok {
+		return nil
+	}
+
+	return &value
+}
+
+func (r *Resolver) task(p graphql.ResolveParams) (interface{}, error) {
+
+	taskId, err := id(p)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.todos.GetTodo(p.Context, taskId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return data, nil
+}
+
+func (r *Resolver) tasks(p graphql.ResolveParams) (interface{}, error) {
+
+	first, _ := p.Args["first"].(int)
+
+	edges, hasNext, total, err := r.todos.ListTodosAfter(p.Context, todo.ListTodoAfterRequest{
+		First:  clampFirst(first),
+		After:  str(p.Args, "after"),
+		Status: strPtr(p.Args, "status"),
+		Search: strPtr(p.Args, "search"),
+		Board:  strPtr(p.Args, "board"),
+	})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return connection{edges: edges, hasNext: hasNext, total: total}, nil
+}
+
+// reload reads a task back after a mutation, so every mutation answers with
+// the same shape as the task query.
+func (r *Resolver) reload(p graphql.ResolveParams, taskId, undo string) (interface{}, error) {
+
+	data, err := r.todos.GetTodo(p.Context, taskId)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return payload{task: data, undo: undo}, nil
+}
+
+func (r *Resolver) createTask(p graphql.ResolveParams) (interface{}, error) {
+
+	input, _ := p.Args["input"].(map[string]interface{})
+
+	req := todo.CreateTodoRequest{
+		Title:          str(input, "title"),
+		Description:    str(input, "description"),
+		DueDate:        str(input, "dueDate"),
+		DueAt:          str(input, "dueAt"),
+		RecurrenceRule: str(input, "recurrenceRule"),
+		Board:          str(input, "board"),
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return nil, toError(err)
+	}
+
+	data, undo, err := r.todos.CreateTodo(p.Context, req)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return r.reload(p, data.ID.String(), undo)
+}
+
+func (r *Resolver) updateTask(p graphql.ResolveParams) (interface{}, error) {
+
+	taskId, err := id(p)
+	if err != nil {
+		return nil, err
+	}
+
+	input, _ := p.Args["input"].(map[string]interface{})
+
+	req := todo.UpdateTodoRequest{
+		Title:          str(input, "title"),
+		Description:    str(input, "description"),
+		Status:         str(input, "status"),
+		DueDate:        str(input, "dueDate"),
+		DueAt:          str(input, "dueAt"),
+		RecurrenceRule: strPtr(input, "recurrenceRule"),
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return nil, toError(err)
+	}
+
+	_, undo, err := r.todos.UpdateTodo(p.Context, req, taskId)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return r.reload(p, taskId, undo)
+}
+
+func (r *Resolver) completeTask(p graphql.ResolveParams) (interface{}, error) {
+
+	taskId, err := id(p)
+	if err != nil {
+		return nil, err
+	}
+
+	_, undo, err := r.todos.CompleteTodo(p.Context, taskId)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return r.reload(p, taskId, undo)
+}
+
+func (r *Resolver) moveTask(p graphql.ResolveParams) (interface{}, error) {
+
+	taskId, err := id(p)
+	if err != nil {
+		return nil, err
+	}
+
+	req := todo.MoveTodoRequest{Board: str(p.Args, "board")}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return nil, toError(err)
+	}
+
+	_, undo, err := r.todos.MoveTodo(p.Context, taskId, req.Board)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return r.reload(p, taskId, undo)
+}
+
+func (r *Resolver) deleteTask(p graphql.ResolveParams) (interface{}, error) {
+
+	taskId, err := id(p)
+	if err != nil {
+		return nil, err
+	}
+
+	undo, err := r.todos.DeleteTodo(p.Context, taskId)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return deletePayload{id: taskId, undo: undo}, nil
+}
+
+func (r *Resolver) restoreTask(p graphql.ResolveParams) (interface{}, error) {
+
+	taskId, err := id(p)
+	if err != nil {
+		return nil, err
+	}
+
+	_, undo, err := r.todos.RestoreTodo(p.Context, taskId)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return r.reload(p, taskId, undo)
+}
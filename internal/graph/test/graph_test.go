@@ -0,0 +1,322 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ilcs/internal/app/todo"
+	"ilcs/internal/constants"
+	"ilcs/internal/graph"
+	"ilcs/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepo answers the batch queries of the loaders and records the ids of
+// every call.
+type MockRepo struct {
+	repositories.Querier
+	tags      map[string][]string
+	reminders map[string][]repositories.Reminder
+	calls     map[string][][]string
+}
+
+func (m *MockRepo) record(query string, ids []pgtype.UUID) {
+
+	var keys []string
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+
+	m.calls[query] = append(m.calls[query], keys)
+}
+
+func (m *MockRepo) ListTagsByTodos(ctx context.Context, ids []pgtype.UUID) ([]repositories.TodoTag, error) {
+
+	m.record("ListTagsByTodos", ids)
+
+	var rows []repositories.TodoTag
+	for _, id := range ids {
+		for _, tag := range m.tags[id.String()] {
+			rows = append(rows, repositories.TodoTag{TodoID: id, Tag: tag})
+		}
+	}
+
+	return rows, nil
+}
+
+func (m *MockRepo) ListRemindersByTodos(ctx context.Context, params repositories.ListRemindersByTodosParams) ([]repositories.Reminder, error) {
+
+	m.record("ListRemindersByTodos", params.TodoIds)
+
+	var rows []repositories.Reminder
+	for _, id := range params.TodoIds {
+		for _, reminder := range m.reminders[id.String()] {
+			if reminder.UserID == params.UserID {
+				rows = append(rows, reminder)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// FakeTodoService keeps tasks in memory, in the order they are listed.
+type FakeTodoService struct {
+	todo.ITodoService
+	tasks []todo.Todo
+}
+
+func (f *FakeTodoService) ListTodosAfter(ctx context.Context, req todo.ListTodoAfterRequest) ([]todo.TodoEdge, bool, int64, error) {
+
+	start := 0
+	if req.After != "" {
+		for i, task := range f.tasks {
+			if task.ID == req.After {
+				start = i + 1
+			}
+		}
+	}
+
+	var edges []todo.TodoEdge
+	for _, task := range f.tasks[start:] {
+		if len(edges) == req.First {
+			return edges, true, int64(len(f.tasks)), nil
+		}
+		edges = append(edges, todo.TodoEdge{Cursor: task.ID, Todo: task})
+	}
+
+	return edges, false, int64(len(f.tasks)), nil
+}
+
+func (f *FakeTodoService) GetTodo(ctx context.Context, id string) (todo.Todo, error) {
+
+	for _, task := range f.tasks {
+		if task.ID == id {
+			return task, nil
+		}
+	}
+
+	return todo.Todo{}, pgx.ErrNoRows
+}
+
+func (f *FakeTodoService) CreateTodo(ctx context.Context, req todo.CreateTodoRequest) (repositories.Todo, string, error) {
+
+	id := uuid.New()
+	f.tasks = append(f.tasks, todo.Todo{
+		ID:      id.String(),
+		Title:   req.Title,
+		Status:  string(repositories.TodoStatusPending),
+		DueDate: req.DueDate,
+		Board:   todo.DefaultBoard,
+	})
+
+	return repositories.Todo{ID: pgtype.UUID{Bytes: id, Valid: true}}, "undo-create", nil
+}
+
+func setup(t *testing.T, count int) (*graph.GraphHandler, *FakeTodoService, *MockRepo) {
+
+	todos := &FakeTodoService{}
+	repo := &MockRepo{
+		tags:      map[string][]string{},
+		reminders: map[string][]repositories.Reminder{},
+		calls:     map[string][][]string{},
+	}
+
+	for i := 0; i < count; i++ {
+		id := uuid.New()
+		todos.tasks = append(todos.tasks, todo.Todo{
+			ID:      id.String(),
+			Title:   "Task",
+			Status:  string(repositories.TodoStatusPending),
+			DueDate: "2025-01-01",
+			Board:   todo.DefaultBoard,
+		})
+		repo.tags[id.String()] = []string{"home"}
+	}
+
+	schema, err := graph.NewSchema(todos)
+	require.NoError(t, err)
+
+	return graph.NewGraphHandler(schema, repo), todos, repo
+}
+
+func decode(t *testing.T, result *graphql.Result, target interface{}) {
+
+	data, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, target))
+}
+
+func TestTasks_BatchesTaskFields(t *testing.T) {
+	handler, todos, repo := setup(t, 3)
+
+	userId := uuid.New()
+	ctx := context.WithValue(context.Background(), constants.USER_ID, userId.String())
+
+	first := todos.tasks[0].ID
+	repo.reminders[first] = []repositories.Reminder{{
+		ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TodoID:  pgtype.UUID{Bytes: uuid.MustParse(first), Valid: true},
+		UserID:  pgtype.UUID{Bytes: userId, Valid: true},
+		Channel: repositories.ReminderChannelEmail,
+	}, {
+		// set by someone else
+		ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TodoID:  pgtype.UUID{Bytes: uuid.MustParse(first), Valid: true},
+		UserID:  pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Channel: repositories.ReminderChannelWebhook,
+	}}
+
+	result := handler.Execute(ctx, graph.Request{
+		Query: `query($first: Int) {
+			tasks(first: $first) {
+				totalCount
+				pageInfo { hasNextPage endCursor }
+				edges { cursor node { id status tags reminders { channel } } }
+			}
+		}`,
+		Variables: map[string]interface{}{"first": float64(2)},
+	})
+	require.Empty(t, result.Errors)
+
+	var data struct {
+		Tasks struct {
+			TotalCount int
+			PageInfo   struct {
+				HasNextPage bool
+				EndCursor   string
+			}
+			Edges []struct {
+				Cursor string
+				Node   struct {
+					ID        string
+					Status    string
+					Tags      []string
+					Reminders []struct{ Channel string }
+				}
+			}
+		}
+	}
+	decode(t, result, &data)
+
+	assert.Equal(t, 3, data.Tasks.TotalCount)
+	assert.True(t, data.Tasks.PageInfo.HasNextPage)
+	require.Len(t, data.Tasks.Edges, 2)
+	assert.Equal(t, data.Tasks.Edges[1].Cursor, data.Tasks.PageInfo.EndCursor)
+	assert.Equal(t, "PENDING", data.Tasks.Edges[0].Node.Status)
+	assert.Equal(t, []string{"home"}, data.Tasks.Edges[1].Node.Tags)
+	require.Len(t, data.Tasks.Edges[0].Node.Reminders, 1)
+	assert.Equal(t, "email", data.Tasks.Edges[0].Node.Reminders[0].Channel)
+	assert.Empty(t, data.Tasks.Edges[1].Node.Reminders)
+
+	// one query per field for the whole page
+	require.Len(t, repo.calls["ListTagsByTodos"], 1)
+	assert.ElementsMatch(t, []string{todos.tasks[0].ID, todos.tasks[1].ID}, repo.calls["ListTagsByTodos"][0])
+	assert.Len(t, repo.calls["ListRemindersByTodos"], 1)
+}
+
+func TestCreateTask(t *testing.T) {
+	handler, _, _ := setup(t, 0)
+
+	result := handler.Execute(context.Background(), graph.Request{
+		Query: `mutation { createTask(input: {title: "No due day"}) { undoToken } }`,
+	})
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "BAD_USER_INPUT", result.Errors[0].Extensions["code"])
+
+	result = handler.Execute(context.Background(), graph.Request{
+		Query: `mutation { createTask(input: {title: "Ship it", dueDate: "2025-03-01"}) { undoToken task { title board tags } } }`,
+	})
+	require.Empty(t, result.Errors)
+
+	var data struct {
+		CreateTask struct {
+			UndoToken string
+			Task      struct {
+				Title string
+				Board string
+				Tags  []string
+			}
+		}
+	}
+	decode(t, result, &data)
+
+	assert.Equal(t, "undo-create", data.CreateTask.UndoToken)
+	assert.Equal(t, "Ship it", data.CreateTask.Task.Title)
+	assert.Equal(t, todo.DefaultBoard, data.CreateTask.Task.Board)
+	assert.Equal(t, []string{}, data.CreateTask.Task.Tags)
+}
+
+func TestTask_NotFoundIsNull(t *testing.T) {
+	handler, _, _ := setup(t, 1)
+
+	result := handler.Execute(context.Background(), graph.Request{
+		Query:     `query($id: ID!) { task(id: $id) { id } }`,
+		Variables: map[string]interface{}{"id": uuid.NewString()},
+	})
+	require.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{"task": nil}, result.Data)
+
+	result = handler.Execute(context.Background(), graph.Request{
+		Query: `{ task(id: "nope") { id } }`,
+	})
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "BAD_USER_INPUT", result.Errors[0].Extensions["code"])
+}
+
+func TestLimits_RejectsTooComplexQueries(t *testing.T) {
+	handler, _, repo := setup(t, 3)
+
+	result := handler.Execute(context.Background(), graph.Request{
+		Query: `query($first: Int) {
+			tasks(first: $first) { edges { node { id title tags reminders { id channel } history { id action } } } }
+		}`,
+		Variables: map[string]interface{}{"first": float64(100)},
+	})
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, graph.ErrTooComplex.Error())
+	assert.Nil(t, result.Data)
+	assert.Empty(t, repo.calls)
+
+	result = handler.Execute(context.Background(), graph.Request{
+		Query: `{ tasks(first: 50) { edges { node { id title tags } } } }`,
+	})
+	assert.Empty(t, result.Errors)
+}
+
+func TestQuery_Status(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler, _, _ := setup(t, 1)
+
+	app := gin.New()
+	app.POST("/graphql", handler.Query)
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"query": "{ tasks { totalCount } }"}`, 200},
+		{`{"query": "{ tasks { nope } }"}`, 400},
+		{`{"query": "{ tasks {"}`, 400},
+		{`{}`, 400},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		app.ServeHTTP(rec, req)
+
+		assert.Equal(t, tc.code, rec.Code, tc.body)
+	}
+}
@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"ilcs/internal/utils"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin only lets through the admins of the guard, set with ADMIN_USER_IDS.
+// It must run after Auth.
+func (g *Guard) Admin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		userId := utils.GetUserId(c)
+
+		if userId == "" || !slices.Contains(g.admins, userId) {
+			c.JSON(403, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
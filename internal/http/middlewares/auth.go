@@ -1,14 +1,29 @@
 package middlewares
 
 import (
-	"os"
+	"ilcs/internal/constants"
+	"ilcs/internal/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func Auth() gin.HandlerFunc {
+// Guard holds what requests are authenticated and authorized against.
+type Guard struct {
+	tokens *utils.Tokens
+	admins []string
+}
+
+// NewGuard returns a guard checking tokens signed by tokens, admins are the
+// user ids let through Admin.
+func NewGuard(tokens *utils.Tokens, admins []string) *Guard {
+	return &Guard{
+		tokens: tokens,
+		admins: admins,
+	}
+}
+
+func (g *Guard) Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		authHeader := c.GetHeader("Authorization")
@@ -31,21 +46,67 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
-		_, err := jwt.Parse(headerSplit[1], func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				c.JSON(401, gin.H{"error": "Invalid token"})
-				c.Abort()
-				return nil, nil
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
+		sub, err := g.tokens.Subject(headerSplit[1])
 		if err != nil {
 			c.JSON(401, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
+		if sub != "" {
+			c.Set(constants.USER_ID, sub)
+		}
+
+		c.Next()
+	}
+}
+
+// TicketProtocol prefixes the ticket in the Sec-WebSocket-Protocol header,
+// the only header a browser lets a WebSocket handshake set.
+const TicketProtocol = "ticket."
+
+// TicketAuth is Auth for the routes browsers open without an Authorization
+// header, like EventSource and WebSocket do: it also takes a ticket of POST
+// /me/tickets in the ticket query parameter or as a "ticket.<ticket>"
+// WebSocket subprotocol.
+func (g *Guard) TicketAuth() gin.HandlerFunc {
+
+	auth := g.Auth()
+
+	return func(c *gin.Context) {
+
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			ticket = protocolTicket(c.GetHeader("Sec-WebSocket-Protocol"))
+		}
+
+		if ticket == "" {
+			auth(c)
+			return
+		}
+
+		sub, err := g.tokens.TicketSubject(ticket)
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Invalid ticket"})
+			c.Abort()
+			return
+		}
+
+		c.Set(constants.USER_ID, sub)
+
 		c.Next()
 	}
 }
+
+// protocolTicket returns the ticket among the comma separated subprotocols
+// of a WebSocket handshake.
+func protocolTicket(header string) string {
+
+	for _, protocol := range strings.Split(header, ",") {
+		if ticket, ok := strings.CutPrefix(strings.TrimSpace(protocol), TicketProtocol); ok {
+			return ticket
+		}
+	}
+
+	return ""
+}
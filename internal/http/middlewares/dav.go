@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"ilcs/internal/constants"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DAVAuth authenticates CalDAV clients. Most of them can only do Basic auth,
+// so the password is an API token and the user name is ignored; Bearer tokens
+// work as well. Only tokens bound to a user are accepted. Failures carry a
+// Basic challenge, without it clients never prompt for credentials.
+func (g *Guard) DAVAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		token := ""
+
+		if _, password, ok := c.Request.BasicAuth(); ok {
+			token = password
+		} else if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			token = bearer
+		}
+
+		sub, err := g.tokens.Subject(token)
+		if token == "" || err != nil || sub == "" {
+			c.Header("WWW-Authenticate", `Basic realm="ilcs", charset="UTF-8"`)
+			c.AbortWithStatus(401)
+			return
+		}
+
+		c.Set(constants.USER_ID, sub)
+
+		c.Next()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"ilcs/internal/constants"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -35,7 +36,12 @@ type bodyLogWriter struct {
 }
 
 func (w bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	// event streams stay open for hours and downloads can be large, do not
+	// keep them in memory
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") &&
+		!strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment") {
+		w.body.Write(b)
+	}
 	return w.ResponseWriter.Write(b)
 }
 
@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"ilcs/internal/repositories"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTimeout bounds every database query of a request to timeout, the
+// queries are also canceled when the client goes away. Requests that stay
+// open, like event streams and WebSockets, are not cut short: only their
+// queries are.
+func QueryTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		if timeout > 0 {
+			c.Request = c.Request.WithContext(repositories.WithQueryTimeout(c.Request.Context(), timeout))
+		}
+
+		c.Next()
+	}
+}
@@ -13,9 +13,12 @@ func Trace() gin.HandlerFunc {
 		trace := c.GetHeader(constants.TRACE_ID)
 
 		if trace == "" {
-			c.Request.Header.Add(constants.TRACE_ID, xid.New().String())
+			trace = xid.New().String()
+			c.Request.Header.Add(constants.TRACE_ID, trace)
 		}
 
+		c.Set(constants.TRACE_ID, trace)
+
 		c.Next()
 
 	}
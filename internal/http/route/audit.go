@@ -0,0 +1,15 @@
+package route
+
+import (
+	"ilcs/internal/app/audit"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAuditRoute(app *gin.Engine, handler audit.IAuditHandler, guard *middlewares.Guard) {
+	auditRoute := app.Group("/api/v1")
+	auditRoute.GET("/tasks/:id/history", guard.Auth(), handler.TaskHistory)
+	auditRoute.GET("/admin/audit", guard.Auth(), guard.Admin(), handler.Search)
+
+}
@@ -0,0 +1,14 @@
+package route
+
+import (
+	"ilcs/internal/app/board"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterBoardRoute(app *gin.Engine, handler board.IBoardHandler, guard *middlewares.Guard) {
+	boardRoute := app.Group("/api/v1")
+	boardRoute.GET("/boards/ws", guard.TicketAuth(), handler.Connect)
+
+}
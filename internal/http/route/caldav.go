@@ -0,0 +1,28 @@
+package route
+
+import (
+	"ilcs/internal/app/caldav"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCaldavRoute(app *gin.Engine, handler caldav.ICaldavHandler, guard *middlewares.Guard) {
+	app.GET("/.well-known/caldav", func(c *gin.Context) {
+		c.Redirect(301, caldav.RootPath)
+	})
+
+	for _, path := range []string{caldav.RootPath, caldav.CollectionPath, caldav.CollectionPath + ":name"} {
+		app.OPTIONS(path, handler.Options)
+		app.Handle("PROPFIND", path, guard.DAVAuth(), handler.Propfind)
+	}
+
+	app.Handle("REPORT", caldav.CollectionPath, guard.DAVAuth(), handler.Report)
+
+	objectRoute := app.Group(caldav.CollectionPath, guard.DAVAuth())
+	objectRoute.GET("/:name", handler.GetObject)
+	objectRoute.HEAD("/:name", handler.GetObject)
+	objectRoute.PUT("/:name", handler.PutObject)
+	objectRoute.DELETE("/:name", handler.DeleteObject)
+
+}
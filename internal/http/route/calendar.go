@@ -0,0 +1,17 @@
+package route
+
+import (
+	"ilcs/internal/app/calendar"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCalendarRoute(app *gin.Engine, handler calendar.ICalendarHandler, guard *middlewares.Guard) {
+	calendarRoute := app.Group("/api/v1")
+	calendarRoute.POST("/calendar/feeds", guard.Auth(), handler.CreateFeed)
+	calendarRoute.GET("/calendar/feeds", guard.Auth(), handler.ListFeeds)
+	calendarRoute.DELETE("/calendar/feeds/:id", guard.Auth(), handler.RevokeFeed)
+	calendarRoute.GET("/calendar/:token", handler.GetFeed)
+
+}
@@ -0,0 +1,14 @@
+package route
+
+import (
+	"ilcs/internal/app/digest"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterDigestRoute(app *gin.Engine, handler digest.IDigestHandler, guard *middlewares.Guard) {
+	digestRoute := app.Group("/api/v1")
+	digestRoute.GET("/digest", guard.Auth(), handler.GetDigest)
+
+}
@@ -0,0 +1,13 @@
+package route
+
+import (
+	"ilcs/internal/graph"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterGraphRoute(app *gin.Engine, handler graph.IGraphHandler, guard *middlewares.Guard) {
+	app.POST("/graphql", guard.Auth(), handler.Query)
+
+}
@@ -0,0 +1,17 @@
+package route
+
+import (
+	"ilcs/internal/app/reminder"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReminderRoute(app *gin.Engine, handler reminder.IReminderHandler, guard *middlewares.Guard) {
+	reminderRoute := app.Group("/api/v1")
+	reminderRoute.POST("/tasks/:id/reminders", guard.Auth(), handler.CreateReminder)
+	reminderRoute.GET("/tasks/:id/reminders", guard.Auth(), handler.ListReminders)
+	reminderRoute.DELETE("/reminders/:id", guard.Auth(), handler.DeleteReminder)
+	reminderRoute.GET("/notifications", guard.Auth(), handler.ListNotifications)
+
+}
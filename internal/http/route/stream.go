@@ -0,0 +1,14 @@
+package route
+
+import (
+	"ilcs/internal/app/stream"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterStreamRoute(app *gin.Engine, handler stream.IStreamHandler, guard *middlewares.Guard) {
+	streamRoute := app.Group("/api/v1")
+	streamRoute.GET("/tasks/stream", guard.TicketAuth(), handler.StreamTasks)
+
+}
@@ -7,13 +7,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterTodoRoute(app *gin.Engine, handler todo.ITodoHandler) {
+func RegisterTodoRoute(app *gin.Engine, handler todo.ITodoHandler, guard *middlewares.Guard) {
 	todoRoute := app.Group("/api/v1")
-	todoRoute.POST("/tasks", middlewares.Auth(), handler.CreateTodo)
-	todoRoute.GET("/tasks", middlewares.Auth(), handler.ListTodo)
-	todoRoute.GET("/tasks/:id", middlewares.Auth(), handler.GetTodoById)
-	todoRoute.PUT("/tasks/:id", middlewares.Auth(), handler.UpdateTodo)
-	todoRoute.DELETE("/tasks/:id", middlewares.Auth(), handler.DeleteTodo)
+	todoRoute.POST("/tasks", guard.Auth(), handler.CreateTodo)
+	todoRoute.POST("/tasks/bulk", guard.Auth(), handler.Bulk)
+	todoRoute.POST("/tasks/import", guard.Auth(), handler.ImportTodos)
+	todoRoute.GET("/tasks", guard.Auth(), handler.ListTodo)
+	todoRoute.GET("/tasks/export", guard.Auth(), handler.ExportTodos)
+	todoRoute.GET("/tasks/:id", guard.Auth(), handler.GetTodoById)
+	todoRoute.PUT("/tasks/:id", guard.Auth(), handler.UpdateTodo)
+	todoRoute.DELETE("/tasks/:id", guard.Auth(), handler.DeleteTodo)
+	todoRoute.GET("/tasks/:id/occurrences", guard.Auth(), handler.PreviewOccurrences)
+	todoRoute.POST("/tasks/:id/skip", guard.Auth(), handler.SkipOccurrence)
+	todoRoute.POST("/tasks/:id/end-series", guard.Auth(), handler.EndSeries)
+	todoRoute.POST("/tasks/:id/move", guard.Auth(), handler.MoveTodo)
+	todoRoute.POST("/tasks/:id/complete", guard.Auth(), handler.CompleteTodo)
+	todoRoute.POST("/tasks/:id/restore", guard.Auth(), handler.RestoreTodo)
+	todoRoute.GET("/trash", guard.Auth(), handler.ListTrash)
+	todoRoute.POST("/undo/:token", guard.Auth(), handler.Undo)
 	todoRoute.GET("/token", handler.GetToken)
 
 }
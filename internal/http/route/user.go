@@ -0,0 +1,19 @@
+package route
+
+import (
+	"ilcs/internal/app/user"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterUserRoute(app *gin.Engine, handler user.IUserHandler, guard *middlewares.Guard) {
+	userRoute := app.Group("/api/v1")
+	userRoute.GET("/me/settings", guard.Auth(), handler.GetSettings)
+	userRoute.PUT("/me/settings", guard.Auth(), handler.UpdateSettings)
+	userRoute.GET("/me/preferences", guard.Auth(), handler.GetPreferences)
+	userRoute.PUT("/me/preferences", guard.Auth(), handler.UpdatePreferences)
+	userRoute.POST("/me/tickets", guard.Auth(), handler.CreateTicket)
+	userRoute.GET("/unsubscribe/:token", handler.Unsubscribe)
+
+}
@@ -0,0 +1,18 @@
+package route
+
+import (
+	"ilcs/internal/app/webhook"
+	"ilcs/internal/http/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWebhookRoute(app *gin.Engine, handler webhook.IWebhookHandler, guard *middlewares.Guard) {
+	webhookRoute := app.Group("/api/v1")
+	webhookRoute.POST("/webhooks", guard.Auth(), handler.CreateEndpoint)
+	webhookRoute.GET("/webhooks", guard.Auth(), handler.ListEndpoints)
+	webhookRoute.DELETE("/webhooks/:id", guard.Auth(), handler.DeleteEndpoint)
+	webhookRoute.GET("/webhooks/:id/deliveries", guard.Auth(), handler.ListDeliveries)
+	webhookRoute.POST("/webhooks/:id/test", guard.Auth(), handler.SendTestEvent)
+
+}
@@ -0,0 +1,232 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSyntax   = errors.New("ical: invalid content line")
+	ErrNesting  = errors.New("ical: unbalanced BEGIN and END")
+	ErrNotFound = errors.New("ical: property not found")
+)
+
+// Decode reads an iCalendar object and returns its outermost component,
+// usually a VCALENDAR. Property and component names are upper cased.
+func Decode(r io.Reader) (c Component, err error) {
+
+	lines, err := unfold(r)
+	if err != nil {
+		return
+	}
+
+	var stack []Component
+
+	for _, line := range lines {
+		prop, errP := parseLine(line)
+		if errP != nil {
+			err = errP
+			return
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			stack = append(stack, Component{Name: strings.ToUpper(prop.Value)})
+
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				err = ErrNesting
+				return
+			}
+
+			done := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if len(stack) == 0 {
+				return done, nil
+			}
+
+			stack[len(stack)-1].Components = append(stack[len(stack)-1].Components, done)
+
+		default:
+			if len(stack) == 0 {
+				err = ErrNesting
+				return
+			}
+
+			stack[len(stack)-1].Props = append(stack[len(stack)-1].Props, prop)
+		}
+	}
+
+	err = ErrNesting
+
+	return
+}
+
+// unfold joins continuation lines, which start with a space or a tab, to the
+// line they continue. Empty lines are dropped.
+func unfold(r io.Reader) (lines []string, err error) {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+func parseLine(line string) (prop Property, err error) {
+
+	// the name ends at the first ; or :, params at the first : not quoted
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		err = fmt.Errorf("%w: %q", ErrSyntax, line)
+		return
+	}
+
+	prop.Name = strings.ToUpper(line[:end])
+	rest := line[end:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			err = fmt.Errorf("%w: %q", ErrSyntax, line)
+			return
+		}
+
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value strings.Builder
+		quoted := false
+
+		for len(rest) > 0 {
+			ch := rest[0]
+			if ch == '"' {
+				quoted = !quoted
+			} else if !quoted && (ch == ';' || ch == ':') {
+				break
+			} else {
+				value.WriteByte(ch)
+			}
+
+			rest = rest[1:]
+		}
+
+		if prop.Params == nil {
+			prop.Params = map[string]string{}
+		}
+
+		prop.Params[name] = value.String()
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		err = fmt.Errorf("%w: %q", ErrSyntax, line)
+		return
+	}
+
+	prop.Value = rest[1:]
+
+	return
+}
+
+// Prop returns the first property of the component with the given name.
+func (c Component) Prop(name string) (Property, bool) {
+
+	for _, prop := range c.Props {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+
+	return Property{}, false
+}
+
+// Text returns the unescaped value of the first property with the given name,
+// or an empty string.
+func (c Component) Text(name string) string {
+
+	prop, ok := c.Prop(name)
+	if !ok {
+		return ""
+	}
+
+	return UnescapeText(prop.Value)
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(text string) string {
+
+	var out strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			out.WriteByte(text[i])
+			continue
+		}
+
+		i++
+		switch text[i] {
+		case 'n', 'N':
+			out.WriteByte('\n')
+		default:
+			out.WriteByte(text[i])
+		}
+	}
+
+	return out.String()
+}
+
+// Time parses a DATE or DATE-TIME property. DATE values are returned as
+// midnight UTC with date set. Floating times and times in an unknown TZID are
+// read in fallback.
+func (c Component) Time(name string, fallback *time.Location) (t time.Time, date bool, err error) {
+
+	prop, ok := c.Prop(name)
+	if !ok {
+		err = ErrNotFound
+		return
+	}
+
+	value := strings.TrimSpace(prop.Value)
+
+	if prop.Params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err = time.Parse(dateLayout, value)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(dateTimeLayout, value)
+		return
+	}
+
+	loc := fallback
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if zone, errL := time.LoadLocation(strings.TrimPrefix(tzid, "/")); errL == nil {
+			loc = zone
+		}
+	}
+
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+
+	return
+}
@@ -0,0 +1,166 @@
+// Package ical reads and writes iCalendar (RFC 5545) objects. It only knows
+// the content line syntax, what the components and properties mean is up to
+// the caller.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	// lineLength is the longest a content line may be before it is folded, in
+	// octets and without the line break.
+	lineLength = 75
+)
+
+// Property is a content line of a component. Its value is written as is, text
+// values have to go through EscapeText first.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+type Component struct {
+	Name       string
+	Props      []Property
+	Components []Component
+}
+
+// Add appends a property, params are given as name and value pairs.
+func (c *Component) Add(name, value string, params ...string) {
+
+	prop := Property{Name: name, Value: value}
+
+	if len(params) > 0 {
+		prop.Params = map[string]string{}
+		for i := 0; i+1 < len(params); i += 2 {
+			prop.Params[params[i]] = params[i+1]
+		}
+	}
+
+	c.Props = append(c.Props, prop)
+}
+
+// AddText appends a property with a text value.
+func (c *Component) AddText(name, text string) {
+	c.Add(name, EscapeText(text))
+}
+
+// EscapeText escapes a TEXT value, see RFC 5545 section 3.3.11.
+func EscapeText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(text)
+}
+
+// FormatDate formats the calendar day of t as a DATE value.
+func FormatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// FormatDateTime formats t as a DATE-TIME value in UTC.
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
+
+// Encode writes a component and its subcomponents with CRLF line breaks,
+// folding lines longer than 75 octets.
+func Encode(w io.Writer, c Component) error {
+
+	buf := bufio.NewWriter(w)
+
+	if err := encode(buf, c); err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
+
+func encode(w *bufio.Writer, c Component) error {
+
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+
+	for _, prop := range c.Props {
+		if err := writeLine(w, contentLine(prop)); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range c.Components {
+		if err := encode(w, child); err != nil {
+			return err
+		}
+	}
+
+	return writeLine(w, "END:"+c.Name)
+}
+
+func contentLine(prop Property) string {
+
+	var line strings.Builder
+	line.WriteString(prop.Name)
+
+	names := make([]string, 0, len(prop.Params))
+	for name := range prop.Params {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		value := prop.Params[name]
+
+		// values with separators have to be quoted, quotes are not allowed
+		value = strings.ReplaceAll(value, `"`, "'")
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + value + `"`
+		}
+
+		line.WriteString(";" + name + "=" + value)
+	}
+
+	line.WriteString(":" + prop.Value)
+
+	return line.String()
+}
+
+// writeLine folds line into lines of at most 75 octets, continuation lines
+// start with a space. Multi-octet characters are never split.
+func writeLine(w *bufio.Writer, line string) error {
+
+	limit := lineLength
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+
+		line = line[cut:]
+		// the leading space counts towards the length of continuation lines
+		limit = lineLength - 1
+	}
+
+	_, err := w.WriteString(line + "\r\n")
+
+	return err
+}
@@ -0,0 +1,87 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"ilcs/internal/ical"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne`, ical.EscapeText("a\\b;c,d\r\ne"))
+}
+
+func TestEncode_FoldsLongLines(t *testing.T) {
+	c := ical.Component{Name: "VTODO"}
+	c.AddText("SUMMARY", strings.Repeat("é", 100))
+	c.Add("DUE", "20250101", "VALUE", "DATE")
+	c.Add("X-NOTE", "x", "LABEL", "a;b")
+
+	var out bytes.Buffer
+	require.NoError(t, ical.Encode(&out, c))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\r\n"), "\r\n")
+
+	var summary strings.Builder
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 75, line)
+		assert.True(t, utf8.ValidString(line), "folding must not split characters")
+
+		if strings.HasPrefix(line, "SUMMARY:") {
+			summary.WriteString(line)
+		} else if strings.HasPrefix(line, " ") {
+			summary.WriteString(line[1:])
+		}
+	}
+
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 100), summary.String())
+	assert.Equal(t, "BEGIN:VTODO", lines[0])
+	assert.Contains(t, lines, "DUE;VALUE=DATE:20250101")
+	assert.Contains(t, lines, `X-NOTE;LABEL="a;b":x`)
+	assert.Equal(t, "END:VTODO", lines[len(lines)-1])
+}
+
+func TestDecode_RoundTripsEncode(t *testing.T) {
+	todo := ical.Component{Name: "VTODO"}
+	todo.AddText("SUMMARY", strings.Repeat("Buy milk; eggs, bread\n", 5))
+	todo.Add("DUE", "20250301T093000", "TZID", "Europe/Berlin")
+	todo.Add("DTSTART", "20250228", "VALUE", "DATE")
+
+	calendar := ical.Component{Name: "VCALENDAR"}
+	calendar.Add("VERSION", "2.0")
+	calendar.Components = append(calendar.Components, todo)
+
+	var out bytes.Buffer
+	require.NoError(t, ical.Encode(&out, calendar))
+
+	decoded, err := ical.Decode(&out)
+	require.NoError(t, err)
+	require.Len(t, decoded.Components, 1)
+
+	task := decoded.Components[0]
+	assert.Equal(t, strings.Repeat("Buy milk; eggs, bread\n", 5), task.Text("SUMMARY"))
+
+	due, date, err := task.Time("DUE", time.UTC)
+	require.NoError(t, err)
+	assert.False(t, date)
+	assert.Equal(t, "2025-03-01T08:30:00Z", due.UTC().Format(time.RFC3339))
+
+	start, date, err := task.Time("DTSTART", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, date)
+	assert.Equal(t, "2025-02-28", start.Format("2006-01-02"))
+
+	_, _, err = task.Time("COMPLETED", time.UTC)
+	assert.ErrorIs(t, err, ical.ErrNotFound)
+}
+
+func TestDecode_RejectsUnbalancedComponents(t *testing.T) {
+	_, err := ical.Decode(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VCALENDAR\r\n"))
+	assert.ErrorIs(t, err, ical.ErrNesting)
+}
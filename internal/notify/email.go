@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"ilcs/internal/repositories"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type EmailEnqueuer interface {
+	Enqueue(ctx context.Context, email Email) error
+}
+
+// EmailChannel renders the message and queues it for delivery, honoring the
+// user's email preferences. When the message has no explicit target the
+// user's email address is used.
+type EmailChannel struct {
+	repo   repositories.Querier
+	queue  EmailEnqueuer
+	appURL string
+}
+
+func NewEmailChannel(repo repositories.Querier, queue EmailEnqueuer, appURL string) *EmailChannel {
+	return &EmailChannel{
+		repo:   repo,
+		queue:  queue,
+		appURL: appURL,
+	}
+}
+
+func (e *EmailChannel) Send(ctx context.Context, msg Message) error {
+
+	to := msg.Target
+	unsubscribeURL := ""
+
+	if msg.UserID != "" {
+		var userId pgtype.UUID
+		if err := userId.Scan(msg.UserID); err != nil {
+			return err
+		}
+
+		user, err := e.repo.GetUserById(ctx, userId)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if err == nil {
+			if !wantsEmail(user, msg.Kind) {
+				return nil
+			}
+
+			if to == "" {
+				to = user.Email.String
+			}
+
+			if user.UnsubscribeToken.Valid {
+				unsubscribeURL = e.appURL + "/api/v1/unsubscribe/" + user.UnsubscribeToken.String() + "?kind=" + msg.Kind
+			}
+		}
+	}
+
+	if to == "" {
+		return errors.New("no email address to deliver to")
+	}
+
+	text, html, err := render(msg, unsubscribeURL)
+	if err != nil {
+		return err
+	}
+
+	return e.queue.Enqueue(ctx, Email{
+		To:      to,
+		Subject: msg.Subject,
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+func wantsEmail(user repositories.User, kind string) bool {
+
+	switch kind {
+	case KindReminder:
+		return user.EmailReminders
+	case KindDigest:
+		return user.EmailDigest
+	}
+
+	return true
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"ilcs/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// InAppChannel stores the message in the notification table, where the
+// client picks it up through GET /api/v1/notifications.
+type InAppChannel struct {
+	repo repositories.Querier
+}
+
+func NewInAppChannel(repo repositories.Querier) *InAppChannel {
+	return &InAppChannel{
+		repo: repo,
+	}
+}
+
+func (i *InAppChannel) Send(ctx context.Context, msg Message) error {
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+
+	var userId, todoId pgtype.UUID
+
+	if msg.UserID != "" {
+		if err := userId.Scan(msg.UserID); err != nil {
+			return err
+		}
+	}
+
+	if msg.TodoID != "" {
+		if err := todoId.Scan(msg.TodoID); err != nil {
+			return err
+		}
+	}
+
+	return i.repo.InsertNotification(ctx, repositories.InsertNotificationParams{
+		ID:      pgtype.UUID{Bytes: id, Valid: true},
+		UserID:  userId,
+		TodoID:  todoId,
+		Message: msg.Body,
+	})
+}
@@ -0,0 +1,30 @@
+package notify
+
+import "context"
+
+const (
+	KindReminder = "reminder"
+	KindDigest   = "digest"
+)
+
+type Item struct {
+	Title   string `json:"title"`
+	Due     string `json:"due"`
+	Overdue bool   `json:"overdue"`
+}
+
+type Message struct {
+	Kind    string `json:"kind"`
+	UserID  string `json:"user_id"`
+	TodoID  string `json:"task_id,omitempty"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Items   []Item `json:"items,omitempty"`
+	// Target is the channel specific destination, e.g. a webhook URL or an email address.
+	Target string `json:"-"`
+}
+
+// Channel delivers a message to a user through one medium.
+type Channel interface {
+	Send(ctx context.Context, msg Message) error
+}
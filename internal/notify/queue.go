@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// The keys share the {notify:email} hash tag, a plain key hashes as its whole
+// name, so a cluster keeps them on one slot for BLMOVE.
+const (
+	emailQueueKey      = "notify:email"
+	emailProcessingKey = "{notify:email}:processing"
+	emailInflightKey   = "{notify:email}:inflight"
+	emailRetryKey      = "{notify:email}:retry"
+
+	maxEmailAttempts = 5
+
+	// emailLease is how long a worker has to send an email it took before it
+	// is put back on the queue for another one.
+	emailLease = 5 * time.Minute
+
+	emailSendTimeout = time.Minute
+	emailRetryDelay  = 30 * time.Second
+
+	// emailPoll bounds the wait for new emails, so due retries are picked up
+	// about on time.
+	emailPoll = time.Second
+)
+
+type QueueClient interface {
+	LPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd
+	BLMove(ctx context.Context, source, destination, srcpos, destpos string, timeout time.Duration) *redis.StringCmd
+	LRem(ctx context.Context, key string, count int64, value interface{}) *redis.IntCmd
+	ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd
+	ZRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	ZRangeByScore(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.StringSliceCmd
+}
+
+// EmailQueue decouples producers from SMTP: producers only push to a Redis
+// list and the worker process delivers in the background.
+type EmailQueue struct {
+	client QueueClient
+}
+
+func NewEmailQueue(client QueueClient) *EmailQueue {
+	return &EmailQueue{
+		client: client,
+	}
+}
+
+func (q *EmailQueue) Enqueue(ctx context.Context, email Email) error {
+
+	data, err := json.Marshal(email)
+	if err != nil {
+		return err
+	}
+
+	return q.client.LPush(ctx, emailQueueKey, data).Err()
+}
+
+// Consume delivers queued emails with mailer until ctx is cancelled. An email
+// is moved to a processing list while it is sent, so it survives a worker
+// that dies, and failed deliveries are retried after a growing delay up to
+// maxEmailAttempts times.
+func (q *EmailQueue) Consume(ctx context.Context, mailer Mailer) {
+
+	for ctx.Err() == nil {
+
+		if err := q.requeueDue(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Send()
+		}
+
+		raw, err := q.client.BLMove(ctx, emailQueueKey, emailProcessingKey, "RIGHT", "LEFT", emailPoll).Result()
+		if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+			continue
+		}
+
+		if err != nil {
+			log.Error().Err(err).Send()
+			time.Sleep(time.Second)
+			continue
+		}
+
+		if err := q.deliver(ctx, mailer, raw); err != nil {
+			log.Error().Err(err).Send()
+		}
+	}
+}
+
+func (q *EmailQueue) deliver(ctx context.Context, mailer Mailer, raw string) error {
+
+	lease := redis.Z{Score: float64(time.Now().Add(emailLease).Unix()), Member: raw}
+	if err := q.client.ZAdd(ctx, emailInflightKey, lease).Err(); err != nil {
+		return err
+	}
+
+	var email Email
+	if err := json.Unmarshal([]byte(raw), &email); err != nil {
+		log.Error().Err(err).Send()
+		return q.done(ctx, raw)
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+
+	if err := mailer.Send(sendCtx, email); err != nil {
+
+		// shutting down, the lease puts the email back for the next worker
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		email.Attempts++
+		log.Error().Err(err).Str("to", email.To).Int("attempts", email.Attempts).Send()
+
+		if email.Attempts < maxEmailAttempts && !errors.Is(err, ErrInvalidHeader) {
+			data, err := json.Marshal(email)
+			if err != nil {
+				return err
+			}
+
+			retry := redis.Z{Score: float64(time.Now().Add(retryDelay(email.Attempts)).Unix()), Member: data}
+			if err := q.client.ZAdd(ctx, emailRetryKey, retry).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return q.done(ctx, raw)
+}
+
+// done takes an email off the processing list once it is sent or given up.
+func (q *EmailQueue) done(ctx context.Context, raw string) error {
+
+	if err := q.client.LRem(ctx, emailProcessingKey, 1, raw).Err(); err != nil {
+		return err
+	}
+
+	return q.client.ZRem(ctx, emailInflightKey, raw).Err()
+}
+
+// requeueDue puts back on the queue the retries whose delay is over and the
+// emails whose worker let the lease run out. Only the worker that removes an
+// email pushes it, so several workers don't queue it twice.
+func (q *EmailQueue) requeueDue(ctx context.Context) error {
+
+	due := &redis.ZRangeBy{Min: "-inf", Max: strconv.FormatInt(time.Now().Unix(), 10)}
+
+	retries, err := q.client.ZRangeByScore(ctx, emailRetryKey, due).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range retries {
+		removed, err := q.client.ZRem(ctx, emailRetryKey, raw).Result()
+		if err != nil {
+			return err
+		}
+
+		if removed > 0 {
+			if err := q.client.LPush(ctx, emailQueueKey, raw).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	expired, err := q.client.ZRangeByScore(ctx, emailInflightKey, due).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range expired {
+		removed, err := q.client.LRem(ctx, emailProcessingKey, 1, raw).Result()
+		if err != nil {
+			return err
+		}
+
+		if removed > 0 {
+			if err := q.client.LPush(ctx, emailQueueKey, raw).Err(); err != nil {
+				return err
+			}
+		}
+
+		if err := q.client.ZRem(ctx, emailInflightKey, raw).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retryDelay doubles the delay with every failed attempt.
+func retryDelay(attempts int) time.Duration {
+	return emailRetryDelay << (attempts - 1)
+}
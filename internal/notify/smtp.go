@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+)
+
+var ErrInvalidHeader = errors.New("email header must not contain line breaks")
+
+type Email struct {
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+	Attempts int    `json:"attempts"`
+}
+
+// Mailer delivers a fully rendered email.
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates a mailer for addr (host:port). Authentication is only
+// used when a username is given, which keeps local stand-ins like MailHog
+// working without credentials.
+func NewSMTPMailer(addr, from, username, password string) *SMTPMailer {
+
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: addr,
+		from: from,
+		auth: auth,
+	}
+}
+
+// Send does what smtp.SendMail does, on a connection that is closed when ctx
+// is done so a stalled server doesn't hold the worker.
+func (m *SMTPMailer) Send(ctx context.Context, email Email) (err error) {
+
+	body, err := buildMIME(m.from, email)
+	if err != nil {
+		return
+	}
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return
+	}
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+
+	host, _, _ := net.SplitHostPort(m.addr)
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return
+		}
+	}
+
+	if m.auth != nil {
+		if err = c.Auth(m.auth); err != nil {
+			return
+		}
+	}
+
+	if err = c.Mail(m.from); err != nil {
+		return
+	}
+
+	if err = c.Rcpt(email.To); err != nil {
+		return
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return
+	}
+
+	if _, err = w.Write(body); err != nil {
+		return
+	}
+
+	if err = w.Close(); err != nil {
+		return
+	}
+
+	return c.Quit()
+}
+
+func buildMIME(from string, email Email) ([]byte, error) {
+
+	// the subject comes from task titles, a line break would let them add
+	// headers of their own
+	for _, value := range []string{from, email.To, email.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var buf bytes.Buffer
+
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", email.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", email.Text},
+		{"text/html; charset=UTF-8", email.HTML},
+	}
+
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+
+		w, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := w.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
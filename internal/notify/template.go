@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+)
+
+type templateData struct {
+	Message
+	UnsubscribeURL string
+}
+
+// render builds the text and HTML bodies of an email from the templates of
+// the message kind.
+func render(msg Message, unsubscribeURL string) (text, html string, err error) {
+
+	data := templateData{Message: msg, UnsubscribeURL: unsubscribeURL}
+
+	var textBuf, htmlBuf bytes.Buffer
+
+	err = textTemplates.ExecuteTemplate(&textBuf, msg.Kind+".txt", data)
+	if err != nil {
+		return
+	}
+
+	err = htmlTemplates.ExecuteTemplate(&htmlBuf, msg.Kind+".html", data)
+	if err != nil {
+		return
+	}
+
+	return textBuf.String(), htmlBuf.String(), nil
+}
@@ -0,0 +1,302 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ilcs/internal/notify"
+	"ilcs/internal/repositories"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// startSMTPServer runs a minimal SMTP stand-in that accepts a single message
+// and sends its DATA section on the returned channel.
+func startSMTPServer(t *testing.T) (addr string, received chan string) {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { ln.Close() })
+
+	received = make(chan string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 end data with <CR><LF>.<CR><LF>")
+
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+
+				received <- data.String()
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), received
+}
+
+type MockRepo struct {
+	mock.Mock
+	repositories.Querier
+}
+
+func (m *MockRepo) GetUserById(ctx context.Context, id pgtype.UUID) (repositories.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repositories.User), args.Error(1)
+}
+
+type MockQueue struct {
+	mock.Mock
+}
+
+func (m *MockQueue) Enqueue(ctx context.Context, email notify.Email) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	addr, received := startSMTPServer(t)
+
+	mailer := notify.NewSMTPMailer(addr, "tasks@example.com", "", "")
+
+	err := mailer.Send(context.Background(), notify.Email{
+		To:      "user@example.com",
+		Subject: "Reminder: invoices",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	})
+
+	assert.NoError(t, err)
+
+	data := <-received
+	assert.Contains(t, data, "Subject: Reminder: invoices")
+	assert.Contains(t, data, "multipart/alternative")
+	assert.Contains(t, data, "plain body")
+	assert.Contains(t, data, "<p>html body</p>")
+}
+
+func TestSMTPMailer_Send_EncodesSubject(t *testing.T) {
+	addr, received := startSMTPServer(t)
+
+	mailer := notify.NewSMTPMailer(addr, "tasks@example.com", "", "")
+
+	err := mailer.Send(context.Background(), notify.Email{
+		To:      "user@example.com",
+		Subject: "Reminder: café",
+		Text:    "plain body",
+	})
+
+	assert.NoError(t, err)
+
+	data := <-received
+	assert.Contains(t, data, "Subject: =?utf-8?q?Reminder:_caf=C3=A9?=")
+}
+
+func TestSMTPMailer_Send_RejectsHeaderInjection(t *testing.T) {
+	mailer := notify.NewSMTPMailer("127.0.0.1:1", "tasks@example.com", "", "")
+
+	err := mailer.Send(context.Background(), notify.Email{
+		To:      "user@example.com",
+		Subject: "Reminder: x\r\nBcc: victim@example.com",
+		Text:    "plain body",
+	})
+
+	assert.ErrorIs(t, err, notify.ErrInvalidHeader)
+}
+
+type flakyMailer struct {
+	mu    sync.Mutex
+	fails int
+	sent  []notify.Email
+}
+
+func (m *flakyMailer) Send(ctx context.Context, email notify.Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.fails > 0 {
+		m.fails--
+		return errors.New("421 try again later")
+	}
+
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+func (m *flakyMailer) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+func TestEmailQueue_RetriesAfterDelay(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	queue := notify.NewEmailQueue(client)
+
+	require.NoError(t, queue.Enqueue(context.Background(), notify.Email{To: "user@example.com", Subject: "hi"}))
+
+	mailer := &flakyMailer{fails: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Consume(ctx, mailer)
+		close(done)
+	}()
+
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// the failed email waits in the retry set instead of going straight back
+	require.Eventually(t, func() bool {
+		members, _ := client.ZRange(context.Background(), "{notify:email}:retry", 0, -1).Result()
+		return len(members) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Zero(t, mailer.count())
+	assert.Zero(t, client.LLen(context.Background(), "{notify:email}:processing").Val())
+
+	members, _ := client.ZRange(context.Background(), "{notify:email}:retry", 0, -1).Result()
+	require.NoError(t, client.ZAdd(context.Background(), "{notify:email}:retry", redis.Z{Score: 0, Member: members[0]}).Err())
+
+	require.Eventually(t, func() bool { return mailer.count() == 1 }, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, mailer.sent[0].Attempts)
+	assert.Zero(t, client.LLen(context.Background(), "{notify:email}:processing").Val())
+	assert.Zero(t, client.ZCard(context.Background(), "{notify:email}:inflight").Val())
+}
+
+func TestEmailQueue_RequeuesExpiredLease(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	queue := notify.NewEmailQueue(client)
+
+	// an email left behind by a worker that died while sending it
+	raw := `{"to":"user@example.com","subject":"hi","text":"","html":"","attempts":0}`
+	require.NoError(t, client.LPush(context.Background(), "{notify:email}:processing", raw).Err())
+	require.NoError(t, client.ZAdd(context.Background(), "{notify:email}:inflight", redis.Z{Score: 0, Member: raw}).Err())
+
+	mailer := &flakyMailer{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Consume(ctx, mailer)
+		close(done)
+	}()
+
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	require.Eventually(t, func() bool { return mailer.count() == 1 }, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "user@example.com", mailer.sent[0].To)
+}
+
+func TestEmailChannel_RendersAndQueues(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockQueue := new(MockQueue)
+	channel := notify.NewEmailChannel(mockRepo, mockQueue, "https://tasks.example.com")
+
+	userId := uuid.New()
+	token := uuid.New()
+
+	mockRepo.On("GetUserById", mock.Anything, pgtype.UUID{Bytes: userId, Valid: true}).Return(repositories.User{
+		Email:            pgtype.Text{String: "user@example.com", Valid: true},
+		EmailReminders:   true,
+		UnsubscribeToken: pgtype.UUID{Bytes: token, Valid: true},
+	}, nil)
+
+	mockQueue.On("Enqueue", mock.Anything, mock.MatchedBy(func(email notify.Email) bool {
+		unsubscribe := "https://tasks.example.com/api/v1/unsubscribe/" + token.String() + "?kind=reminder"
+		return email.To == "user@example.com" &&
+			strings.Contains(email.Text, "Pay invoices is due") &&
+			strings.Contains(email.Text, unsubscribe) &&
+			strings.Contains(email.HTML, unsubscribe)
+	})).Return(nil)
+
+	err := channel.Send(context.Background(), notify.Message{
+		Kind:    notify.KindReminder,
+		UserID:  userId.String(),
+		Subject: "Reminder: Pay invoices",
+		Body:    "Pay invoices is due 2025-01-01",
+	})
+
+	assert.NoError(t, err)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestEmailChannel_RespectsPreferences(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockQueue := new(MockQueue)
+	channel := notify.NewEmailChannel(mockRepo, mockQueue, "")
+
+	userId := uuid.New()
+
+	mockRepo.On("GetUserById", mock.Anything, mock.Anything).Return(repositories.User{
+		Email:          pgtype.Text{String: "user@example.com", Valid: true},
+		EmailReminders: false,
+	}, nil)
+
+	err := channel.Send(context.Background(), notify.Message{
+		Kind:   notify.KindReminder,
+		UserID: userId.String(),
+		Body:   "Pay invoices is due 2025-01-01",
+	})
+
+	assert.NoError(t, err)
+	mockQueue.AssertNotCalled(t, "Enqueue")
+}
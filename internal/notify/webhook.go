@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"ilcs/internal/utils"
+	"net/http"
+	"time"
+)
+
+type WebhookChannel struct {
+	client *http.Client
+}
+
+// NewWebhookChannel posts reminders to the URLs users gave, only on public
+// addresses.
+func NewWebhookChannel() *WebhookChannel {
+	return &WebhookChannel{
+		client: utils.PublicHTTPClient(10 * time.Second),
+	}
+}
+
+func (w *WebhookChannel) Send(ctx context.Context, msg Message) error {
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+)
+
+// Cursor streams the rows of queries whose results can be too large to hold
+// in memory, where the generated :many methods collect every row first. fn is
+// called once per row while the rows are read, an error stops the query and is
+// returned.
+type Cursor interface {
+	EachExportTodo(ctx context.Context, arg ExportTodoParams, fn func(ExportTodoRow) error) error
+}
+
+func (q *Queries) EachExportTodo(ctx context.Context, arg ExportTodoParams, fn func(ExportTodoRow) error) error {
+
+	rows, err := q.db.Query(ctx, exportTodo, arg.Status, arg.Search, arg.Board)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var i ExportTodoRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.DueDate,
+			&i.RecurrenceRule,
+			&i.DueAt,
+			&i.Board,
+			&i.Tags,
+		); err != nil {
+			return err
+		}
+
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -11,6 +11,67 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ReminderChannel string
+
+const (
+	ReminderChannelWebhook ReminderChannel = "webhook"
+	ReminderChannelEmail   ReminderChannel = "email"
+	ReminderChannelInApp   ReminderChannel = "in_app"
+)
+
+func (e *ReminderChannel) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReminderChannel(s)
+	case string:
+		*e = ReminderChannel(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReminderChannel: %T", src)
+	}
+	return nil
+}
+
+type NullReminderChannel struct {
+	ReminderChannel ReminderChannel `json:"reminder_channel"`
+	Valid           bool            `json:"valid"` // Valid is true if ReminderChannel is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReminderChannel) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReminderChannel, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReminderChannel.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReminderChannel) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReminderChannel), nil
+}
+
+func (e ReminderChannel) Valid() bool {
+	switch e {
+	case ReminderChannelWebhook,
+		ReminderChannelEmail,
+		ReminderChannelInApp:
+		return true
+	}
+	return false
+}
+
+func AllReminderChannelValues() []ReminderChannel {
+	return []ReminderChannel{
+		ReminderChannelWebhook,
+		ReminderChannelEmail,
+		ReminderChannelInApp,
+	}
+}
+
 type TodoStatus string
 
 const (
@@ -69,12 +130,187 @@ func AllTodoStatusValues() []TodoStatus {
 	}
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus `json:"webhook_delivery_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
+func (e WebhookDeliveryStatus) Valid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending,
+		WebhookDeliveryStatusSucceeded,
+		WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllWebhookDeliveryStatusValues() []WebhookDeliveryStatus {
+	return []WebhookDeliveryStatus{
+		WebhookDeliveryStatusPending,
+		WebhookDeliveryStatusSucceeded,
+		WebhookDeliveryStatusFailed,
+	}
+}
+
+type CalendarFeed struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	Name      string             `db:"name" json:"name"`
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+	Component string             `db:"component" json:"component"`
+	Status    pgtype.Text        `db:"status" json:"status"`
+	Search    pgtype.Text        `db:"search" json:"search"`
+	Board     pgtype.Text        `db:"board" json:"board"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type CaldavObject struct {
+	TodoID pgtype.UUID `db:"todo_id" json:"todo_id"`
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+	Name   string      `db:"name" json:"name"`
+	Uid    string      `db:"uid" json:"uid"`
+}
+
+type Notification struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	TodoID    pgtype.UUID        `db:"todo_id" json:"todo_id"`
+	Message   string             `db:"message" json:"message"`
+	ReadAt    pgtype.Timestamptz `db:"read_at" json:"read_at"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type Outbox struct {
+	ID           int64              `db:"id" json:"id"`
+	EventID      pgtype.UUID        `db:"event_id" json:"event_id"`
+	Type         string             `db:"type" json:"type"`
+	TaskID       pgtype.UUID        `db:"task_id" json:"task_id"`
+	UserID       pgtype.UUID        `db:"user_id" json:"user_id"`
+	Payload      []byte             `db:"payload" json:"payload"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	DispatchedAt pgtype.Timestamptz `db:"dispatched_at" json:"dispatched_at"`
+}
+
+type Reminder struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	TodoID        pgtype.UUID        `db:"todo_id" json:"todo_id"`
+	UserID        pgtype.UUID        `db:"user_id" json:"user_id"`
+	Channel       ReminderChannel    `db:"channel" json:"channel"`
+	Target        pgtype.Text        `db:"target" json:"target"`
+	RemindAt      pgtype.Timestamptz `db:"remind_at" json:"remind_at"`
+	OffsetMinutes pgtype.Int4        `db:"offset_minutes" json:"offset_minutes"`
+	Attempts      int32              `db:"attempts" json:"attempts"`
+	LastError     pgtype.Text        `db:"last_error" json:"last_error"`
+	FiredAt       pgtype.Timestamptz `db:"fired_at" json:"fired_at"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ClaimedUntil  pgtype.Timestamptz `db:"claimed_until" json:"claimed_until"`
+}
+
+type TaskAudit struct {
+	ID        int64              `db:"id" json:"id"`
+	TaskID    pgtype.UUID        `db:"task_id" json:"task_id"`
+	Actor     pgtype.UUID        `db:"actor" json:"actor"`
+	Action    string             `db:"action" json:"action"`
+	TraceID   pgtype.Text        `db:"trace_id" json:"trace_id"`
+	Changes   []byte             `db:"changes" json:"changes"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type Todo struct {
-	ID          pgtype.UUID        `db:"id" json:"id"`
-	Title       string             `db:"title" json:"title"`
-	Description pgtype.Text        `db:"description" json:"description"`
-	Status      TodoStatus         `db:"status" json:"status"`
-	DueDate     pgtype.Date        `db:"due_date" json:"due_date"`
-	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	Title           string             `db:"title" json:"title"`
+	Description     pgtype.Text        `db:"description" json:"description"`
+	Status          TodoStatus         `db:"status" json:"status"`
+	DueDate         pgtype.Date        `db:"due_date" json:"due_date"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	RecurrenceRule  pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID        pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt           pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	CompletedAt     pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	Board           string             `db:"board" json:"board"`
+	DeletedAt       pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+}
+
+type TodoTag struct {
+	TodoID pgtype.UUID `db:"todo_id" json:"todo_id"`
+	Tag    string      `db:"tag" json:"tag"`
+}
+
+type User struct {
+	ID                 pgtype.UUID        `db:"id" json:"id"`
+	Timezone           string             `db:"timezone" json:"timezone"`
+	CreatedAt          pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	Email              pgtype.Text        `db:"email" json:"email"`
+	EmailReminders     bool               `db:"email_reminders" json:"email_reminders"`
+	EmailDigest        bool               `db:"email_digest" json:"email_digest"`
+	UnsubscribeToken   pgtype.UUID        `db:"unsubscribe_token" json:"unsubscribe_token"`
+	DigestSentOn       pgtype.Date        `db:"digest_sent_on" json:"digest_sent_on"`
+	DigestClaimedUntil pgtype.Timestamptz `db:"digest_claimed_until" json:"digest_claimed_until"`
+}
+
+type WebhookDelivery struct {
+	ID               pgtype.UUID           `db:"id" json:"id"`
+	EndpointID       pgtype.UUID           `db:"endpoint_id" json:"endpoint_id"`
+	Event            string                `db:"event" json:"event"`
+	Payload          []byte                `db:"payload" json:"payload"`
+	Status           WebhookDeliveryStatus `db:"status" json:"status"`
+	Attempts         int32                 `db:"attempts" json:"attempts"`
+	NextAttemptAt    pgtype.Timestamptz    `db:"next_attempt_at" json:"next_attempt_at"`
+	LastResponseCode pgtype.Int4           `db:"last_response_code" json:"last_response_code"`
+	LastError        pgtype.Text           `db:"last_error" json:"last_error"`
+	DeliveredAt      pgtype.Timestamptz    `db:"delivered_at" json:"delivered_at"`
+	CreatedAt        pgtype.Timestamptz    `db:"created_at" json:"created_at"`
+}
+
+type WebhookEndpoint struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	Url       string             `db:"url" json:"url"`
+	Secret    string             `db:"secret" json:"secret"`
+	Events    []string           `db:"events" json:"events"`
+	Active    bool               `db:"active" json:"active"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
@@ -11,12 +11,111 @@ import (
 )
 
 type Querier interface {
+	// Returns the tags the task did not have yet.
+	AddTodoTags(ctx context.Context, arg AddTodoTagsParams) ([]string, error)
+	// Offset reminders are resolved against the current due time of the task, so
+	// rescheduling a task moves its reminders with it. Date-only tasks are due at
+	// midnight in the owner's time zone. The claimed reminders are skipped by the
+	// other workers for the lease, a worker that dies while sending leaves them to
+	// be claimed again once it is over.
+	ClaimDueReminders(ctx context.Context, arg ClaimDueRemindersParams) ([]ClaimDueRemindersRow, error)
+	// Users whose local time has reached the digest hour and who have not
+	// received today's digest yet. The claimed users are skipped by the other
+	// workers for the lease, a worker that dies while sending leaves them to be
+	// claimed again once it is over.
+	ClaimDigestUsers(ctx context.Context, arg ClaimDigestUsersParams) ([]User, error)
+	// Pushes the next attempt of the claimed deliveries past the lease, so the
+	// other workers skip them while they are sent and pick them up again if the
+	// worker sending them dies.
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CompleteTodo(ctx context.Context, id pgtype.UUID) (Todo, error)
+	CopyOutboxEvents(ctx context.Context, arg []CopyOutboxEventsParams) (int64, error)
+	CopyTaskAudit(ctx context.Context, arg []CopyTaskAuditParams) (int64, error)
+	// Bulk insert of imported tasks, they carry their own timestamps so the
+	// snapshots recorded for them match the rows.
+	CopyTodos(ctx context.Context, arg []CopyTodosParams) (int64, error)
 	CountTodo(ctx context.Context, arg CountTodoParams) (int64, error)
-	DeleteTodo(ctx context.Context, id pgtype.UUID) error
+	CountTrash(ctx context.Context, userID pgtype.UUID) (int64, error)
+	DeleteCalendarFeed(ctx context.Context, arg DeleteCalendarFeedParams) (int64, error)
+	DeleteReminder(ctx context.Context, arg DeleteReminderParams) (int64, error)
+	// Moves the task to the trash, PurgeTrashedTodos deletes it for good.
+	DeleteTodo(ctx context.Context, arg DeleteTodoParams) (Todo, error)
+	DeleteWebhookEndpoint(ctx context.Context, arg DeleteWebhookEndpointParams) error
+	EndTodoSeries(ctx context.Context, seriesID pgtype.UUID) ([]EndTodoSeriesRow, error)
+	// Same filters as ListTodo without the paging, grouped by board. Exports read
+	// it through EachExportTodo, which streams the rows instead of collecting them.
+	ExportTodo(ctx context.Context, arg ExportTodoParams) ([]ExportTodoRow, error)
+	GetCaldavObjectByName(ctx context.Context, arg GetCaldavObjectByNameParams) (CaldavObject, error)
+	// CalDAV reads tasks here rather than through the task cache, and only those
+	// of the user.
+	GetCaldavTodo(ctx context.Context, arg GetCaldavTodoParams) (Todo, error)
+	GetCalendarFeedByToken(ctx context.Context, tokenHash string) (CalendarFeed, error)
+	// The last change of a task, undo tokens are only valid while it is theirs.
+	GetLatestTaskAudit(ctx context.Context, taskID pgtype.UUID) (TaskAudit, error)
 	GetTodoById(ctx context.Context, id pgtype.UUID) (GetTodoByIdRow, error)
+	// Locks the task until the end of the transaction, so the state it returns is
+	// the state the following update starts from.
+	GetTodoForUpdate(ctx context.Context, id pgtype.UUID) (Todo, error)
+	GetUserById(ctx context.Context, id pgtype.UUID) (User, error)
+	GetWebhookEndpoint(ctx context.Context, arg GetWebhookEndpointParams) (WebhookEndpoint, error)
+	InsertCaldavObject(ctx context.Context, arg InsertCaldavObjectParams) error
+	InsertCalendarFeed(ctx context.Context, arg InsertCalendarFeedParams) (CalendarFeed, error)
+	InsertNotification(ctx context.Context, arg InsertNotificationParams) error
+	InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error
+	InsertReminder(ctx context.Context, arg InsertReminderParams) (Reminder, error)
+	InsertTaskAudit(ctx context.Context, arg InsertTaskAuditParams) (int64, error)
 	InsertTodo(ctx context.Context, arg InsertTodoParams) (Todo, error)
+	InsertTodoOccurrence(ctx context.Context, arg InsertTodoOccurrenceParams) (int64, error)
+	InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) (WebhookDelivery, error)
+	InsertWebhookEndpoint(ctx context.Context, arg InsertWebhookEndpointParams) (WebhookEndpoint, error)
+	ListCaldavTodos(ctx context.Context, arg ListCaldavTodosParams) ([]ListCaldavTodosRow, error)
+	ListCalendarFeedsByUser(ctx context.Context, userID pgtype.UUID) ([]CalendarFeed, error)
+	// The tasks of a feed: those of its owner due since a given day that match
+	// the saved filters, which use the same rules as ListTodo.
+	ListCalendarTodos(ctx context.Context, arg ListCalendarTodosParams) ([]ListCalendarTodosRow, error)
+	ListDigestTodos(ctx context.Context, arg ListDigestTodosParams) ([]ListDigestTodosRow, error)
+	ListNotificationsByUser(ctx context.Context, arg ListNotificationsByUserParams) ([]Notification, error)
+	ListPendingOutboxEvents(ctx context.Context, limitVal int32) ([]Outbox, error)
+	ListRemindersByTodo(ctx context.Context, arg ListRemindersByTodoParams) ([]Reminder, error)
+	ListRemindersByTodos(ctx context.Context, arg ListRemindersByTodosParams) ([]Reminder, error)
+	ListTagsByTodos(ctx context.Context, todoIds []pgtype.UUID) ([]TodoTag, error)
+	ListTaskHistories(ctx context.Context, taskIds []pgtype.UUID) ([]TaskAudit, error)
+	ListTaskHistory(ctx context.Context, taskID pgtype.UUID) ([]TaskAudit, error)
 	ListTodo(ctx context.Context, arg ListTodoParams) ([]ListTodoRow, error)
+	// Keyset variant of ListTodo for cursor paging: the tasks that come after the
+	// one with the given created_at and id, so tasks created meanwhile don't shift
+	// the next pages.
+	ListTodoAfter(ctx context.Context, arg ListTodoAfterParams) ([]ListTodoAfterRow, error)
+	ListTodoTags(ctx context.Context, todoID pgtype.UUID) ([]string, error)
+	ListTrash(ctx context.Context, arg ListTrashParams) ([]Todo, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookEndpointsByUser(ctx context.Context, userID pgtype.UUID) ([]WebhookEndpoint, error)
+	ListWebhookEndpointsForEvent(ctx context.Context, arg ListWebhookEndpointsForEventParams) ([]WebhookEndpoint, error)
+	// Unlike GetTodoForUpdate it also locks tasks in the trash.
+	LockTodo(ctx context.Context, id pgtype.UUID) (Todo, error)
+	// Only one relay may dispatch at a time so events leave in insertion order.
+	// The lock is released when the transaction ends.
+	LockOutboxRelay(ctx context.Context) (bool, error)
+	MarkDigestSent(ctx context.Context, arg MarkDigestSentParams) error
+	MarkOutboxDispatched(ctx context.Context, ids []int64) error
+	MarkReminderFailed(ctx context.Context, arg MarkReminderFailedParams) error
+	MarkReminderFired(ctx context.Context, id pgtype.UUID) error
+	// previous_board lets subscribers of the old board drop the task.
+	MoveTodo(ctx context.Context, arg MoveTodoParams) (MoveTodoRow, error)
+	PurgeDispatchedOutbox(ctx context.Context, dispatchedAt pgtype.Timestamptz) error
+	PurgeTrashedTodos(ctx context.Context, arg PurgeTrashedTodosParams) ([]Todo, error)
+	RecordWebhookAttempt(ctx context.Context, arg RecordWebhookAttemptParams) (WebhookDelivery, error)
+	RemoveTodoTags(ctx context.Context, arg RemoveTodoTagsParams) ([]string, error)
+	RestoreTodo(ctx context.Context, arg RestoreTodoParams) (RestoreTodoRow, error)
+	SearchTaskAudit(ctx context.Context, arg SearchTaskAuditParams) ([]TaskAudit, error)
+	// Overwrites every editable field, completed_at included, for undo and bulk
+	// updates that compute the whole new state themselves.
+	SetTodoFields(ctx context.Context, arg SetTodoFieldsParams) (Todo, error)
+	UnsubscribeUser(ctx context.Context, arg UnsubscribeUserParams) (pgtype.UUID, error)
 	UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error)
+	UpdateTodoDueDate(ctx context.Context, arg UpdateTodoDueDateParams) (Todo, error)
+	UpsertUserPreferences(ctx context.Context, arg UpsertUserPreferencesParams) (User, error)
+	UpsertUserTimezone(ctx context.Context, arg UpsertUserTimezoneParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ilcs/internal/repositories"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeDB records the context of every query.
+type FakeDB struct {
+	repositories.DBTX
+	ctx context.Context
+}
+
+func (f *FakeDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	f.ctx = ctx
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *FakeDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	f.ctx = ctx
+	return FakeRow{}
+}
+
+type FakeRow struct{}
+
+func (FakeRow) Scan(dest ...any) error { return nil }
+
+func TestWithTimeouts_BoundsQueriesOfTimedContexts(t *testing.T) {
+	db := &FakeDB{}
+	wrapped := repositories.WithTimeouts(db)
+
+	_, err := wrapped.Exec(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	_, ok := db.ctx.Deadline()
+	assert.False(t, ok, "no timeout without WithQueryTimeout")
+
+	ctx := repositories.WithQueryTimeout(context.Background(), time.Minute)
+
+	_, err = wrapped.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+	deadline, ok := db.ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+	assert.ErrorIs(t, db.ctx.Err(), context.Canceled, "the deadline is released with the query")
+
+	row := wrapped.QueryRow(ctx, "SELECT 1")
+	assert.NoError(t, db.ctx.Err(), "a row keeps its deadline until it is read")
+	require.NoError(t, row.Scan())
+	assert.ErrorIs(t, db.ctx.Err(), context.Canceled)
+
+	_, err = wrapped.Exec(repositories.WithoutQueryTimeout(ctx), "SELECT 1")
+	require.NoError(t, err)
+	_, ok = db.ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestWithTimeouts_KeepsEarlierDeadlines(t *testing.T) {
+	db := &FakeDB{}
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := repositories.WithTimeouts(db).Exec(repositories.WithQueryTimeout(parent, time.Hour), "SELECT 1")
+	require.NoError(t, err)
+
+	deadline, _ := db.ctx.Deadline()
+	parentDeadline, _ := parent.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+}
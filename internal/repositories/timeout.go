@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout bounds every query made with ctx, through a DBTX wrapped by
+// WithTimeouts, to timeout. The deadline of a query is derived from ctx, so it
+// still stops earlier when ctx is done.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// WithoutQueryTimeout lifts the timeout of ctx, for the queries that stream
+// their rows for as long as the client reads them.
+func WithoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, time.Duration(0))
+}
+
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+
+	if timeout, _ := ctx.Value(queryTimeoutKey{}).(time.Duration); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// WithTimeouts wraps db so its queries honour WithQueryTimeout.
+func WithTimeouts(db DBTX) DBTX {
+	return timeoutDB{db: db}
+}
+
+type timeoutDB struct {
+	db DBTX
+}
+
+func (t timeoutDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	return t.db.Exec(ctx, sql, args...)
+}
+
+func (t timeoutDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+
+	ctx, cancel := queryContext(ctx)
+
+	rows, err := t.db.Query(ctx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return cancelRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (t timeoutDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+
+	ctx, cancel := queryContext(ctx)
+
+	return cancelRow{row: t.db.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+func (t timeoutDB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	return t.db.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+// cancelRows releases the deadline of the query once its rows are closed.
+type cancelRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r cancelRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// cancelRow releases the deadline of the query once its row is read.
+type cancelRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r cancelRow) Scan(dest ...any) error {
+	defer r.cancel()
+
+	return r.row.Scan(dest...)
+}
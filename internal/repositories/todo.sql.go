@@ -11,6 +11,56 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const completeTodo = `-- name: CompleteTodo :one
+UPDATE todo
+SET
+    status = 'completed',
+    completed_at = CASE WHEN status <> 'completed' THEN NOW() ELSE completed_at END,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
+`
+
+func (q *Queries) CompleteTodo(ctx context.Context, id pgtype.UUID) (Todo, error) {
+	row := q.db.QueryRow(ctx, completeTodo, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+type CopyTodosParams struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	Title           string             `db:"title" json:"title"`
+	Description     pgtype.Text        `db:"description" json:"description"`
+	Status          TodoStatus         `db:"status" json:"status"`
+	DueDate         pgtype.Date        `db:"due_date" json:"due_date"`
+	RecurrenceRule  pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID        pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt           pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Board           string             `db:"board" json:"board"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	CompletedAt     pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+}
+
 const countTodo = `-- name: CountTodo :one
 SELECT COUNT(*) 
 FROM todo
@@ -18,28 +68,209 @@ WHERE
     ($1::text IS NULL OR status = $1::todo_status) AND
     ($2::text IS NULL OR 
         (title ILIKE '%' || $2 || '%' OR 
-         description ILIKE '%' || $2 || '%'))
+         description ILIKE '%' || $2 || '%')) AND
+    ($3::text IS NULL OR board = $3) AND
+    deleted_at IS NULL
 `
 
 type CountTodoParams struct {
 	Status *string `db:"status" json:"status"`
 	Search *string `db:"search" json:"search"`
+	Board  *string `db:"board" json:"board"`
 }
 
 func (q *Queries) CountTodo(ctx context.Context, arg CountTodoParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countTodo, arg.Status, arg.Search)
+	row := q.db.QueryRow(ctx, countTodo, arg.Status, arg.Search, arg.Board)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTrash = `-- name: CountTrash :one
+SELECT COUNT(*) FROM todo WHERE deleted_at IS NOT NULL AND user_id = $1
+`
+
+func (q *Queries) CountTrash(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countTrash, userID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
-const deleteTodo = `-- name: DeleteTodo :exec
-DELETE FROM todo WHERE id = $1
+const deleteTodo = `-- name: DeleteTodo :one
+UPDATE todo
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+RETURNING id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
 `
 
-func (q *Queries) DeleteTodo(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteTodo, id)
-	return err
+type DeleteTodoParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+// Moves the task to the trash, PurgeTrashedTodos deletes it for good.
+func (q *Queries) DeleteTodo(ctx context.Context, arg DeleteTodoParams) (Todo, error) {
+	row := q.db.QueryRow(ctx, deleteTodo, arg.ID, arg.UserID)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const endTodoSeries = `-- name: EndTodoSeries :many
+UPDATE todo t
+SET
+    recurrence_rule = NULL,
+    updated_at = NOW()
+FROM (SELECT id, recurrence_rule FROM todo WHERE (series_id = $1 OR id = $1) AND deleted_at IS NULL FOR UPDATE) old
+WHERE t.id = old.id
+RETURNING t.id, t.title, t.description, t.status, t.due_date, t.created_at, t.updated_at, t.recurrence_rule, t.recurrence_start, t.series_id, t.due_at, t.user_id, t.completed_at, t.board, t.deleted_at, old.recurrence_rule AS previous_recurrence_rule
+`
+
+type EndTodoSeriesRow struct {
+	ID                     pgtype.UUID        `db:"id" json:"id"`
+	Title                  string             `db:"title" json:"title"`
+	Description            pgtype.Text        `db:"description" json:"description"`
+	Status                 TodoStatus         `db:"status" json:"status"`
+	DueDate                pgtype.Date        `db:"due_date" json:"due_date"`
+	CreatedAt              pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt              pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	RecurrenceRule         pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart        pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID               pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt                  pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID                 pgtype.UUID        `db:"user_id" json:"user_id"`
+	CompletedAt            pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	Board                  string             `db:"board" json:"board"`
+	DeletedAt              pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	PreviousRecurrenceRule pgtype.Text        `db:"previous_recurrence_rule" json:"previous_recurrence_rule"`
+}
+
+func (q *Queries) EndTodoSeries(ctx context.Context, seriesID pgtype.UUID) ([]EndTodoSeriesRow, error) {
+	rows, err := q.db.Query(ctx, endTodoSeries, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EndTodoSeriesRow
+	for rows.Next() {
+		var i EndTodoSeriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RecurrenceRule,
+			&i.RecurrenceStart,
+			&i.SeriesID,
+			&i.DueAt,
+			&i.UserID,
+			&i.CompletedAt,
+			&i.Board,
+			&i.DeletedAt,
+			&i.PreviousRecurrenceRule,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportTodo = `-- name: ExportTodo :many
+SELECT
+    t.id,
+    t.title,
+    t.description,
+    t.status,
+    t.due_date,
+    t.recurrence_rule,
+    t.due_at,
+    t.board,
+    ARRAY(SELECT tag FROM todo_tag WHERE todo_id = t.id ORDER BY tag)::text[] AS tags
+FROM todo t
+WHERE
+    ($1::text IS NULL OR t.status = $1::todo_status) AND
+    ($2::text IS NULL OR
+        (t.title ILIKE '%' || $2 || '%' OR
+         t.description ILIKE '%' || $2 || '%')) AND
+    ($3::text IS NULL OR t.board = $3) AND
+    t.deleted_at IS NULL
+ORDER BY t.board, t.created_at DESC
+`
+
+type ExportTodoParams struct {
+	Status *string `db:"status" json:"status"`
+	Search *string `db:"search" json:"search"`
+	Board  *string `db:"board" json:"board"`
+}
+
+type ExportTodoRow struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Title          string             `db:"title" json:"title"`
+	Description    pgtype.Text        `db:"description" json:"description"`
+	Status         TodoStatus         `db:"status" json:"status"`
+	DueDate        pgtype.Date        `db:"due_date" json:"due_date"`
+	RecurrenceRule pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	Board          string             `db:"board" json:"board"`
+	Tags           []string           `db:"tags" json:"tags"`
+}
+
+// Same filters as ListTodo without the paging, grouped by board. Exports read
+// it through EachExportTodo, which streams the rows instead of collecting them.
+func (q *Queries) ExportTodo(ctx context.Context, arg ExportTodoParams) ([]ExportTodoRow, error) {
+	rows, err := q.db.Query(ctx, exportTodo, arg.Status, arg.Search, arg.Board)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportTodoRow
+	for rows.Next() {
+		var i ExportTodoRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.DueDate,
+			&i.RecurrenceRule,
+			&i.DueAt,
+			&i.Board,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getTodoById = `-- name: GetTodoById :one
@@ -48,17 +279,33 @@ SELECT
     title,
     description,
     status,
-    due_date
+    due_date,
+    recurrence_rule,
+    recurrence_start,
+    series_id,
+    due_at,
+    user_id,
+    board,
+    updated_at,
+    completed_at
 FROM todo
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 type GetTodoByIdRow struct {
-	ID          pgtype.UUID `db:"id" json:"id"`
-	Title       string      `db:"title" json:"title"`
-	Description pgtype.Text `db:"description" json:"description"`
-	Status      TodoStatus  `db:"status" json:"status"`
-	DueDate     pgtype.Date `db:"due_date" json:"due_date"`
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	Title           string             `db:"title" json:"title"`
+	Description     pgtype.Text        `db:"description" json:"description"`
+	Status          TodoStatus         `db:"status" json:"status"`
+	DueDate         pgtype.Date        `db:"due_date" json:"due_date"`
+	RecurrenceRule  pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID        pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt           pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Board           string             `db:"board" json:"board"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	CompletedAt     pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
 }
 
 func (q *Queries) GetTodoById(ctx context.Context, id pgtype.UUID) (GetTodoByIdRow, error) {
@@ -70,19 +317,62 @@ func (q *Queries) GetTodoById(ctx context.Context, id pgtype.UUID) (GetTodoByIdR
 		&i.Description,
 		&i.Status,
 		&i.DueDate,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.Board,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getTodoForUpdate = `-- name: GetTodoForUpdate :one
+SELECT id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at FROM todo WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+`
+
+// Locks the task until the end of the transaction, so the state it returns is
+// the state the following update starts from.
+func (q *Queries) GetTodoForUpdate(ctx context.Context, id pgtype.UUID) (Todo, error) {
+	row := q.db.QueryRow(ctx, getTodoForUpdate, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const insertTodo = `-- name: InsertTodo :one
-INSERT INTO todo (id, title, description, due_date) VALUES ($1, $2, $3, $4) RETURNING id, title, description, status, due_date, created_at, updated_at
+INSERT INTO todo (id, title, description, due_date, recurrence_rule, recurrence_start, series_id, due_at, user_id, board) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
 `
 
 type InsertTodoParams struct {
-	ID          pgtype.UUID `db:"id" json:"id"`
-	Title       string      `db:"title" json:"title"`
-	Description pgtype.Text `db:"description" json:"description"`
-	DueDate     pgtype.Date `db:"due_date" json:"due_date"`
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	Title           string             `db:"title" json:"title"`
+	Description     pgtype.Text        `db:"description" json:"description"`
+	DueDate         pgtype.Date        `db:"due_date" json:"due_date"`
+	RecurrenceRule  pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID        pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt           pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Board           string             `db:"board" json:"board"`
 }
 
 func (q *Queries) InsertTodo(ctx context.Context, arg InsertTodoParams) (Todo, error) {
@@ -91,6 +381,12 @@ func (q *Queries) InsertTodo(ctx context.Context, arg InsertTodoParams) (Todo, e
 		arg.Title,
 		arg.Description,
 		arg.DueDate,
+		arg.RecurrenceRule,
+		arg.RecurrenceStart,
+		arg.SeriesID,
+		arg.DueAt,
+		arg.UserID,
+		arg.Board,
 	)
 	var i Todo
 	err := row.Scan(
@@ -101,26 +397,78 @@ func (q *Queries) InsertTodo(ctx context.Context, arg InsertTodoParams) (Todo, e
 		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const insertTodoOccurrence = `-- name: InsertTodoOccurrence :execrows
+INSERT INTO todo (id, title, description, due_date, recurrence_rule, recurrence_start, series_id, due_at, user_id, board) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (series_id, due_date) DO NOTHING
+`
+
+type InsertTodoOccurrenceParams struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	Title           string             `db:"title" json:"title"`
+	Description     pgtype.Text        `db:"description" json:"description"`
+	DueDate         pgtype.Date        `db:"due_date" json:"due_date"`
+	RecurrenceRule  pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID        pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt           pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Board           string             `db:"board" json:"board"`
+}
+
+func (q *Queries) InsertTodoOccurrence(ctx context.Context, arg InsertTodoOccurrenceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertTodoOccurrence,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.DueDate,
+		arg.RecurrenceRule,
+		arg.RecurrenceStart,
+		arg.SeriesID,
+		arg.DueAt,
+		arg.UserID,
+		arg.Board,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const listTodo = `-- name: ListTodo :many
 WITH filtered_todo AS (
-    SELECT id, title, description, status, due_date, created_at, updated_at
+    SELECT id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
     FROM todo
     WHERE 
         ($3::text IS NULL OR status = $3::todo_status) AND
         ($4::text IS NULL OR 
             (title ILIKE '%' || $4 || '%' OR 
-             description ILIKE '%' || $4 || '%'))
+             description ILIKE '%' || $4 || '%')) AND
+        ($5::text IS NULL OR board = $5) AND
+        deleted_at IS NULL
 )
 SELECT 
     id,
     title,
     description,
     status,
-    due_date
+    due_date,
+    recurrence_rule,
+    due_at,
+    board,
+    updated_at,
+    completed_at
 FROM filtered_todo
 ORDER BY created_at DESC
 LIMIT $2::integer
@@ -128,18 +476,24 @@ OFFSET ($1::integer - 1) * $2::integer
 `
 
 type ListTodoParams struct {
-	Page     int32  `db:"page" json:"page"`
-	LimitVal int32  `db:"limit_val" json:"limit_val"`
+	Page     int32   `db:"page" json:"page"`
+	LimitVal int32   `db:"limit_val" json:"limit_val"`
 	Status   *string `db:"status" json:"status"`
 	Search   *string `db:"search" json:"search"`
+	Board    *string `db:"board" json:"board"`
 }
 
 type ListTodoRow struct {
-	ID          pgtype.UUID `db:"id" json:"id"`
-	Title       string      `db:"title" json:"title"`
-	Description pgtype.Text `db:"description" json:"description"`
-	Status      TodoStatus  `db:"status" json:"status"`
-	DueDate     pgtype.Date `db:"due_date" json:"due_date"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Title          string             `db:"title" json:"title"`
+	Description    pgtype.Text        `db:"description" json:"description"`
+	Status         TodoStatus         `db:"status" json:"status"`
+	DueDate        pgtype.Date        `db:"due_date" json:"due_date"`
+	RecurrenceRule pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	Board          string             `db:"board" json:"board"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	CompletedAt    pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
 }
 
 func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]ListTodoRow, error) {
@@ -148,6 +502,7 @@ func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]ListTodoR
 		arg.LimitVal,
 		arg.Status,
 		arg.Search,
+		arg.Board,
 	)
 	if err != nil {
 		return nil, err
@@ -162,6 +517,153 @@ func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]ListTodoR
 			&i.Description,
 			&i.Status,
 			&i.DueDate,
+			&i.RecurrenceRule,
+			&i.DueAt,
+			&i.Board,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTodoAfter = `-- name: ListTodoAfter :many
+SELECT
+    id,
+    title,
+    description,
+    status,
+    due_date,
+    recurrence_rule,
+    due_at,
+    board,
+    updated_at,
+    created_at,
+    completed_at
+FROM todo
+WHERE
+    ($1::text IS NULL OR status = $1::todo_status) AND
+    ($2::text IS NULL OR
+        (title ILIKE '%' || $2 || '%' OR
+         description ILIKE '%' || $2 || '%')) AND
+    ($3::text IS NULL OR board = $3) AND
+    deleted_at IS NULL AND
+    ($4::timestamptz IS NULL OR
+        (created_at, id) < ($4::timestamptz, $5::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $6::integer
+`
+
+type ListTodoAfterParams struct {
+	Status         *string            `db:"status" json:"status"`
+	Search         *string            `db:"search" json:"search"`
+	Board          *string            `db:"board" json:"board"`
+	AfterCreatedAt pgtype.Timestamptz `db:"after_created_at" json:"after_created_at"`
+	AfterID        pgtype.UUID        `db:"after_id" json:"after_id"`
+	LimitVal       int32              `db:"limit_val" json:"limit_val"`
+}
+
+type ListTodoAfterRow struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Title          string             `db:"title" json:"title"`
+	Description    pgtype.Text        `db:"description" json:"description"`
+	Status         TodoStatus         `db:"status" json:"status"`
+	DueDate        pgtype.Date        `db:"due_date" json:"due_date"`
+	RecurrenceRule pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	Board          string             `db:"board" json:"board"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	CompletedAt    pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+}
+
+// Keyset variant of ListTodo for cursor paging: the tasks that come after the
+// one with the given created_at and id, so tasks created meanwhile don't shift
+// the next pages.
+func (q *Queries) ListTodoAfter(ctx context.Context, arg ListTodoAfterParams) ([]ListTodoAfterRow, error) {
+	rows, err := q.db.Query(ctx, listTodoAfter,
+		arg.Status,
+		arg.Search,
+		arg.Board,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTodoAfterRow
+	for rows.Next() {
+		var i ListTodoAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.DueDate,
+			&i.RecurrenceRule,
+			&i.DueAt,
+			&i.Board,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrash = `-- name: ListTrash :many
+SELECT id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at FROM todo
+WHERE deleted_at IS NOT NULL AND user_id = $1
+ORDER BY deleted_at DESC
+LIMIT $2::integer
+OFFSET ($3::integer - 1) * $2::integer
+`
+
+type ListTrashParams struct {
+	UserID   pgtype.UUID `db:"user_id" json:"user_id"`
+	LimitVal int32       `db:"limit_val" json:"limit_val"`
+	Page     int32       `db:"page" json:"page"`
+}
+
+func (q *Queries) ListTrash(ctx context.Context, arg ListTrashParams) ([]Todo, error) {
+	rows, err := q.db.Query(ctx, listTrash, arg.UserID, arg.LimitVal, arg.Page)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RecurrenceRule,
+			&i.RecurrenceStart,
+			&i.SeriesID,
+			&i.DueAt,
+			&i.UserID,
+			&i.CompletedAt,
+			&i.Board,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -173,33 +675,305 @@ func (q *Queries) ListTodo(ctx context.Context, arg ListTodoParams) ([]ListTodoR
 	return items, nil
 }
 
+const lockTodo = `-- name: LockTodo :one
+SELECT id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at FROM todo WHERE id = $1 FOR UPDATE
+`
+
+// Unlike GetTodoForUpdate it also locks tasks in the trash.
+func (q *Queries) LockTodo(ctx context.Context, id pgtype.UUID) (Todo, error) {
+	row := q.db.QueryRow(ctx, lockTodo, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const moveTodo = `-- name: MoveTodo :one
+UPDATE todo t
+SET
+    board = $1,
+    updated_at = NOW()
+FROM (SELECT id, board FROM todo WHERE id = $2 AND deleted_at IS NULL FOR UPDATE) old
+WHERE t.id = old.id
+RETURNING t.id, t.title, t.description, t.status, t.due_date, t.created_at, t.updated_at, t.recurrence_rule, t.recurrence_start, t.series_id, t.due_at, t.user_id, t.completed_at, t.board, t.deleted_at, old.board AS previous_board
+`
+
+type MoveTodoParams struct {
+	Board string      `db:"board" json:"board"`
+	ID    pgtype.UUID `db:"id" json:"id"`
+}
+
+type MoveTodoRow struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	Title           string             `db:"title" json:"title"`
+	Description     pgtype.Text        `db:"description" json:"description"`
+	Status          TodoStatus         `db:"status" json:"status"`
+	DueDate         pgtype.Date        `db:"due_date" json:"due_date"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	RecurrenceRule  pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID        pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt           pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	CompletedAt     pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	Board           string             `db:"board" json:"board"`
+	DeletedAt       pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	PreviousBoard   string             `db:"previous_board" json:"previous_board"`
+}
+
+// previous_board lets subscribers of the old board drop the task.
+func (q *Queries) MoveTodo(ctx context.Context, arg MoveTodoParams) (MoveTodoRow, error) {
+	row := q.db.QueryRow(ctx, moveTodo, arg.Board, arg.ID)
+	var i MoveTodoRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
+		&i.PreviousBoard,
+	)
+	return i, err
+}
+
+const purgeTrashedTodos = `-- name: PurgeTrashedTodos :many
+DELETE FROM todo
+WHERE id IN (
+    SELECT id FROM todo
+    WHERE deleted_at < $1
+    ORDER BY deleted_at
+    LIMIT $2::integer
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
+`
+
+type PurgeTrashedTodosParams struct {
+	DeletedBefore pgtype.Timestamptz `db:"deleted_before" json:"deleted_before"`
+	LimitVal      int32              `db:"limit_val" json:"limit_val"`
+}
+
+func (q *Queries) PurgeTrashedTodos(ctx context.Context, arg PurgeTrashedTodosParams) ([]Todo, error) {
+	rows, err := q.db.Query(ctx, purgeTrashedTodos, arg.DeletedBefore, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Todo
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RecurrenceRule,
+			&i.RecurrenceStart,
+			&i.SeriesID,
+			&i.DueAt,
+			&i.UserID,
+			&i.CompletedAt,
+			&i.Board,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreTodo = `-- name: RestoreTodo :one
+UPDATE todo t
+SET
+    deleted_at = NULL,
+    updated_at = NOW()
+FROM (SELECT id, deleted_at FROM todo WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL FOR UPDATE) old
+WHERE t.id = old.id
+RETURNING t.id, t.title, t.description, t.status, t.due_date, t.created_at, t.updated_at, t.recurrence_rule, t.recurrence_start, t.series_id, t.due_at, t.user_id, t.completed_at, t.board, t.deleted_at, old.deleted_at AS previous_deleted_at
+`
+
+type RestoreTodoParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+type RestoreTodoRow struct {
+	ID                pgtype.UUID        `db:"id" json:"id"`
+	Title             string             `db:"title" json:"title"`
+	Description       pgtype.Text        `db:"description" json:"description"`
+	Status            TodoStatus         `db:"status" json:"status"`
+	DueDate           pgtype.Date        `db:"due_date" json:"due_date"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	RecurrenceRule    pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	RecurrenceStart   pgtype.Date        `db:"recurrence_start" json:"recurrence_start"`
+	SeriesID          pgtype.UUID        `db:"series_id" json:"series_id"`
+	DueAt             pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UserID            pgtype.UUID        `db:"user_id" json:"user_id"`
+	CompletedAt       pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	Board             string             `db:"board" json:"board"`
+	DeletedAt         pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	PreviousDeletedAt pgtype.Timestamptz `db:"previous_deleted_at" json:"previous_deleted_at"`
+}
+
+func (q *Queries) RestoreTodo(ctx context.Context, arg RestoreTodoParams) (RestoreTodoRow, error) {
+	row := q.db.QueryRow(ctx, restoreTodo, arg.ID, arg.UserID)
+	var i RestoreTodoRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
+		&i.PreviousDeletedAt,
+	)
+	return i, err
+}
+
+const setTodoFields = `-- name: SetTodoFields :one
+UPDATE todo
+SET
+    title = $1,
+    description = $2,
+    status = $3,
+    due_date = $4,
+    due_at = $5,
+    recurrence_rule = $6,
+    completed_at = $7,
+    board = $8,
+    updated_at = NOW()
+WHERE id = $9 AND deleted_at IS NULL
+RETURNING id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
+`
+
+type SetTodoFieldsParams struct {
+	Title          string             `db:"title" json:"title"`
+	Description    pgtype.Text        `db:"description" json:"description"`
+	Status         TodoStatus         `db:"status" json:"status"`
+	DueDate        pgtype.Date        `db:"due_date" json:"due_date"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	RecurrenceRule pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	CompletedAt    pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	Board          string             `db:"board" json:"board"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+}
+
+// Overwrites every editable field, completed_at included, for undo and bulk
+// updates that compute the whole new state themselves.
+func (q *Queries) SetTodoFields(ctx context.Context, arg SetTodoFieldsParams) (Todo, error) {
+	row := q.db.QueryRow(ctx, setTodoFields,
+		arg.Title,
+		arg.Description,
+		arg.Status,
+		arg.DueDate,
+		arg.DueAt,
+		arg.RecurrenceRule,
+		arg.CompletedAt,
+		arg.Board,
+		arg.ID,
+	)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const updateTodo = `-- name: UpdateTodo :one
 UPDATE todo 
 SET 
-    title = $2,
-    description = $3,
-    status = $4,
-    due_date = $5,
+    title = $1,
+    description = $2,
+    status = $3,
+    due_date = $4,
+    due_at = $5,
+    recurrence_rule = COALESCE($6, recurrence_rule),
+    completed_at = CASE
+        WHEN $3 = 'completed' AND status <> 'completed' THEN NOW()
+        WHEN $3 = 'completed' THEN completed_at
+        ELSE NULL
+    END,
     updated_at = NOW()
-WHERE id = $1 
-RETURNING id, title, description, status, due_date, created_at, updated_at
+WHERE id = $7 AND deleted_at IS NULL
+RETURNING id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
 `
 
 type UpdateTodoParams struct {
-	ID          pgtype.UUID `db:"id" json:"id"`
-	Title       string      `db:"title" json:"title"`
-	Description pgtype.Text `db:"description" json:"description"`
-	Status      TodoStatus  `db:"status" json:"status"`
-	DueDate     pgtype.Date `db:"due_date" json:"due_date"`
+	Title          string             `db:"title" json:"title"`
+	Description    pgtype.Text        `db:"description" json:"description"`
+	Status         TodoStatus         `db:"status" json:"status"`
+	DueDate        pgtype.Date        `db:"due_date" json:"due_date"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	RecurrenceRule pgtype.Text        `db:"recurrence_rule" json:"recurrence_rule"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
 }
 
 func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error) {
 	row := q.db.QueryRow(ctx, updateTodo,
-		arg.ID,
 		arg.Title,
 		arg.Description,
 		arg.Status,
 		arg.DueDate,
+		arg.DueAt,
+		arg.RecurrenceRule,
+		arg.ID,
 	)
 	var i Todo
 	err := row.Scan(
@@ -210,6 +984,53 @@ func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, e
 		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateTodoDueDate = `-- name: UpdateTodoDueDate :one
+UPDATE todo
+SET
+    due_date = $2,
+    due_at = $3,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, title, description, status, due_date, created_at, updated_at, recurrence_rule, recurrence_start, series_id, due_at, user_id, completed_at, board, deleted_at
+`
+
+type UpdateTodoDueDateParams struct {
+	ID      pgtype.UUID        `db:"id" json:"id"`
+	DueDate pgtype.Date        `db:"due_date" json:"due_date"`
+	DueAt   pgtype.Timestamptz `db:"due_at" json:"due_at"`
+}
+
+func (q *Queries) UpdateTodoDueDate(ctx context.Context, arg UpdateTodoDueDateParams) (Todo, error) {
+	row := q.db.QueryRow(ctx, updateTodoDueDate, arg.ID, arg.DueDate, arg.DueAt)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceStart,
+		&i.SeriesID,
+		&i.DueAt,
+		&i.UserID,
+		&i.CompletedAt,
+		&i.Board,
+		&i.DeletedAt,
 	)
 	return i, err
 }
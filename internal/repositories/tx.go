@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Transactor runs fn with queries that share a single transaction. The
+// transaction is committed when fn returns nil and rolled back otherwise.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(q Querier) error) error
+}
+
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type PgxTransactor struct {
+	db txBeginner
+}
+
+func NewTransactor(db txBeginner) *PgxTransactor {
+	return &PgxTransactor{
+		db: db,
+	}
+}
+
+func (t *PgxTransactor) InTx(ctx context.Context, fn func(q Querier) error) error {
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := fn(New(WithTimeouts(tx))); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
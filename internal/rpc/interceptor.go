@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"ilcs/internal/constants"
+	"ilcs/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicServices need no token, load balancers check health without one.
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// wrappedStream is a server stream with the context the interceptors built.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+// trace does for a call what middlewares.Trace does for a request: it takes
+// the trace id of the caller, or makes one, and sends it back as a header.
+func trace(ctx context.Context) context.Context {
+
+	traceId := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(constants.TRACE_ID); len(values) > 0 {
+			traceId = values[0]
+		}
+	}
+
+	if traceId == "" {
+		traceId = xid.New().String()
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(constants.TRACE_ID, traceId))
+
+	return context.WithValue(ctx, constants.TRACE_ID, traceId)
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	log.Info().Str(constants.TRACE_ID, utils.GetTraceId(ctx)).Str("type", "rpc").Str("method", method).Str("code", status.Code(err).String()).Dur("latency", time.Since(start)).Send()
+}
+
+func TraceUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+		start := time.Now()
+		ctx = trace(ctx)
+
+		res, err := handler(ctx, req)
+
+		logCall(ctx, info.FullMethod, start, err)
+
+		return res, err
+	}
+}
+
+func TraceStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		start := time.Now()
+		ctx := trace(ss.Context())
+
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+
+		logCall(ctx, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// authenticate checks the bearer token of a call like middlewares.Auth checks
+// the one of a request, and puts its subject in the context.
+func authenticate(ctx context.Context, tokens *utils.Tokens, method string) (context.Context, error) {
+
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok || token == "" || strings.Contains(token, " ") {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+	}
+
+	sub, err := tokens.Subject(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	if sub != "" {
+		ctx = context.WithValue(ctx, constants.USER_ID, sub)
+	}
+
+	return ctx, nil
+}
+
+func AuthUnary(tokens *utils.Tokens) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+		ctx, err := authenticate(ctx, tokens, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func AuthStream(tokens *utils.Tokens) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		ctx, err := authenticate(ss.Context(), tokens, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
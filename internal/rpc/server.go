@@ -0,0 +1,333 @@
+// Package rpc serves the tasks over gRPC, next to the REST API. The contract
+// is proto/task/v1/task.proto, the code in taskv1 is generated from it with
+// "task proto".
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ilcs/internal/app/stream"
+	"ilcs/internal/app/todo"
+	"ilcs/internal/events"
+	"ilcs/internal/rpc/taskv1"
+	"ilcs/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewServer returns a gRPC server with the task service, health checks and
+// reflection. Every call goes through the trace and auth interceptors.
+func NewServer(todos todo.ITodoService, streams stream.IStreamService, tokens *utils.Tokens) *grpc.Server {
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(TraceUnary(), AuthUnary(tokens)),
+		grpc.ChainStreamInterceptor(TraceStream(), AuthStream(tokens)),
+	)
+
+	taskv1.RegisterTaskServiceServer(server, NewTaskServer(todos, streams))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(taskv1.TaskService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server
+}
+
+// TaskServer implements TaskService on top of the task service of the REST
+// API, requests are validated with the same rules.
+type TaskServer struct {
+	taskv1.UnimplementedTaskServiceServer
+	todos   todo.ITodoService
+	streams stream.IStreamService
+}
+
+func NewTaskServer(todos todo.ITodoService, streams stream.IStreamService) *TaskServer {
+	return &TaskServer{
+		todos:   todos,
+		streams: streams,
+	}
+}
+
+// toStatus gives the gRPC status the REST API answers an error with a 4xx
+// for.
+func toStatus(err error) error {
+
+	var errs validator.ValidationErrors
+
+	switch {
+	case errors.As(err, &errs):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, pgx.ErrNoRows):
+		return status.Error(codes.NotFound, "task not found")
+	case errors.Is(err, todo.ErrInvalidRecurrenceRule), errors.Is(err, todo.ErrInvalidBoard):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, stream.ErrNoUser):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func validateId(id string) error {
+
+	if err := utils.ValidateId(id); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return nil
+}
+
+func toTask(data todo.Todo) *taskv1.Task {
+
+	task := &taskv1.Task{
+		Id:             data.ID,
+		Title:          data.Title,
+		Description:    data.Description,
+		Status:         toProtoStatus(data.Status),
+		DueDate:        data.DueDate,
+		RecurrenceRule: data.RecurrenceRule,
+		Board:          data.Board,
+		Overdue:        data.Overdue,
+		Tags:           data.Tags,
+	}
+
+	if dueAt, err := time.Parse(time.RFC3339, data.DueAt); err == nil {
+		task.DueAt = timestamppb.New(dueAt)
+	}
+
+	if updatedAt, err := time.Parse(time.RFC3339, data.UpdatedAt); err == nil {
+		task.UpdatedAt = timestamppb.New(updatedAt)
+	}
+
+	return task
+}
+
+func toProtoStatus(value string) taskv1.Status {
+
+	switch value {
+	case "pending":
+		return taskv1.Status_STATUS_PENDING
+	case "completed":
+		return taskv1.Status_STATUS_COMPLETED
+	}
+
+	return taskv1.Status_STATUS_UNSPECIFIED
+}
+
+func fromProtoStatus(value taskv1.Status) string {
+
+	switch value {
+	case taskv1.Status_STATUS_PENDING:
+		return "pending"
+	case taskv1.Status_STATUS_COMPLETED:
+		return "completed"
+	}
+
+	return ""
+}
+
+// formatDueAt writes a due time the way the REST API takes it.
+func formatDueAt(dueAt *timestamppb.Timestamp) string {
+
+	if dueAt == nil {
+		return ""
+	}
+
+	return dueAt.AsTime().Format(time.RFC3339)
+}
+
+// task reads a task back after a mutation, so every call answers with the
+// same shape as GetTask.
+func (s *TaskServer) task(ctx context.Context, id string) (*taskv1.Task, error) {
+
+	data, err := s.todos.GetTodo(ctx, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTask(data), nil
+}
+
+func (s *TaskServer) CreateTask(ctx context.Context, req *taskv1.CreateTaskRequest) (*taskv1.CreateTaskResponse, error) {
+
+	create := todo.CreateTodoRequest{
+		Title:          req.GetTitle(),
+		Description:    req.GetDescription(),
+		DueDate:        req.GetDueDate(),
+		DueAt:          formatDueAt(req.GetDueAt()),
+		RecurrenceRule: req.GetRecurrenceRule(),
+		Board:          req.GetBoard(),
+	}
+
+	if err := binding.Validator.ValidateStruct(create); err != nil {
+		return nil, toStatus(err)
+	}
+
+	data, undo, err := s.todos.CreateTodo(ctx, create)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	task, err := s.task(ctx, data.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &taskv1.CreateTaskResponse{Task: task, UndoToken: undo}, nil
+}
+
+func (s *TaskServer) GetTask(ctx context.Context, req *taskv1.GetTaskRequest) (*taskv1.Task, error) {
+
+	if err := validateId(req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return s.task(ctx, req.GetId())
+}
+
+func (s *TaskServer) ListTasks(ctx context.Context, req *taskv1.ListTasksRequest) (*taskv1.ListTasksResponse, error) {
+
+	var params todo.ListTodoRequestParams
+
+	if req.GetPage() > 0 {
+		page := int(req.GetPage())
+		params.Page = &page
+	}
+
+	if req.GetLimit() > 0 {
+		limit := int(req.GetLimit())
+		params.Limit = &limit
+	}
+
+	if req.GetStatus() != taskv1.Status_STATUS_UNSPECIFIED {
+		value := fromProtoStatus(req.GetStatus())
+		params.Status = &value
+	}
+
+	params.Search = req.Search
+	params.Board = req.Board
+
+	todos, count, page, limit, err := s.todos.GetListTodos(ctx, params)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &taskv1.ListTasksResponse{
+		Tasks: make([]*taskv1.Task, 0, len(todos)),
+		Total: count,
+		Page:  int32(page),
+		Limit: int32(limit),
+	}
+
+	for _, data := range todos {
+		res.Tasks = append(res.Tasks, toTask(data))
+	}
+
+	return res, nil
+}
+
+func (s *TaskServer) UpdateTask(ctx context.Context, req *taskv1.UpdateTaskRequest) (*taskv1.UpdateTaskResponse, error) {
+
+	if err := validateId(req.GetId()); err != nil {
+		return nil, err
+	}
+
+	update := todo.UpdateTodoRequest{
+		Title:          req.GetTitle(),
+		Description:    req.GetDescription(),
+		Status:         fromProtoStatus(req.GetStatus()),
+		DueDate:        req.GetDueDate(),
+		DueAt:          formatDueAt(req.GetDueAt()),
+		RecurrenceRule: req.RecurrenceRule,
+	}
+
+	if err := binding.Validator.ValidateStruct(update); err != nil {
+		return nil, toStatus(err)
+	}
+
+	_, undo, err := s.todos.UpdateTodo(ctx, update, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	task, err := s.task(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &taskv1.UpdateTaskResponse{Task: task, UndoToken: undo}, nil
+}
+
+func (s *TaskServer) DeleteTask(ctx context.Context, req *taskv1.DeleteTaskRequest) (*taskv1.DeleteTaskResponse, error) {
+
+	if err := validateId(req.GetId()); err != nil {
+		return nil, err
+	}
+
+	undo, err := s.todos.DeleteTodo(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &taskv1.DeleteTaskResponse{UndoToken: undo}, nil
+}
+
+// WatchTasks sends the events GET /tasks/stream sends, from the same feed.
+func (s *TaskServer) WatchTasks(req *taskv1.WatchTasksRequest, srv taskv1.TaskService_WatchTasksServer) error {
+
+	ctx := srv.Context()
+
+	entries, err := s.streams.Subscribe(ctx, utils.GetUserId(ctx), req.GetLastEventId())
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry, ok := <-entries:
+			if !ok {
+				return nil
+			}
+
+			if err := srv.Send(toEvent(entry)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toEvent turns a feed entry into a TaskEvent. Its id is the id of the feed
+// entry, which is what last_event_id resumes after.
+func toEvent(entry events.FeedEntry) *taskv1.TaskEvent {
+
+	event := &taskv1.TaskEvent{
+		Id:   entry.ID,
+		Type: entry.Type,
+		Data: entry.Data,
+	}
+
+	var data events.Event
+	if err := json.Unmarshal(entry.Data, &data); err == nil {
+		event.TaskId = data.TaskID
+		event.OccurredAt = timestamppb.New(data.OccurredAt)
+	}
+
+	return event
+}
@@ -0,0 +1,210 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"ilcs/internal/app/todo"
+	"ilcs/internal/constants"
+	"ilcs/internal/events"
+	"ilcs/internal/repositories"
+	"ilcs/internal/rpc"
+	"ilcs/internal/rpc/taskv1"
+	"ilcs/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FakeTodoService keeps tasks in memory and remembers the user of each call.
+type FakeTodoService struct {
+	todo.ITodoService
+	tasks map[string]todo.Todo
+	users []string
+}
+
+func (f *FakeTodoService) CreateTodo(ctx context.Context, req todo.CreateTodoRequest) (repositories.Todo, string, error) {
+
+	f.users = append(f.users, utils.GetUserId(ctx))
+
+	id := uuid.New()
+	f.tasks[id.String()] = todo.Todo{
+		ID:      id.String(),
+		Title:   req.Title,
+		Status:  "pending",
+		DueDate: req.DueDate,
+		DueAt:   req.DueAt,
+		Board:   todo.DefaultBoard,
+	}
+
+	return repositories.Todo{ID: pgtype.UUID{Bytes: id, Valid: true}}, "undo-create", nil
+}
+
+func (f *FakeTodoService) GetTodo(ctx context.Context, id string) (todo.Todo, error) {
+
+	task, ok := f.tasks[id]
+	if !ok {
+		return todo.Todo{}, pgx.ErrNoRows
+	}
+
+	return task, nil
+}
+
+func (f *FakeTodoService) UpdateTodo(ctx context.Context, req todo.UpdateTodoRequest, id string) (repositories.Todo, string, error) {
+
+	task, ok := f.tasks[id]
+	if !ok {
+		return repositories.Todo{}, "", pgx.ErrNoRows
+	}
+
+	task.Title = req.Title
+	task.Status = req.Status
+	task.DueDate = req.DueDate
+	f.tasks[id] = task
+
+	return repositories.Todo{}, "undo-update", nil
+}
+
+// FakeStreamService replays the entries it was given and ends the stream.
+type FakeStreamService struct {
+	entries []events.FeedEntry
+	after   string
+}
+
+func (f *FakeStreamService) Subscribe(ctx context.Context, userId, lastEventId string) (<-chan events.FeedEntry, error) {
+
+	f.after = lastEventId
+
+	out := make(chan events.FeedEntry, len(f.entries))
+	for _, entry := range f.entries {
+		out <- entry
+	}
+	close(out)
+
+	return out, nil
+}
+
+func setup(t *testing.T, todos *FakeTodoService, streams *FakeStreamService) *grpc.ClientConn {
+
+	listener := bufconn.Listen(1 << 20)
+
+	server := rpc.NewServer(todos, streams, utils.NewTokens("secret"))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func withToken(t *testing.T, userId string) context.Context {
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userId,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuth_RejectsMissingAndInvalidTokens(t *testing.T) {
+	client := taskv1.NewTaskServiceClient(setup(t, &FakeTodoService{}, &FakeStreamService{}))
+
+	_, err := client.GetTask(context.Background(), &taskv1.GetTaskRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer nope")
+	_, err = client.GetTask(ctx, &taskv1.GetTaskRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestHealth_NeedsNoToken(t *testing.T) {
+	client := healthpb.NewHealthClient(setup(t, &FakeTodoService{}, &FakeStreamService{}))
+
+	res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "ilcs.task.v1.TaskService"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+}
+
+func TestCreateAndUpdateTask(t *testing.T) {
+	todos := &FakeTodoService{tasks: map[string]todo.Todo{}}
+	client := taskv1.NewTaskServiceClient(setup(t, todos, &FakeStreamService{}))
+
+	userId := uuid.NewString()
+	ctx := metadata.AppendToOutgoingContext(withToken(t, userId), constants.TRACE_ID, "trace-1")
+
+	var header metadata.MD
+	created, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{
+		Title: "Ship it",
+		DueAt: timestamppb.New(time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)),
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"trace-1"}, header.Get(constants.TRACE_ID))
+	assert.Equal(t, []string{userId}, todos.users)
+	assert.Equal(t, "undo-create", created.GetUndoToken())
+	assert.Equal(t, "Ship it", created.GetTask().GetTitle())
+	assert.Equal(t, taskv1.Status_STATUS_PENDING, created.GetTask().GetStatus())
+	assert.Equal(t, "2025-03-01T09:00:00Z", created.GetTask().GetDueAt().AsTime().Format(time.RFC3339))
+
+	updated, err := client.UpdateTask(ctx, &taskv1.UpdateTaskRequest{
+		Id:      created.GetTask().GetId(),
+		Title:   "Shipped",
+		Status:  taskv1.Status_STATUS_COMPLETED,
+		DueDate: "2025-03-01",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, taskv1.Status_STATUS_COMPLETED, updated.GetTask().GetStatus())
+
+	_, err = client.CreateTask(ctx, &taskv1.CreateTaskRequest{Title: "No due day"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.UpdateTask(ctx, &taskv1.UpdateTaskRequest{Id: created.GetTask().GetId(), Title: "No status", DueDate: "2025-03-01"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetTask(ctx, &taskv1.GetTaskRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestWatchTasks(t *testing.T) {
+	taskId := uuid.NewString()
+	data, err := json.Marshal(events.New(events.TaskCompleted, "", taskId, nil))
+	require.NoError(t, err)
+
+	streams := &FakeStreamService{entries: []events.FeedEntry{{ID: "1-0", Type: events.TaskCompleted, Data: data}}}
+	client := taskv1.NewTaskServiceClient(setup(t, &FakeTodoService{}, streams))
+
+	watch, err := client.WatchTasks(withToken(t, uuid.NewString()), &taskv1.WatchTasksRequest{LastEventId: "0-1"})
+	require.NoError(t, err)
+
+	event, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "1-0", event.GetId())
+	assert.Equal(t, events.TaskCompleted, event.GetType())
+	assert.Equal(t, taskId, event.GetTaskId())
+	assert.JSONEq(t, string(data), string(event.GetData()))
+	assert.Equal(t, "0-1", streams.after)
+}
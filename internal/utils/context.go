@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"context"
+	"ilcs/internal/constants"
+)
+
+// GetUserId returns the subject of the token that authenticated the request,
+// or an empty string when the token carries no subject.
+func GetUserId(ctx context.Context) string {
+
+	userId, _ := ctx.Value(constants.USER_ID).(string)
+
+	return userId
+}
+
+// GetTraceId returns the trace id middlewares.Trace gave the request.
+func GetTraceId(ctx context.Context) string {
+
+	traceId, _ := ctx.Value(constants.TRACE_ID).(string)
+
+	return traceId
+}
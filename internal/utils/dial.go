@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrPrivateAddress = errors.New("address is not publicly routable")
+
+// nonPublic are the ranges the netip predicates leave out: shared address
+// space, IETF protocol assignments, benchmarking, reserved and NAT64.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicAddr reports whether ip can be reached over the internet, as
+// opposed to loopback, private, link-local (cloud metadata) and other
+// special purpose addresses.
+func IsPublicAddr(ip netip.Addr) bool {
+
+	ip = ip.Unmap()
+
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() ||
+		ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, prefix := range nonPublic {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckPublicHost resolves host and fails unless all its addresses are
+// public. It only catches mistakes early, the host may resolve elsewhere by
+// the time it is called, so connections go through PublicHTTPClient too.
+func CheckPublicHost(ctx context.Context, host string) error {
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return fmt.Errorf("%s: %w", host, ErrPrivateAddress)
+		}
+	}
+
+	return nil
+}
+
+// publicOnly runs once the dialer has resolved the host, so the address it
+// checks is the one connected to.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%s: %w", address, ErrPrivateAddress)
+	}
+
+	return nil
+}
+
+// PublicHTTPClient returns a client for URLs given by users, such as webhook
+// targets. It refuses to connect to non-public addresses, redirects
+// included, and ignores proxy settings so the check sees the real target.
+func PublicHTTPClient(timeout time.Duration) *http.Client {
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: publicOnly,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
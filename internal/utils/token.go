@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ticketAudience marks the tokens signed by SignTicket.
+const ticketAudience = "ticket"
+
+var ErrTicketAsToken = errors.New("tickets are not accepted as tokens")
+
+// Tokens signs and verifies the API tokens with the JWT secret.
+type Tokens struct {
+	secret []byte
+}
+
+func NewTokens(secret string) *Tokens {
+	return &Tokens{
+		secret: []byte(secret),
+	}
+}
+
+// Sign issues a token of userId that is valid for ttl.
+func (t *Tokens) Sign(userId string, ttl time.Duration) (string, error) {
+
+	claimsJwt := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userId,
+		"exp": time.Now().Add(ttl).Unix(),
+	})
+
+	return claimsJwt.SignedString(t.secret)
+}
+
+// SignTicket issues a ticket of userId that is valid for ttl. Tickets stand
+// in for tokens where browsers can't send an Authorization header, they end
+// up in URLs so they are short-lived and only accepted by TicketSubject.
+func (t *Tokens) SignTicket(userId string, ttl time.Duration) (string, error) {
+
+	claimsJwt := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userId,
+		"aud": ticketAudience,
+		"exp": time.Now().Add(ttl).Unix(),
+	})
+
+	return claimsJwt.SignedString(t.secret)
+}
+
+// Subject verifies a token issued by GET /token and returns its subject,
+// which is empty for tokens without a user.
+func (t *Tokens) Subject(token string) (sub string, err error) {
+
+	claims, err := t.parse(token)
+	if err != nil {
+		return
+	}
+
+	if aud, _ := claims.GetAudience(); slices.Contains(aud, ticketAudience) {
+		err = ErrTicketAsToken
+		return
+	}
+
+	sub, _ = claims.GetSubject()
+
+	return
+}
+
+// TicketSubject verifies a ticket issued by SignTicket and returns its
+// subject.
+func (t *Tokens) TicketSubject(ticket string) (sub string, err error) {
+
+	claims, err := t.parse(ticket, jwt.WithAudience(ticketAudience))
+	if err != nil {
+		return
+	}
+
+	sub, _ = claims.GetSubject()
+
+	return
+}
+
+func (t *Tokens) parse(token string, options ...jwt.ParserOption) (claims jwt.MapClaims, err error) {
+
+	claims = jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return t.secret, nil
+	}, options...)
+
+	return
+}
@@ -0,0 +1,71 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"ilcs/internal/xlsx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumn(t *testing.T) {
+	assert.Equal(t, "A", xlsx.Column(0))
+	assert.Equal(t, "Z", xlsx.Column(25))
+	assert.Equal(t, "AA", xlsx.Column(26))
+	assert.Equal(t, "AZ", xlsx.Column(51))
+	assert.Equal(t, "BA", xlsx.Column(52))
+	assert.Equal(t, "ZZ", xlsx.Column(701))
+	assert.Equal(t, "AAA", xlsx.Column(702))
+}
+
+func TestWriter_ProducesWellFormedWorkbook(t *testing.T) {
+	var out bytes.Buffer
+
+	w, err := xlsx.NewWriter(&out, "Tasks & more")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow([]string{"title", "", "notes"}))
+	require.NoError(t, w.WriteRow([]string{"a < b", "x\x00y", "  padded  "}))
+	require.NoError(t, w.Close())
+
+	assert.ErrorIs(t, w.WriteRow([]string{"late"}), xlsx.ErrClosed)
+
+	archive, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+
+	names := map[string]bool{}
+	for _, file := range archive.File {
+		names[file.Name] = true
+
+		part, err := file.Open()
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		// every part must parse for spreadsheet applications to open the file
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			_, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, file.Name)
+		}
+
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			assert.Contains(t, string(data), `<c r="C1" t="inlineStr">`)
+			assert.NotContains(t, string(data), `r="B1"`)
+			assert.Contains(t, string(data), `a &lt; b`)
+			assert.Contains(t, string(data), `>  padded  <`)
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		assert.True(t, names[name], name)
+	}
+}
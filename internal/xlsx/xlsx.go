@@ -0,0 +1,152 @@
+// Package xlsx writes single sheet spreadsheets row by row. Rows are written to
+// the underlying writer as they come, so a sheet never has to fit in memory.
+// Every cell is an inline string, which every spreadsheet application reads.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrClosed = errors.New("xlsx: write to a closed sheet")
+
+// parts are the files of a workbook besides its sheet.
+var parts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+const workbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+type Writer struct {
+	zip    *zip.Writer
+	sheet  io.Writer
+	row    int
+	closed bool
+}
+
+// NewWriter starts a workbook with a single sheet of the given name.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+
+	archive := zip.NewWriter(w)
+
+	for _, part := range parts {
+		if err := writePart(archive, part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writePart(archive, "xl/workbook.xml", fmtWorkbook(sheetName)); err != nil {
+		return nil, err
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{zip: archive, sheet: sheet}, nil
+}
+
+// WriteRow appends a row of text cells to the sheet.
+func (w *Writer) WriteRow(cells []string) error {
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	w.row++
+	ref := strconv.Itoa(w.row)
+
+	if _, err := io.WriteString(w.sheet, `<row r="`+ref+`">`); err != nil {
+		return err
+	}
+
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+
+		if _, err := io.WriteString(w.sheet, `<c r="`+Column(i)+ref+`" t="inlineStr"><is><t xml:space="preserve">`); err != nil {
+			return err
+		}
+
+		// invalid characters become U+FFFD instead of breaking the sheet
+		if err := xml.EscapeText(w.sheet, []byte(cell)); err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w.sheet, `</t></is></c>`); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w.sheet, `</row>`)
+
+	return err
+}
+
+// Close ends the sheet and writes the directory of the archive. A workbook that
+// is not closed is not readable, which is what a failed export should be.
+func (w *Writer) Close() error {
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	if _, err := io.WriteString(w.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}
+
+// Column is the letter reference of the column at index i, A for 0 and AA
+// for 26.
+func Column(i int) string {
+
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+
+	return name
+}
+
+func writePart(archive *zip.Writer, name, content string) error {
+
+	part, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(part, content)
+
+	return err
+}
+
+func fmtWorkbook(sheetName string) string {
+
+	var name strings.Builder
+	_ = xml.EscapeText(&name, []byte(sheetName))
+
+	return fmt.Sprintf(workbook, name.String())
+}